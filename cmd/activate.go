@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/kazuph/mcp-android-chrome/internal/driver"
+)
+
+var activateCmd = &cobra.Command{
+	Use:   "activate [tab-id]",
+	Short: "Bring an Android Chrome tab to the front",
+	Long: `Bring an open tab to the front of Chrome on the Android device.
+
+The tab ID is the "id" field reported by the android command or by the
+copy_tabs_android and search_tabs MCP tools.
+
+Examples:
+  mcp-android-chrome activate C4590D171DDF33989C7B1ED6DFE754FC
+  mcp-android-chrome activate --serial R58M123ABC 11952`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		profile, err := resolveProfile(cmd, "android")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		androidDriver := driver.NewAndroidDriver(profile.AndroidConfig(debug))
+
+		ctx, cancel := context.WithTimeout(context.Background(), profile.TimeoutDuration()+10*time.Second)
+		defer cancel()
+
+		if err := androidDriver.Start(ctx); err != nil {
+			fmt.Printf("Error: Failed to start Android driver: %v\n", err)
+			return
+		}
+		defer androidDriver.Stop(ctx)
+
+		if err := androidDriver.ActivateTab(ctx, args[0]); err != nil {
+			fmt.Printf("Error: Failed to activate tab: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Activated tab %s on Android device\n", args[0])
+	},
+}
+
+func init() {
+	activateCmd.Flags().IntP("port", "p", 9222, "Port for ADB forwarding")
+	activateCmd.Flags().String("serial", "", "ADB device serial or profile name (default: the default profile or the single USB-attached device)")
+	activateCmd.Flags().String("adb-backend", "", "ADB backend: auto, exec or native (default: $ADB_BACKEND or auto)")
+	activateCmd.Flags().IntP("timeout", "t", 10, "Network timeout in seconds")
+	activateCmd.Flags().Bool("debug", false, "Enable debug output")
+}
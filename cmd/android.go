@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/kazuph/mcp-android-chrome/internal/driver"
+	"github.com/kazuph/mcp-android-chrome/internal/query"
 )
 
 var androidCmd = &cobra.Command{
@@ -25,29 +26,37 @@ This command will:
 1. Setup ADB port forwarding
 2. Connect to Chrome DevTools Protocol on device
 3. Retrieve all open tabs
-4. Output tab information as JSON`,
+4. Output tab information as JSON
+5. Save a snapshot of the tabs to the snapshot history
+   (see 'mcp-android-chrome snapshots')
+
+When several devices are attached, pick one with --serial
+(see 'mcp-android-chrome devices' for the available serials) or with the
+name of a device profile of the config file.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		port, _ := cmd.Flags().GetInt("port")
-		socket, _ := cmd.Flags().GetString("socket")
-		timeout, _ := cmd.Flags().GetInt("timeout")
-		wait, _ := cmd.Flags().GetInt("wait")
 		skipCleanup, _ := cmd.Flags().GetBool("skip-cleanup")
 		debug, _ := cmd.Flags().GetBool("debug")
+		noSnapshot, _ := cmd.Flags().GetBool("no-snapshot")
+		queryStr, _ := cmd.Flags().GetString("query")
 
-		config := driver.AndroidConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    port,
-				Timeout: time.Duration(timeout) * time.Second,
-				Debug:   debug,
-			},
-			Socket:      socket,
-			Wait:        time.Duration(wait) * time.Second,
-			SkipCleanup: skipCleanup,
+		q, err := query.Parse(queryStr)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
 
+		profile, err := resolveProfile(cmd, "android")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		config := profile.AndroidConfig(debug)
+		config.SkipCleanup = skipCleanup
+
 		androidDriver := driver.NewAndroidDriver(config)
 		
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout+10)*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), profile.TimeoutDuration()+10*time.Second)
 		defer cancel()
 
 		fmt.Println("Starting Android Chrome tab copy...")
@@ -66,24 +75,37 @@ This command will:
 			return
 		}
 
-		// Output results
-		fmt.Printf("Successfully copied %d tabs from Android device:\n\n", len(tabs))
+		// Output results, only the tabs matching the query
+		matching := q.Filter(tabs)
+		if q.Empty() {
+			fmt.Printf("Successfully copied %d tabs from Android device:\n\n", len(tabs))
+		} else {
+			fmt.Printf("Successfully copied %d tabs from Android device, %d matching %s:\n\n", len(tabs), len(matching), q)
+		}
 		
-		tabsJSON, err := json.MarshalIndent(tabs, "", "  ")
+		tabsJSON, err := json.MarshalIndent(matching, "", "  ")
 		if err != nil {
 			fmt.Printf("Error: Failed to format tabs: %v\n", err)
 			return
 		}
 		
 		fmt.Println(string(tabsJSON))
+
+		if !noSnapshot {
+			saveSnapshot("android", tabs)
+		}
 	},
 }
 
 func init() {
 	androidCmd.Flags().IntP("port", "p", 9222, "Port for ADB forwarding")
+	androidCmd.Flags().String("serial", "", "ADB device serial or profile name (default: the default profile or the single USB-attached device)")
+	androidCmd.Flags().String("adb-backend", "", "ADB backend: auto, exec (adb binary) or native (adb server protocol) (default: $ADB_BACKEND or auto)")
 	androidCmd.Flags().StringP("socket", "s", "chrome_devtools_remote", "ADB socket name")
 	androidCmd.Flags().IntP("timeout", "t", 10, "Network timeout in seconds")
 	androidCmd.Flags().IntP("wait", "w", 2, "Wait time before starting in seconds")
 	androidCmd.Flags().Bool("skip-cleanup", false, "Skip ADB cleanup after operation")
 	androidCmd.Flags().Bool("debug", false, "Enable debug output")
+	androidCmd.Flags().Bool("no-snapshot", false, "Do not save a snapshot of the tabs to the snapshot history")
+	androidCmd.Flags().StringP("query", "q", "", "Only output tabs matching this search query (e.g. 'host:github.com -title:notifications'); the snapshot keeps all tabs")
 }
\ No newline at end of file
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/kazuph/mcp-android-chrome/internal/adb"
 	platformpkg "github.com/kazuph/mcp-android-chrome/internal/platform"
 )
 
@@ -13,7 +16,8 @@ var checkCmd = &cobra.Command{
 	Long: `Check that required system dependencies are installed and available.
 
 This command verifies:
-- ADB (Android Debug Bridge) for Android support
+- ADB (Android Debug Bridge) for Android support: the adb binary, or the adb
+  server when --adb-backend native is used
 - iOS WebKit Debug Proxy for iOS support
 
 You can check a specific platform or all platforms:
@@ -34,11 +38,11 @@ You can check a specific platform or all platforms:
 
 		if platform == "all" || platform == "android" {
 			fmt.Print("Android (ADB): ")
-			if err := platformpkg.CheckADBAvailable(); err != nil {
+			if name, devices, err := checkADB(cmd); err != nil {
 				fmt.Printf("❌ %v\n", err)
 				hasErrors = true
 			} else {
-				fmt.Println("✅ Available and working")
+				fmt.Printf("✅ Available and working (%s backend, %d device(s) listed)\n", name, len(devices))
 			}
 		}
 
@@ -78,4 +82,30 @@ You can check a specific platform or all platforms:
 			fmt.Println("✅ All required dependencies are available!")
 		}
 	},
-}
\ No newline at end of file
+}
+
+// checkADB verifies the ADB backend of the default Android profile (or
+// --adb-backend) by listing devices through it, as the devices command does
+func checkADB(cmd *cobra.Command) (string, []platformpkg.ADBDevice, error) {
+	profile, err := resolveProfile(cmd, "android")
+	if err != nil {
+		return "", nil, err
+	}
+	backend, err := adb.NewBackend(profile.ADBBackend, 10*time.Second, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := backend.CheckAvailable(ctx); err != nil {
+		return backend.Name(), nil, err
+	}
+	devices, err := backend.Devices(ctx)
+	return backend.Name(), devices, err
+}
+
+func init() {
+	checkCmd.Flags().String("adb-backend", "", "ADB backend to check: auto, exec or native (default: $ADB_BACKEND or auto)")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/kazuph/mcp-android-chrome/internal/config"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+)
+
+// appConfig holds the settings of the config file and the environment,
+// loaded before every command runs
+var appConfig = config.Default()
+
+// loadConfig loads the config file named by --config (or the default one)
+// and selects the profile named by --profile
+func loadConfig(cmd *cobra.Command, args []string) error {
+	// Configuration errors are not usage errors
+	cmd.SilenceUsage = true
+
+	path, _ := cmd.Flags().GetString("config")
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("profile") {
+		cfg.Defaults.Profile, _ = cmd.Flags().GetString("profile")
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	appConfig = cfg
+	return nil
+}
+
+// resolveProfile returns the connection settings of a command for the
+// platform. Flags set on the command line win over the profile, which is
+// named by --serial (a profile name or an ADB serial) or is the default
+// profile, and the profile wins over the defaults.
+func resolveProfile(cmd *cobra.Command, platform string) (config.Profile, error) {
+	var device string
+	if cmd.Flags().Changed("serial") {
+		device, _ = cmd.Flags().GetString("serial")
+	}
+
+	profile, err := appConfig.Resolve(device, platform)
+	if err != nil {
+		return profile, err
+	}
+
+	if cmd.Flags().Changed("port") {
+		profile.Port, _ = cmd.Flags().GetInt("port")
+	}
+	if cmd.Flags().Changed("socket") {
+		profile.Socket, _ = cmd.Flags().GetString("socket")
+	}
+	if cmd.Flags().Changed("timeout") {
+		profile.Timeout, _ = cmd.Flags().GetInt("timeout")
+	}
+	if cmd.Flags().Changed("wait") {
+		profile.Wait, _ = cmd.Flags().GetInt("wait")
+	}
+	if cmd.Flags().Changed("adb-backend") {
+		profile.ADBBackend, _ = cmd.Flags().GetString("adb-backend")
+	}
+	return profile, nil
+}
+
+// resolveFormat returns the output format of a command: the --format flag
+// when set, otherwise the configured default format
+func resolveFormat(cmd *cobra.Command) (format.Format, error) {
+	if cmd.Flags().Changed("format") {
+		formatStr, _ := cmd.Flags().GetString("format")
+		return format.ParseFormat(formatStr)
+	}
+	return appConfig.OutputFormat("")
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuph/mcp-android-chrome/internal/config"
+	"github.com/kazuph/mcp-android-chrome/internal/dedupe"
+	"github.com/kazuph/mcp-android-chrome/internal/driver"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+	"github.com/kazuph/mcp-android-chrome/internal/trash"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [source]",
+	Short: "Find duplicate tabs and close the extra copies",
+	Long: `Group tabs that show the same page and suggest one tab of every group to keep.
+
+Tabs are grouped by normalized URL: the scheme, www., m. and amp. hosts, AMP
+variants, in-page anchors, tracking parameters (utm_*, fbclid, gclid and
+the like) and a trailing slash are ignored; fragments that look like app
+routes (#inbox/abc, #!/page) are kept. With --by-title, tabs of one site
+whose titles share most of their words are grouped too. The suggested tab to
+keep is the one with the tidiest URL.
+
+The source is "live" (default) for the tabs open on the device, a tabs file
+or a snapshot ID from 'mcp-android-chrome snapshots list' (or "latest").
+
+--close lists the tabs that would be closed. --close --yes closes them on the
+live device; they are recorded in the trash first, so that the MCP tool
+undo_close can reopen them.
+
+Examples:
+  mcp-android-chrome dedupe
+  mcp-android-chrome dedupe latest --by-title --format yaml
+  mcp-android-chrome dedupe --serial R58M123ABC --close
+  mcp-android-chrome dedupe --serial R58M123ABC --close --yes
+
+Exit codes with --close --yes:
+  0  all duplicates were closed
+  1  nothing was closed (invalid arguments or device not reachable)
+  2  some tabs failed to close (status "failed" in the result)
+  3  interrupted or timed out (remaining tabs have status "skipped")`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		platform, _ := cmd.Flags().GetString("platform")
+		byTitle, _ := cmd.Flags().GetBool("by-title")
+		similarity, _ := cmd.Flags().GetFloat64("title-similarity")
+		closeTabs, _ := cmd.Flags().GetBool("close")
+		yes, _ := cmd.Flags().GetBool("yes")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		outputFormat, err := resolveFormat(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+
+		source := "live"
+		if len(args) == 1 {
+			source = args[0]
+		}
+		if yes && (!closeTabs || source != "live") {
+			fmt.Println("Error: --yes closes tabs on the live device and needs --close without a source")
+			os.Exit(exitError)
+		}
+
+		profile, err := resolveProfile(cmd, platform)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+
+		var tabs []loader.Tab
+		if source == "live" {
+			ctx, cancel := context.WithTimeout(context.Background(), profile.TimeoutDuration()+10*time.Second)
+			tabs, err = loadLiveTabs(ctx, profile, debug)
+			cancel()
+		} else {
+			tabs, err = snapshot.NewStore(appConfig.SnapshotOptions()).LoadTabs(source)
+		}
+		if err != nil {
+			fmt.Printf("Error: Failed to load '%s' tabs: %v\n", source, err)
+			os.Exit(exitError)
+		}
+
+		report := dedupe.Find(tabs, dedupe.Options{ByTitle: byTitle, TitleSimilarity: similarity})
+		fmt.Fprintf(os.Stderr, "Found %d duplicate tabs in %d groups among %d tabs\n", report.Duplicates, len(report.Groups), report.Tabs)
+
+		if !closeTabs || !yes {
+			output, err := format.NewTabFormatter(outputFormat).FormatData(report)
+			if err != nil {
+				fmt.Printf("Error: Failed to format duplicate tabs: %v\n", err)
+				os.Exit(exitError)
+			}
+			fmt.Println(output)
+			if closeTabs && report.Duplicates > 0 {
+				fmt.Fprintf(os.Stderr, "Dry run: would close %d tabs (every tab under close). Run again with --yes to close them.\n", report.Duplicates)
+			}
+			return
+		}
+
+		// Never close tabs protected by the safety policy
+		var tabIDs []string
+		for _, group := range report.Groups {
+			for _, tab := range group.Close {
+				if appConfig.Safety.Protected(tab.URL) {
+					fmt.Fprintf(os.Stderr, "Skipping protected tab %s (%s)\n", tab.ID, tab.URL)
+					continue
+				}
+				tabIDs = append(tabIDs, tab.ID)
+			}
+		}
+		if len(tabIDs) == 0 {
+			fmt.Fprintln(os.Stderr, "No duplicate tabs to close")
+			return
+		}
+		if limit := appConfig.Safety.MaxBulkClose; limit > 0 && len(tabIDs) > limit {
+			fmt.Printf("Error: refusing to close %d tabs at once: the safety policy allows at most %d (safety.maxBulkClose)\n", len(tabIDs), limit)
+			os.Exit(exitError)
+		}
+
+		// Record the tabs in the trash first so that they can be reopened
+		closedTabs := trash.NewStore(appConfig.TrashOptions())
+		entries, err := closedTabs.Record(platform, "dedupe", tabs, tabIDs)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+
+		fmt.Fprintf(os.Stderr, "Closing %d duplicate tabs on %s device...\n", len(tabIDs), platform)
+
+		// Ctrl-C stops before the next tab; the timeout grows with the number of tabs
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		ctx, cancel := context.WithTimeout(ctx, profile.BatchTimeout(len(tabIDs)))
+		defer cancel()
+
+		result, err := closeDuplicateTabs(ctx, profile, debug, tabIDs)
+
+		// Tabs that were not closed leave the trash again
+		closedTabs.ForgetUnclosed(entries, result)
+
+		var batchErr *loader.BatchError
+		switch {
+		case errors.As(err, &batchErr):
+			fmt.Fprintf(os.Stderr, "Stopped: closed %d of %d tabs (%v)\n", result.Succeeded, result.Total, batchErr.Err)
+		case err != nil:
+			fmt.Printf("Error: Failed to close %s tabs: %v\n", platform, err)
+			os.Exit(exitError)
+		case result.Failed > 0:
+			fmt.Fprintf(os.Stderr, "Closed %d of %d tabs; %d failed\n", result.Succeeded, result.Total, result.Failed)
+		default:
+			fmt.Fprintf(os.Stderr, "Successfully closed %d duplicate tabs\n", result.Succeeded)
+		}
+
+		printBatchResult(result, outputFormat)
+		if code := batchExitCode(result, err); code != exitOK {
+			os.Exit(code)
+		}
+	},
+}
+
+// closeDuplicateTabs closes tabs on the Android or iOS device of a profile
+func closeDuplicateTabs(ctx context.Context, profile config.Profile, debug bool, tabIDs []string) (*loader.BatchResult, error) {
+	progress := func(done, total int, message string) {
+		fmt.Fprintf(os.Stderr, "  [%d/%d] %s\n", done, total, message)
+	}
+
+	switch profile.Platform {
+	case "android":
+		androidConfig := profile.AndroidConfig(debug)
+		androidConfig.Progress = progress
+		androidDriver := driver.NewAndroidDriver(androidConfig)
+		if err := androidDriver.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to start Android driver: %w", err)
+		}
+		// Clean up even when interrupted
+		defer androidDriver.Stop(context.WithoutCancel(ctx))
+
+		return androidDriver.CloseTabs(ctx, tabIDs)
+
+	case "ios":
+		iosConfig := profile.IOSConfig(debug)
+		iosConfig.Progress = progress
+		iosDriver := driver.NewIOSDriver(iosConfig)
+		if err := iosDriver.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to start iOS driver: %w", err)
+		}
+		defer iosDriver.Stop(context.WithoutCancel(ctx))
+
+		return iosDriver.CloseTabs(ctx, tabIDs)
+
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s (use 'android' or 'ios')", profile.Platform)
+	}
+}
+
+func init() {
+	dedupeCmd.Flags().StringP("platform", "P", "android", "Platform for live tabs (android or ios)")
+	dedupeCmd.Flags().IntP("port", "p", 9222, "Port for device communication")
+	dedupeCmd.Flags().String("serial", "", "ADB device serial or profile name (default: the default profile or the single USB-attached device)")
+	dedupeCmd.Flags().String("adb-backend", "", "ADB backend for Android: auto, exec or native (default: $ADB_BACKEND or auto)")
+	dedupeCmd.Flags().IntP("timeout", "t", 10, "Network timeout in seconds")
+	dedupeCmd.Flags().StringP("format", "f", "json", "Output format: json or yaml")
+	dedupeCmd.Flags().Bool("by-title", false, "Also group tabs of one site whose titles are similar")
+	dedupeCmd.Flags().Float64("title-similarity", dedupe.DefaultTitleSimilarity, "Share of common title words (0 to 1) for --by-title")
+	dedupeCmd.Flags().Bool("close", false, "List the duplicate tabs to close (with --yes: close them)")
+	dedupeCmd.Flags().Bool("yes", false, "With --close: actually close the duplicate tabs on the live device")
+	dedupeCmd.Flags().Bool("debug", false, "Enable debug output")
+}
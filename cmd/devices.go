@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/kazuph/mcp-android-chrome/internal/adb"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+)
+
+var devicesCmd = &cobra.Command{
+	Use:   "devices",
+	Short: "List attached Android devices",
+	Long: `List the Android devices known to ADB, as reported by 'adb devices -l'.
+
+Use the serial of a device with the --serial flag of the android and
+reopen commands to target it when several devices are attached.
+
+Examples:
+  mcp-android-chrome devices
+  mcp-android-chrome devices --format yaml
+  mcp-android-chrome devices --adb-backend native`,
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, err := resolveFormat(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		profile, err := resolveProfile(cmd, "android")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		backend, err := adb.NewBackend(profile.ADBBackend, 10*time.Second, false)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		devices, err := backend.Devices(ctx)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		output, err := format.NewTabFormatter(outputFormat).FormatData(devices)
+		if err != nil {
+			fmt.Printf("Error: Failed to format devices: %v\n", err)
+			return
+		}
+
+		fmt.Println(output)
+	},
+}
+
+func init() {
+	devicesCmd.Flags().StringP("format", "f", "json", "Output format: json or yaml")
+	devicesCmd.Flags().String("adb-backend", "", "ADB backend: auto, exec or native (default: $ADB_BACKEND or auto)")
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/kazuph/mcp-android-chrome/internal/config"
+	"github.com/kazuph/mcp-android-chrome/internal/driver"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [from] [to]",
+	Short: "Show which tabs were opened, closed or changed between two tab sets",
+	Long: `Compare two sets of tabs and list the added, removed, navigated
+(same tab ID, new URL) and retitled (same URL, new title) tabs.
+
+Each side is one of:
+- a tabs file (as printed by the android and ios commands) or a snapshot file
+- a snapshot ID from 'mcp-android-chrome snapshots list', or "latest"
+- "live" for the tabs currently open on the device
+
+"to" defaults to "live".
+
+Examples:
+  mcp-android-chrome diff latest
+  mcp-android-chrome diff yesterday.json today.json
+  mcp-android-chrome diff 20261006T091500.000Z-android-R58M123ABC live --serial R58M123ABC
+  mcp-android-chrome diff latest live --platform ios --format yaml`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		platform, _ := cmd.Flags().GetString("platform")
+		noSnapshot, _ := cmd.Flags().GetBool("no-snapshot")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		outputFormat, err := resolveFormat(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		from := args[0]
+		to := "live"
+		if len(args) == 2 {
+			to = args[1]
+		}
+
+		profile, err := resolveProfile(cmd, platform)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), profile.TimeoutDuration()+10*time.Second)
+		defer cancel()
+
+		resolve := func(source string) ([]loader.Tab, error) {
+			if source != "live" {
+				return snapshot.NewStore(appConfig.SnapshotOptions()).LoadTabs(source)
+			}
+
+			tabs, err := loadLiveTabs(ctx, profile, debug)
+			if err != nil {
+				return nil, err
+			}
+			if !noSnapshot {
+				saveSnapshot(platform, tabs)
+			}
+			return tabs, nil
+		}
+
+		fromTabs, err := resolve(from)
+		if err != nil {
+			fmt.Printf("Error: Failed to load '%s' tabs: %v\n", from, err)
+			return
+		}
+		toTabs, err := resolve(to)
+		if err != nil {
+			fmt.Printf("Error: Failed to load '%s' tabs: %v\n", to, err)
+			return
+		}
+
+		diff := loader.DiffTabs(fromTabs, toTabs)
+		diff.From = from
+		diff.To = to
+
+		output, err := format.NewTabFormatter(outputFormat).FormatDiff(diff)
+		if err != nil {
+			fmt.Printf("Error: Failed to format tab diff: %v\n", err)
+			return
+		}
+
+		fmt.Println(output)
+	},
+}
+
+// loadLiveTabs fetches the tabs currently open on the Android or iOS device
+// of a profile
+func loadLiveTabs(ctx context.Context, profile config.Profile, debug bool) ([]loader.Tab, error) {
+	switch profile.Platform {
+	case "android":
+		androidDriver := driver.NewAndroidDriver(profile.AndroidConfig(debug))
+		if err := androidDriver.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to start Android driver: %w", err)
+		}
+		defer androidDriver.Stop(ctx)
+
+		return androidDriver.LoadTabs(ctx)
+
+	case "ios":
+		iosDriver := driver.NewIOSDriver(profile.IOSConfig(debug))
+		if err := iosDriver.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to start iOS driver: %w", err)
+		}
+		defer iosDriver.Stop(ctx)
+
+		return iosDriver.LoadTabs(ctx)
+
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s (use 'android' or 'ios')", profile.Platform)
+	}
+}
+
+func init() {
+	diffCmd.Flags().StringP("platform", "P", "android", "Platform for live tabs (android or ios)")
+	diffCmd.Flags().IntP("port", "p", 9222, "Port for device communication")
+	diffCmd.Flags().String("serial", "", "ADB device serial or profile name (default: the default profile or the single USB-attached device)")
+	diffCmd.Flags().String("adb-backend", "", "ADB backend for Android: auto, exec or native (default: $ADB_BACKEND or auto)")
+	diffCmd.Flags().IntP("timeout", "t", 10, "Network timeout in seconds")
+	diffCmd.Flags().StringP("format", "f", "json", "Output format: json or yaml")
+	diffCmd.Flags().Bool("no-snapshot", false, "Do not save a snapshot of live tabs to the snapshot history")
+	diffCmd.Flags().Bool("debug", false, "Enable debug output")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// Exit codes of the commands that work on a batch of tabs, so that scripts
+// can tell a partial failure from a total one
+const (
+	exitOK = 0
+	// Nothing was done: invalid arguments, or the device could not be reached
+	exitError = 1
+	// Some tabs failed; the result lists them with their errors
+	exitPartial = 2
+	// Interrupted or timed out; the result lists the tabs not attempted as skipped
+	exitStopped = 3
+)
+
+// batchExitCode returns the exit code of a batch operation from its result
+// and the error that stopped it, if any
+func batchExitCode(result *loader.BatchResult, err error) int {
+	var batchErr *loader.BatchError
+	switch {
+	case errors.As(err, &batchErr):
+		return exitStopped
+	case err != nil || result == nil:
+		return exitError
+	case result.Failed > 0:
+		return exitPartial
+	default:
+		return exitOK
+	}
+}
+
+// printBatchResult writes the result of every tab of a batch operation to
+// stdout in the output format, for scripts to retry the failed tabs
+func printBatchResult(result *loader.BatchResult, outputFormat format.Format) {
+	formattedResult, err := format.NewTabFormatter(outputFormat).FormatData(result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to format result: %v\n", err)
+		return
+	}
+	fmt.Println(formattedResult)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/kazuph/mcp-android-chrome/internal/driver"
+	"github.com/kazuph/mcp-android-chrome/internal/query"
 )
 
 var iosCmd = &cobra.Command{
@@ -25,25 +26,29 @@ This command will:
 1. Start ios_webkit_debug_proxy as background process
 2. Connect to WebKit Debug Protocol on device
 3. Retrieve all open tabs
-4. Output tab information as JSON`,
+4. Output tab information as JSON
+5. Save a snapshot of the tabs to the snapshot history
+   (see 'mcp-android-chrome snapshots')`,
 	Run: func(cmd *cobra.Command, args []string) {
-		port, _ := cmd.Flags().GetInt("port")
-		timeout, _ := cmd.Flags().GetInt("timeout")
-		wait, _ := cmd.Flags().GetInt("wait")
 		debug, _ := cmd.Flags().GetBool("debug")
+		noSnapshot, _ := cmd.Flags().GetBool("no-snapshot")
+		queryStr, _ := cmd.Flags().GetString("query")
 
-		config := driver.IOSConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    port,
-				Timeout: time.Duration(timeout) * time.Second,
-				Debug:   debug,
-			},
-			Wait: time.Duration(wait) * time.Second,
+		q, err := query.Parse(queryStr)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
 
-		iosDriver := driver.NewIOSDriver(config)
+		profile, err := resolveProfile(cmd, "ios")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		iosDriver := driver.NewIOSDriver(profile.IOSConfig(debug))
 		
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout+10)*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), profile.TimeoutDuration()+10*time.Second)
 		defer cancel()
 
 		fmt.Println("Starting iOS Chrome/Safari tab copy...")
@@ -62,16 +67,25 @@ This command will:
 			return
 		}
 
-		// Output results
-		fmt.Printf("Successfully copied %d tabs from iOS device:\n\n", len(tabs))
+		// Output results, only the tabs matching the query
+		matching := q.Filter(tabs)
+		if q.Empty() {
+			fmt.Printf("Successfully copied %d tabs from iOS device:\n\n", len(tabs))
+		} else {
+			fmt.Printf("Successfully copied %d tabs from iOS device, %d matching %s:\n\n", len(tabs), len(matching), q)
+		}
 		
-		tabsJSON, err := json.MarshalIndent(tabs, "", "  ")
+		tabsJSON, err := json.MarshalIndent(matching, "", "  ")
 		if err != nil {
 			fmt.Printf("Error: Failed to format tabs: %v\n", err)
 			return
 		}
 		
 		fmt.Println(string(tabsJSON))
+
+		if !noSnapshot {
+			saveSnapshot("ios", tabs)
+		}
 	},
 }
 
@@ -80,4 +94,6 @@ func init() {
 	iosCmd.Flags().IntP("timeout", "t", 10, "Network timeout in seconds")
 	iosCmd.Flags().IntP("wait", "w", 2, "Wait time before starting in seconds")
 	iosCmd.Flags().Bool("debug", false, "Enable debug output")
+	iosCmd.Flags().Bool("no-snapshot", false, "Do not save a snapshot of the tabs to the snapshot history")
+	iosCmd.Flags().StringP("query", "q", "", "Only output tabs matching this search query (e.g. 'host:github.com -title:notifications'); the snapshot keeps all tabs")
 }
\ No newline at end of file
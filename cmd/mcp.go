@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/kazuph/mcp-android-chrome/internal/config"
 	"github.com/kazuph/mcp-android-chrome/internal/mcp"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +21,19 @@ The server exposes tools for:
 - reopen_tabs: Restore saved tabs to mobile devices
 - check_environment: Verify system dependencies
 
+Device profiles, defaults, cache settings and safety policies are read
+from the config file (see --config). The flags below override the config
+file and the environment for every tool call, including calls that name a
+device profile.
+
+By default the server talks to the client that spawned it over stdio. With
+--transport http (streamable HTTP on /mcp) or --transport sse (HTTP+SSE on
+/sse and /messages) it listens on --address instead, so that one
+long-running server attached to the device can be shared by several
+clients and machines, keeping the tab cache warm between sessions. Set
+--token (or $MCP_ANDROID_CHROME_TOKEN) to require
+"Authorization: Bearer <token>" from clients.
+
 Configure in Claude Desktop's claude_desktop_config.json:
 {
   "mcpServers": {
@@ -33,8 +47,24 @@ Configure in Claude Desktop's claude_desktop_config.json:
 		// Don't print anything to stdout - MCP uses stdio for JSON-RPC communication
 		// Any debug output should go to stderr instead
 		
-		server := mcp.NewTabTransferServer()
-		if err := server.Start(); err != nil {
+		applyMCPFlags(cmd)
+		if err := appConfig.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid MCP server settings: %v\n", err)
+			os.Exit(1)
+		}
+
+		server := mcp.NewTabTransferServer(appConfig)
+		var err error
+		if appConfig.Server.Transport == "stdio" {
+			err = server.Start()
+		} else {
+			err = server.StartHTTP(mcp.HTTPOptions{
+				Transport: appConfig.Server.Transport,
+				Address:   appConfig.Server.Address,
+				Token:     appConfig.Server.Token,
+			})
+		}
+		if err != nil {
 			// Use stderr for error messages in MCP mode
 			fmt.Fprintf(os.Stderr, "Failed to start MCP server: %v\n", err)
 			os.Exit(1)
@@ -42,6 +72,72 @@ Configure in Claude Desktop's claude_desktop_config.json:
 	},
 }
 
+// applyMCPFlags overrides the loaded config with the flags of the mcp command.
+// Connection flags are applied to the profile selected with --profile (or
+// defaults.profile), or to the defaults when none is selected, so that
+// other profiles keep their own ports and sockets.
+func applyMCPFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	var connection config.ProfileSettings
+	if flags.Changed("port") {
+		port, _ := flags.GetInt("port")
+		connection.Port = &port
+	}
+	if flags.Changed("socket") {
+		connection.Socket, _ = flags.GetString("socket")
+	}
+	if flags.Changed("timeout") {
+		timeout, _ := flags.GetInt("timeout")
+		connection.Timeout = &timeout
+	}
+	if flags.Changed("wait") {
+		wait, _ := flags.GetInt("wait")
+		connection.Wait = &wait
+	}
+	if _, selected := appConfig.Profiles[appConfig.Defaults.Profile]; selected {
+		appConfig.OverrideProfile(appConfig.Defaults.Profile, connection)
+	} else {
+		appConfig.OverrideDefaults(connection)
+	}
+	if flags.Changed("adb-backend") {
+		backend, _ := flags.GetString("adb-backend")
+		appConfig.OverrideAll(config.ProfileSettings{ADBBackend: backend})
+	}
+	if flags.Changed("format") {
+		appConfig.Defaults.Format, _ = flags.GetString("format")
+	}
+	if flags.Changed("page-size") {
+		appConfig.Cache.PageSize, _ = flags.GetInt("page-size")
+	}
+	if flags.Changed("refresh-interval") {
+		appConfig.Cache.RefreshInterval, _ = flags.GetString("refresh-interval")
+	}
+	if flags.Changed("refresh-port") {
+		appConfig.Cache.RefreshPort, _ = flags.GetInt("refresh-port")
+	}
+	if flags.Changed("transport") {
+		appConfig.Server.Transport, _ = flags.GetString("transport")
+	}
+	if flags.Changed("address") {
+		appConfig.Server.Address, _ = flags.GetString("address")
+	}
+	if flags.Changed("token") {
+		appConfig.Server.Token, _ = flags.GetString("token")
+	}
+}
+
 func init() {
-	// MCP server flags can be added here if needed
+	mcpCmd.Flags().IntP("port", "p", 9222, "Local port for ADB forwarding and the iOS WebKit Debug Proxy")
+	mcpCmd.Flags().StringP("socket", "s", "chrome_devtools_remote", "ADB socket name")
+	mcpCmd.Flags().IntP("timeout", "t", 10, "Network timeout in seconds")
+	mcpCmd.Flags().IntP("wait", "w", 2, "Wait time before starting in seconds")
+	mcpCmd.Flags().String("adb-backend", "", "ADB backend: auto, exec or native (default: $ADB_BACKEND or auto)")
+	mcpCmd.Flags().StringP("format", "f", "json", "Default output format of the tools: json or yaml")
+	mcpCmd.Flags().Int("page-size", 50, "Number of tabs per page of tab listings")
+	mcpCmd.Flags().String("refresh-interval", "1m", "Tab cache auto-refresh interval, on by default (0 to disable)")
+	mcpCmd.Flags().Int("refresh-port", 0, "Local port forwarded by tab cache auto-refreshes (default: a free port)")
+	mcpCmd.Flags().String("transport", "stdio", "MCP transport: stdio, http (streamable HTTP) or sse")
+	mcpCmd.Flags().String("address", "127.0.0.1:8765", "Address the http and sse transports listen on")
+	mcpCmd.Flags().String("token", "", "Bearer token required from http and sse clients (default: $MCP_ANDROID_CHROME_TOKEN)")
 }
\ No newline at end of file
@@ -3,13 +3,15 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"time"
+	"os/signal"
 
 	"github.com/spf13/cobra"
 	"github.com/kazuph/mcp-android-chrome/internal/driver"
 	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
 )
 
 var reopenCmd = &cobra.Command{
@@ -18,7 +20,8 @@ var reopenCmd = &cobra.Command{
 	Long: `Restore previously saved tabs to an Android or iOS mobile device.
 
 This command reads a JSON file containing tab information and restores
-those tabs to the specified mobile device platform.
+those tabs to the specified mobile device platform. With --snapshot the tabs
+are taken from the snapshot history instead (see 'mcp-android-chrome snapshots').
 
 For Android:
 - Uses ADB and Chrome DevTools Protocol
@@ -30,96 +33,145 @@ For iOS:
 
 Examples:
   mcp-android-chrome reopen --platform android tabs.json
-  mcp-android-chrome reopen --platform ios --port 9222 saved-tabs.json`,
-	Args: cobra.ExactArgs(1),
+  mcp-android-chrome reopen --platform android --serial R58M123ABC tabs.json
+  mcp-android-chrome reopen --platform ios --port 9222 saved-tabs.json
+  mcp-android-chrome reopen --platform android --snapshot latest
+  mcp-android-chrome reopen --platform android tabs.json --format yaml
+
+The result of every tab (id, url, title, status, error and the ID of the new
+tab) is written to stdout as JSON or YAML; progress goes to stderr. A tab
+that fails to open does not stop the others. Ctrl-C stops before the next
+tab and reports the remaining tabs as skipped.
+
+Exit codes:
+  0  all tabs were restored
+  1  nothing was restored (invalid arguments or device not reachable)
+  2  some tabs failed to restore (status "failed" in the result)
+  3  interrupted or timed out (remaining tabs have status "skipped")`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		platform, _ := cmd.Flags().GetString("platform")
-		port, _ := cmd.Flags().GetInt("port")
-		timeout, _ := cmd.Flags().GetInt("timeout")
 		debug, _ := cmd.Flags().GetBool("debug")
+		snapshotID, _ := cmd.Flags().GetString("snapshot")
 
 		if platform == "" {
 			fmt.Println("Error: --platform flag is required (android or ios)")
-			return
+			os.Exit(exitError)
 		}
 
-		// Read tabs from file
-		tabsFile := args[0]
-		tabsData, err := os.ReadFile(tabsFile)
+		var tabs []loader.Tab
+		switch {
+		case snapshotID != "" && len(args) > 0:
+			fmt.Println("Error: pass either a tabs file or --snapshot, not both")
+			os.Exit(exitError)
+
+		case snapshotID != "":
+			// Load tabs from the snapshot history
+			snap, err := snapshot.NewStore(appConfig.SnapshotOptions()).Load(snapshotID)
+			if err != nil {
+				fmt.Printf("Error: Failed to load snapshot: %v\n", err)
+				os.Exit(exitError)
+			}
+			tabs = snap.Tabs
+
+		case len(args) == 1:
+			// Read tabs from file
+			tabsFile := args[0]
+			tabsData, err := os.ReadFile(tabsFile)
+			if err != nil {
+				fmt.Printf("Error: Failed to read tabs file: %v\n", err)
+				os.Exit(exitError)
+			}
+
+			if err := json.Unmarshal(tabsData, &tabs); err != nil {
+				fmt.Printf("Error: Failed to parse tabs JSON: %v\n", err)
+				os.Exit(exitError)
+			}
+
+		default:
+			fmt.Println("Error: a tabs file or --snapshot is required")
+			os.Exit(exitError)
+		}
+
+		if platform != "android" && platform != "ios" {
+			fmt.Printf("Error: Unsupported platform: %s (use 'android' or 'ios')\n", platform)
+			os.Exit(exitError)
+		}
+
+		profile, err := resolveProfile(cmd, platform)
 		if err != nil {
-			fmt.Printf("Error: Failed to read tabs file: %v\n", err)
-			return
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
 		}
 
-		var tabs []loader.Tab
-		if err := json.Unmarshal(tabsData, &tabs); err != nil {
-			fmt.Printf("Error: Failed to parse tabs JSON: %v\n", err)
-			return
+		outputFormat, err := resolveFormat(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
 		}
 
-		fmt.Printf("Restoring %d tabs to %s device...\n", len(tabs), platform)
+		fmt.Fprintf(os.Stderr, "Restoring %d tabs to %s device...\n", len(tabs), platform)
 
-		timeout_duration := time.Duration(timeout) * time.Second
-		ctx, cancel := context.WithTimeout(context.Background(), timeout_duration+10*time.Second)
+		// Ctrl-C stops before the next tab; the timeout grows with the number of tabs
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		ctx, cancel := context.WithTimeout(ctx, profile.BatchTimeout(len(tabs)))
 		defer cancel()
 
+		progress := func(done, total int, message string) {
+			fmt.Fprintf(os.Stderr, "  [%d/%d] %s\n", done, total, message)
+		}
+
+		var result *loader.BatchResult
 		switch platform {
 		case "android":
-			if err := restoreAndroidTabs(ctx, tabs, port, timeout_duration, debug); err != nil {
-				fmt.Printf("Error: Failed to restore Android tabs: %v\n", err)
-				return
-			}
-			fmt.Printf("Successfully restored %d tabs to Android device\n", len(tabs))
-
+			androidConfig := profile.AndroidConfig(debug)
+			androidConfig.Progress = progress
+			result, err = restoreAndroidTabs(ctx, tabs, androidConfig)
 		case "ios":
-			if err := restoreIOSTabs(ctx, tabs, port, timeout_duration, debug); err != nil {
-				fmt.Printf("Error: Failed to restore iOS tabs: %v\n", err)
-				return
-			}
-			fmt.Printf("Successfully initiated restoration of %d tabs to iOS device\n", len(tabs))
+			result, err = restoreIOSTabs(ctx, tabs, profile.IOSConfig(debug))
+		}
 
+		var batchErr *loader.BatchError
+		switch {
+		case errors.As(err, &batchErr):
+			fmt.Fprintf(os.Stderr, "Stopped: restored %d of %d tabs (%v)\n", result.Succeeded, result.Total, batchErr.Err)
+		case err != nil:
+			fmt.Printf("Error: Failed to restore %s tabs: %v\n", platform, err)
+			os.Exit(exitError)
+		case result.Failed > 0:
+			fmt.Fprintf(os.Stderr, "Restored %d of %d tabs; %d failed\n", result.Succeeded, result.Total, result.Failed)
+		case platform == "ios":
+			fmt.Fprintf(os.Stderr, "Successfully initiated restoration of %d tabs to iOS device\n", len(tabs))
 		default:
-			fmt.Printf("Error: Unsupported platform: %s (use 'android' or 'ios')\n", platform)
-			return
+			fmt.Fprintf(os.Stderr, "Successfully restored %d tabs to Android device\n", len(tabs))
+		}
+
+		printBatchResult(result, outputFormat)
+		if code := batchExitCode(result, err); code != exitOK {
+			os.Exit(code)
 		}
 	},
 }
 
-func restoreAndroidTabs(ctx context.Context, tabs []loader.Tab, port int, timeout time.Duration, debug bool) error {
-	config := driver.AndroidConfig{
-		DriverConfig: driver.DriverConfig{
-			Port:    port,
-			Timeout: timeout,
-			Debug:   debug,
-		},
-		Socket: "chrome_devtools_remote",
-		Wait:   2 * time.Second,
-	}
-	
+func restoreAndroidTabs(ctx context.Context, tabs []loader.Tab, config driver.AndroidConfig) (*loader.BatchResult, error) {
 	androidDriver := driver.NewAndroidDriver(config)
 	if err := androidDriver.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start Android driver: %w", err)
+		return nil, fmt.Errorf("failed to start Android driver: %w", err)
 	}
-	defer androidDriver.Stop(ctx)
+	// Clean up even when interrupted
+	defer androidDriver.Stop(context.WithoutCancel(ctx))
 	
 	return androidDriver.RestoreTabs(ctx, tabs)
 }
 
-func restoreIOSTabs(ctx context.Context, tabs []loader.Tab, port int, timeout time.Duration, debug bool) error {
-	config := driver.IOSConfig{
-		DriverConfig: driver.DriverConfig{
-			Port:    port,
-			Timeout: timeout,
-			Debug:   debug,
-		},
-		Wait: 2 * time.Second,
-	}
-	
+func restoreIOSTabs(ctx context.Context, tabs []loader.Tab, config driver.IOSConfig) (*loader.BatchResult, error) {
 	iosDriver := driver.NewIOSDriver(config)
 	if err := iosDriver.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start iOS driver: %w", err)
+		return nil, fmt.Errorf("failed to start iOS driver: %w", err)
 	}
-	defer iosDriver.Stop(ctx)
+	// Clean up even when interrupted
+	defer iosDriver.Stop(context.WithoutCancel(ctx))
 	
 	return iosDriver.RestoreTabs(ctx, tabs)
 }
@@ -127,7 +179,11 @@ func restoreIOSTabs(ctx context.Context, tabs []loader.Tab, port int, timeout ti
 func init() {
 	reopenCmd.Flags().StringP("platform", "P", "", "Target platform (android or ios) [required]")
 	reopenCmd.Flags().IntP("port", "p", 9222, "Port for device communication")
+	reopenCmd.Flags().String("serial", "", "ADB device serial or profile name (default: the default profile or the single USB-attached device)")
+	reopenCmd.Flags().String("adb-backend", "", "ADB backend for Android: auto, exec or native (default: $ADB_BACKEND or auto)")
 	reopenCmd.Flags().IntP("timeout", "t", 10, "Network timeout in seconds")
 	reopenCmd.Flags().Bool("debug", false, "Enable debug output")
+	reopenCmd.Flags().String("snapshot", "", "Restore the tabs of this stored snapshot ID (or latest) instead of a tabs file")
+	reopenCmd.Flags().StringP("format", "f", "json", "Output format of the per-tab result: json or yaml")
 	reopenCmd.MarkFlagRequired("platform")
 }
\ No newline at end of file
@@ -15,9 +15,17 @@ This tool supports:
 - Copying tabs from Android Chrome via ADB
 - Copying tabs from iOS Chrome/Safari via iOS WebKit Debug Proxy
 - Reopening saved tabs on mobile devices
+- Keeping a history of tab snapshots
 - Environment dependency checking
 
+Ports, sockets, timeouts, device profiles, the output format, cache settings
+and safety policies can be set in a YAML config file, by default config.yaml
+in the mcp-android-chrome user config directory ($MCP_ANDROID_CHROME_CONFIG or
+--config to override). Flags win over environment variables, which win over
+the config file.
+
 Original tool by machinateur, Go port by kazuph.`,
+	PersistentPreRunE: loadConfig,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -26,9 +34,17 @@ func Execute() error {
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("config", "", "Config file (default: $MCP_ANDROID_CHROME_CONFIG or config.yaml in the user config directory)")
+	rootCmd.PersistentFlags().String("profile", "", "Device profile of the config file to use (default: $TAB_PROFILE or defaults.profile)")
+
 	rootCmd.AddCommand(mcpCmd)
 	rootCmd.AddCommand(androidCmd)
 	rootCmd.AddCommand(iosCmd)
 	rootCmd.AddCommand(reopenCmd)
 	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(devicesCmd)
+	rootCmd.AddCommand(activateCmd)
+	rootCmd.AddCommand(snapshotsCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(dedupeCmd)
 }
\ No newline at end of file
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+)
+
+var snapshotsCmd = &cobra.Command{
+	Use:   "snapshots",
+	Short: "Manage the on-disk history of tab snapshots",
+	Long: `Every tab fetch by the android and ios commands and by the MCP server
+saves a timestamped snapshot of the tabs, tagged with the device it came from.
+
+Snapshots are stored as JSON files in the user config directory
+(override with snapshots.dir or $TAB_SNAPSHOT_DIR). By default at most 500
+snapshots are kept for at most 90 days (snapshots.maxCount and
+snapshots.maxAgeDays, or $TAB_SNAPSHOT_MAX_COUNT and $TAB_SNAPSHOT_MAX_AGE_DAYS,
+0 for no limit). Set snapshots.disabled or $TAB_SNAPSHOT_DISABLE=1 to stop
+saving snapshots.
+
+Examples:
+  mcp-android-chrome snapshots list --since 2026-10-06 --until 2026-10-06
+  mcp-android-chrome snapshots show latest > tabs.json
+  mcp-android-chrome reopen --platform android --snapshot latest
+  mcp-android-chrome snapshots delete 20261006T091500.000Z-android-R58M123ABC`,
+}
+
+var snapshotsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored snapshots, newest first",
+	Run: func(cmd *cobra.Command, args []string) {
+		platform, _ := cmd.Flags().GetString("platform")
+		device, _ := cmd.Flags().GetString("device")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		untilStr, _ := cmd.Flags().GetString("until")
+		limit, _ := cmd.Flags().GetInt("limit")
+		outputFormat, err := resolveFormat(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		since, until, err := snapshot.ParseRange(sinceStr, untilStr)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		metas, err := snapshot.NewStore(appConfig.SnapshotOptions()).List(snapshot.Filter{
+			Platform: platform,
+			Device:   device,
+			Since:    since,
+			Until:    until,
+			Limit:    limit,
+		})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		output, err := format.NewTabFormatter(outputFormat).FormatData(metas)
+		if err != nil {
+			fmt.Printf("Error: Failed to format snapshots: %v\n", err)
+			return
+		}
+
+		fmt.Println(output)
+	},
+}
+
+var snapshotsShowCmd = &cobra.Command{
+	Use:   "show [snapshot-id]",
+	Short: "Print the tabs of a snapshot",
+	Long: `Print the tabs of a stored snapshot. Use "latest" for the newest snapshot.
+
+The JSON output can be passed to the reopen command as a tabs file.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, err := resolveFormat(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		snap, err := snapshot.NewStore(appConfig.SnapshotOptions()).Load(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		output, err := format.NewTabFormatter(outputFormat).FormatTabs(snap.Tabs)
+		if err != nil {
+			fmt.Printf("Error: Failed to format tabs: %v\n", err)
+			return
+		}
+
+		fmt.Println(output)
+	},
+}
+
+var snapshotsDeleteCmd = &cobra.Command{
+	Use:   "delete [snapshot-id...]",
+	Short: "Delete stored snapshots",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := snapshot.NewStore(appConfig.SnapshotOptions())
+		for _, id := range args {
+			if err := store.Delete(id); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Deleted snapshot %s\n", id)
+		}
+	},
+}
+
+var snapshotsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete snapshots beyond the retention limits",
+	Run: func(cmd *cobra.Command, args []string) {
+		deleted, err := snapshot.NewStore(appConfig.SnapshotOptions()).Prune()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		fmt.Printf("Deleted %d snapshots\n", deleted)
+	},
+}
+
+// saveSnapshot records fetched tabs in the snapshot history. Failures are
+// reported but do not fail the command.
+func saveSnapshot(platform string, tabs []loader.Tab) {
+	meta, err := snapshot.NewStore(appConfig.SnapshotOptions()).Save(platform, "cli", tabs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to save snapshot: %v\n", err)
+	}
+	if meta != nil {
+		fmt.Fprintf(os.Stderr, "Saved snapshot %s\n", meta.ID)
+	}
+}
+
+func init() {
+	snapshotsListCmd.Flags().String("platform", "", "Only snapshots from this platform (android or ios)")
+	snapshotsListCmd.Flags().String("device", "", "Only snapshots from this device (ADB serial or iOS device name)")
+	snapshotsListCmd.Flags().String("since", "", "Only snapshots captured at or after this time (RFC 3339 or YYYY-MM-DD)")
+	snapshotsListCmd.Flags().String("until", "", "Only snapshots captured at or before this time (RFC 3339 or YYYY-MM-DD)")
+	snapshotsListCmd.Flags().IntP("limit", "n", 0, "Maximum number of snapshots to list (default: all)")
+	snapshotsListCmd.Flags().StringP("format", "f", "json", "Output format: json or yaml")
+
+	snapshotsShowCmd.Flags().StringP("format", "f", "json", "Output format: json or yaml")
+
+	snapshotsCmd.AddCommand(snapshotsListCmd)
+	snapshotsCmd.AddCommand(snapshotsShowCmd)
+	snapshotsCmd.AddCommand(snapshotsDeleteCmd)
+	snapshotsCmd.AddCommand(snapshotsPruneCmd)
+}
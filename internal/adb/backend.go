@@ -0,0 +1,218 @@
+package adb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kazuph/mcp-android-chrome/internal/platform"
+)
+
+// Backend names accepted by NewBackend
+const (
+	BackendAuto   = "auto"
+	BackendExec   = "exec"
+	BackendNative = "native"
+)
+
+// Backend abstracts the ADB operations the Android driver needs, so they can
+// be served by either the adb binary or the in-process host-protocol client
+type Backend interface {
+	Name() string
+	CheckAvailable(ctx context.Context) error
+	Devices(ctx context.Context) ([]platform.ADBDevice, error)
+	Forward(ctx context.Context, serial, local, remote string) error
+	KillForward(ctx context.Context, serial, local string) error
+	ListForward(ctx context.Context) ([]Forward, error)
+}
+
+// Forward describes an active ADB port forward
+type Forward struct {
+	Serial string `json:"serial" yaml:"serial"`
+	Local  string `json:"local" yaml:"local"`
+	Remote string `json:"remote" yaml:"remote"`
+}
+
+// ParseForwards parses the text format shared by `adb forward --list` and the
+// host:list-forward service: one "serial local remote" entry per line
+func ParseForwards(output string) []Forward {
+	forwards := make([]Forward, 0)
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		forwards = append(forwards, Forward{
+			Serial: fields[0],
+			Local:  fields[1],
+			Remote: fields[2],
+		})
+	}
+
+	return forwards
+}
+
+// NewBackend resolves a backend by name. An empty name falls back to the
+// ADB_BACKEND environment variable and then to auto, which prefers the adb
+// binary and uses the native client when the binary is not available.
+func NewBackend(name string, timeout time.Duration, debug bool) (Backend, error) {
+	if name == "" {
+		name = os.Getenv("ADB_BACKEND")
+	}
+	if name == "" {
+		name = BackendAuto
+	}
+
+	switch name {
+	case BackendExec:
+		return NewExecBackend(debug), nil
+	case BackendNative:
+		return NewNativeBackend(NewClient("", timeout, debug)), nil
+	case BackendAuto:
+		if platform.CheckADBAvailable() == nil {
+			return NewExecBackend(debug), nil
+		}
+		if debug {
+			fmt.Fprintln(os.Stderr, "adb binary not available, using native ADB client")
+		}
+		return NewNativeBackend(NewClient("", timeout, debug)), nil
+	default:
+		return nil, fmt.Errorf("unsupported ADB backend: %s (use 'auto', 'exec' or 'native')", name)
+	}
+}
+
+// NativeBackend serves ADB operations through the host-protocol Client
+type NativeBackend struct {
+	client *Client
+}
+
+// NewNativeBackend creates a backend using the given host-protocol client
+func NewNativeBackend(client *Client) *NativeBackend {
+	return &NativeBackend{
+		client: client,
+	}
+}
+
+// Name returns the backend name
+func (b *NativeBackend) Name() string {
+	return BackendNative
+}
+
+// CheckAvailable verifies the adb server is reachable
+func (b *NativeBackend) CheckAvailable(ctx context.Context) error {
+	if _, err := b.client.Version(ctx); err != nil {
+		return fmt.Errorf("adb server not reachable at %s: %w. Start it with 'adb start-server' on this or another host (set ADB_SERVER_SOCKET=tcp:host:port for a remote server)", b.client.Address(), err)
+	}
+	return nil
+}
+
+// Devices lists the devices known to the adb server
+func (b *NativeBackend) Devices(ctx context.Context) ([]platform.ADBDevice, error) {
+	return b.client.Devices(ctx)
+}
+
+// Forward sets up a port forward on the selected device
+func (b *NativeBackend) Forward(ctx context.Context, serial, local, remote string) error {
+	return b.client.Forward(ctx, serial, local, remote)
+}
+
+// KillForward removes a port forward from the selected device
+func (b *NativeBackend) KillForward(ctx context.Context, serial, local string) error {
+	return b.client.KillForward(ctx, serial, local)
+}
+
+// ListForward lists all active port forwards
+func (b *NativeBackend) ListForward(ctx context.Context) ([]Forward, error) {
+	return b.client.ListForward(ctx)
+}
+
+// ExecBackend serves ADB operations by running the adb binary
+type ExecBackend struct {
+	debug bool
+}
+
+// NewExecBackend creates a backend shelling out to the adb binary
+func NewExecBackend(debug bool) *ExecBackend {
+	return &ExecBackend{
+		debug: debug,
+	}
+}
+
+// Name returns the backend name
+func (b *ExecBackend) Name() string {
+	return BackendExec
+}
+
+// CheckAvailable verifies the adb binary is installed and working
+func (b *ExecBackend) CheckAvailable(ctx context.Context) error {
+	return platform.CheckADBAvailable()
+}
+
+// Devices runs `adb devices -l` and returns the parsed device records
+func (b *ExecBackend) Devices(ctx context.Context) ([]platform.ADBDevice, error) {
+	output, err := b.run(ctx, "devices", "-l")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ADB devices: %w", err)
+	}
+
+	return platform.ParseADBDevices(output), nil
+}
+
+// Forward runs `adb forward local remote` on the selected device
+func (b *ExecBackend) Forward(ctx context.Context, serial, local, remote string) error {
+	if _, err := b.run(ctx, deviceArgs(serial, "forward", local, remote)...); err != nil {
+		return fmt.Errorf("failed to setup ADB port forwarding: %w", err)
+	}
+	return nil
+}
+
+// KillForward runs `adb forward --remove local` on the selected device
+func (b *ExecBackend) KillForward(ctx context.Context, serial, local string) error {
+	if _, err := b.run(ctx, deviceArgs(serial, "forward", "--remove", local)...); err != nil {
+		return fmt.Errorf("failed to cleanup ADB port forwarding: %w", err)
+	}
+	return nil
+}
+
+// ListForward runs `adb forward --list` and returns the parsed forwards
+func (b *ExecBackend) ListForward(ctx context.Context) ([]Forward, error) {
+	output, err := b.run(ctx, "forward", "--list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ADB port forwards: %w", err)
+	}
+
+	return ParseForwards(output), nil
+}
+
+// run executes adb with the given arguments and returns its standard output
+func (b *ExecBackend) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, platform.FindADBPath(), args...)
+
+	if b.debug {
+		fmt.Fprintf(os.Stderr, "Executing: %s\n", cmd.String())
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+
+	return string(output), nil
+}
+
+// deviceArgs prefixes args with the device selector: -s <serial> when a serial
+// is given, otherwise -d for the single USB-attached device
+func deviceArgs(serial string, args ...string) []string {
+	if serial != "" {
+		return append([]string{"-s", serial}, args...)
+	}
+	return append([]string{"-d"}, args...)
+}
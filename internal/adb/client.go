@@ -0,0 +1,252 @@
+package adb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kazuph/mcp-android-chrome/internal/platform"
+)
+
+// DefaultServerAddress is the address the adb server listens on by default
+const DefaultServerAddress = "localhost:5037"
+
+// Client talks to the adb server over its host protocol without the adb binary
+type Client struct {
+	address string
+	timeout time.Duration
+	debug   bool
+}
+
+// NewClient creates a new adb host-protocol client. An empty address falls
+// back to ServerAddress().
+func NewClient(address string, timeout time.Duration, debug bool) *Client {
+	if address == "" {
+		address = ServerAddress()
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &Client{
+		address: address,
+		timeout: timeout,
+		debug:   debug,
+	}
+}
+
+// ServerAddress resolves the adb server address from the same environment
+// variables the adb binary honours: ADB_SERVER_SOCKET (tcp:host:port),
+// ANDROID_ADB_SERVER_ADDRESS and ANDROID_ADB_SERVER_PORT.
+func ServerAddress() string {
+	if socket := os.Getenv("ADB_SERVER_SOCKET"); socket != "" {
+		if addr, found := strings.CutPrefix(socket, "tcp:"); found {
+			if !strings.Contains(addr, ":") {
+				return net.JoinHostPort("localhost", addr)
+			}
+			return addr
+		}
+	}
+
+	host := os.Getenv("ANDROID_ADB_SERVER_ADDRESS")
+	if host == "" {
+		host = "localhost"
+	}
+	port := os.Getenv("ANDROID_ADB_SERVER_PORT")
+	if port == "" {
+		port = "5037"
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// Address returns the adb server address used by the client
+func (c *Client) Address() string {
+	return c.address
+}
+
+// Version queries host:version and returns the adb server protocol version
+func (c *Client) Version(ctx context.Context) (int, error) {
+	payload, err := c.query(ctx, "host:version")
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := strconv.ParseInt(payload, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid adb server version %q: %w", payload, err)
+	}
+
+	return int(version), nil
+}
+
+// Devices queries host:devices-l and returns the parsed device records
+func (c *Client) Devices(ctx context.Context) ([]platform.ADBDevice, error) {
+	payload, err := c.query(ctx, "host:devices-l")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ADB devices: %w", err)
+	}
+
+	return platform.ParseADBDevices(payload), nil
+}
+
+// Forward sets up a port forward from local (e.g. tcp:9222) to remote
+// (e.g. localabstract:chrome_devtools_remote) on the device with the given
+// serial. An empty serial selects the single USB-attached device.
+func (c *Client) Forward(ctx context.Context, serial, local, remote string) error {
+	service := fmt.Sprintf("%sforward:%s;%s", hostPrefix(serial), local, remote)
+	if err := c.exec(ctx, service); err != nil {
+		return fmt.Errorf("failed to setup ADB port forwarding: %w", err)
+	}
+
+	return nil
+}
+
+// KillForward removes the port forward listening on local
+func (c *Client) KillForward(ctx context.Context, serial, local string) error {
+	service := fmt.Sprintf("%skillforward:%s", hostPrefix(serial), local)
+	if err := c.exec(ctx, service); err != nil {
+		return fmt.Errorf("failed to remove ADB port forwarding: %w", err)
+	}
+
+	return nil
+}
+
+// ListForward queries host:list-forward and returns all active port forwards
+func (c *Client) ListForward(ctx context.Context) ([]Forward, error) {
+	payload, err := c.query(ctx, "host:list-forward")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ADB port forwards: %w", err)
+	}
+
+	return ParseForwards(payload), nil
+}
+
+// hostPrefix returns the host service prefix addressing a device: host-serial
+// for an explicit serial, host-usb (like adb -d) otherwise
+func hostPrefix(serial string) string {
+	if serial != "" {
+		return fmt.Sprintf("host-serial:%s:", serial)
+	}
+	return "host-usb:"
+}
+
+// query sends a host service request and reads its length-prefixed payload
+func (c *Client) query(ctx context.Context, service string) (string, error) {
+	conn, err := c.request(ctx, service)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return readMessage(conn)
+}
+
+// exec sends a host service request that answers with a status only. Forward
+// services acknowledge the request with OKAY and then report the outcome of
+// the operation with a second OKAY or FAIL before closing the connection.
+func (c *Client) exec(ctx context.Context, service string) error {
+	conn, err := c.request(ctx, service)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rest, err := io.ReadAll(conn)
+	if err != nil {
+		return fmt.Errorf("failed to read adb server response: %w", err)
+	}
+
+	if status, found := strings.CutPrefix(string(rest), "FAIL"); found {
+		return fmt.Errorf("adb server: %s", decodeMessage(status))
+	}
+
+	return nil
+}
+
+// request dials the adb server, sends the service request and consumes the
+// OKAY/FAIL status. On success the open connection is returned.
+func (c *Client) request(ctx context.Context, service string) (net.Conn, error) {
+	if c.debug {
+		fmt.Fprintf(os.Stderr, "ADB request (%s): %s\n", c.address, service)
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to adb server at %s (is it running?): %w", c.address, err)
+	}
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := fmt.Fprintf(conn, "%04x%s", len(service), service); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send adb request: %w", err)
+	}
+
+	if err := readStatus(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// readStatus reads a 4-byte OKAY/FAIL status, returning the FAIL message as error
+func readStatus(r io.Reader) error {
+	status := make([]byte, 4)
+	if _, err := io.ReadFull(r, status); err != nil {
+		return fmt.Errorf("failed to read adb server status: %w", err)
+	}
+
+	switch string(status) {
+	case "OKAY":
+		return nil
+	case "FAIL":
+		message, err := readMessage(r)
+		if err != nil {
+			return fmt.Errorf("adb server request failed: %w", err)
+		}
+		return fmt.Errorf("adb server: %s", message)
+	default:
+		return fmt.Errorf("unexpected adb server status: %q", status)
+	}
+}
+
+// readMessage reads a message prefixed with its length as 4 hex digits
+func readMessage(r io.Reader) (string, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", fmt.Errorf("failed to read adb message length: %w", err)
+	}
+
+	length, err := strconv.ParseUint(string(header), 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid adb message length %q: %w", header, err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return "", fmt.Errorf("failed to read adb message: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// decodeMessage decodes a length-prefixed message already held in memory,
+// returning the raw text if it is not properly prefixed
+func decodeMessage(s string) string {
+	if message, err := readMessage(strings.NewReader(s)); err == nil {
+		return message
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,266 @@
+package adb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kazuph/mcp-android-chrome/internal/platform"
+)
+
+// fakeServer is an adb server on a local port that answers every request
+// with the reply of its handler and records the requested services
+type fakeServer struct {
+	listener net.Listener
+	reply    func(service string) string
+
+	mu       sync.Mutex
+	services []string
+}
+
+// newFakeServer starts a fake adb server, stopped when the test ends
+func newFakeServer(t *testing.T, reply func(service string) string) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &fakeServer{listener: listener, reply: reply}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// serve reads one length-prefixed request and writes the reply
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	length, err := strconv.ParseUint(string(header), 16, 32)
+	if err != nil {
+		return
+	}
+	service := make([]byte, length)
+	if _, err := io.ReadFull(conn, service); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.services = append(s.services, string(service))
+	s.mu.Unlock()
+
+	_, _ = io.WriteString(conn, s.reply(string(service)))
+}
+
+// requests returns the services requested so far
+func (s *fakeServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.services...)
+}
+
+// client returns a client of the fake server
+func (s *fakeServer) client() *Client {
+	return NewClient(s.listener.Addr().String(), 2*time.Second, false)
+}
+
+// message prefixes text with its length as 4 hex digits
+func message(text string) string {
+	return fmt.Sprintf("%04x%s", len(text), text)
+}
+
+func TestClientDevices(t *testing.T) {
+	server := newFakeServer(t, func(service string) string {
+		return "OKAY" + message("R58M123ABC device usb:1-1 product:panther model:Pixel_7 device:panther transport_id:3\nemulator-5554 unauthorized transport_id:4\n")
+	})
+
+	devices, err := server.client().Devices(context.Background())
+	if err != nil {
+		t.Fatalf("Devices() error = %v", err)
+	}
+
+	want := []platform.ADBDevice{
+		{Serial: "R58M123ABC", State: "device", Model: "Pixel_7", Product: "panther", Device: "panther", TransportID: "3", USB: "1-1"},
+		{Serial: "emulator-5554", State: "unauthorized", TransportID: "4"},
+	}
+	if !reflect.DeepEqual(devices, want) {
+		t.Errorf("Devices() = %+v, want %+v", devices, want)
+	}
+	if got := server.requests(); !reflect.DeepEqual(got, []string{"host:devices-l"}) {
+		t.Errorf("requested services = %q, want host:devices-l", got)
+	}
+}
+
+func TestClientForward(t *testing.T) {
+	tests := []struct {
+		name        string
+		serial      string
+		reply       string
+		wantService string
+		wantErr     string
+	}{
+		{
+			name:        "usb device",
+			reply:       "OKAYOKAY",
+			wantService: "host-usb:forward:tcp:9222;localabstract:chrome_devtools_remote",
+		},
+		{
+			name:        "device by serial",
+			serial:      "R58M123ABC",
+			reply:       "OKAYOKAY",
+			wantService: "host-serial:R58M123ABC:forward:tcp:9222;localabstract:chrome_devtools_remote",
+		},
+		{
+			name:        "request refused",
+			reply:       "FAIL" + message("device 'R58M123ABC' not found"),
+			serial:      "R58M123ABC",
+			wantService: "host-serial:R58M123ABC:forward:tcp:9222;localabstract:chrome_devtools_remote",
+			wantErr:     "device 'R58M123ABC' not found",
+		},
+		{
+			name:        "forward failed",
+			reply:       "OKAYFAIL" + message("cannot bind listener: Address already in use"),
+			wantService: "host-usb:forward:tcp:9222;localabstract:chrome_devtools_remote",
+			wantErr:     "cannot bind listener: Address already in use",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t, func(service string) string { return tt.reply })
+
+			err := server.client().Forward(context.Background(), tt.serial, "tcp:9222", "localabstract:chrome_devtools_remote")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Forward() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if got := server.requests(); !reflect.DeepEqual(got, []string{tt.wantService}) {
+				t.Errorf("requested services = %q, want %q", got, tt.wantService)
+			}
+		})
+	}
+}
+
+func TestClientKillForward(t *testing.T) {
+	tests := []struct {
+		name        string
+		serial      string
+		reply       string
+		wantService string
+		wantErr     string
+	}{
+		{
+			name:        "removed",
+			serial:      "R58M123ABC",
+			reply:       "OKAYOKAY",
+			wantService: "host-serial:R58M123ABC:killforward:tcp:9222",
+		},
+		{
+			name:        "no such forward",
+			reply:       "OKAYFAIL" + message("listener 'tcp:9222' not found"),
+			wantService: "host-usb:killforward:tcp:9222",
+			wantErr:     "listener 'tcp:9222' not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t, func(service string) string { return tt.reply })
+
+			err := server.client().KillForward(context.Background(), tt.serial, "tcp:9222")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("KillForward() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("KillForward() error = %v", err)
+			}
+			if got := server.requests(); !reflect.DeepEqual(got, []string{tt.wantService}) {
+				t.Errorf("requested services = %q, want %q", got, tt.wantService)
+			}
+		})
+	}
+}
+
+func TestClientListForward(t *testing.T) {
+	server := newFakeServer(t, func(service string) string {
+		if service != "host:list-forward" {
+			return "FAIL" + message("unknown service "+service)
+		}
+		return "OKAY" + message("R58M123ABC tcp:9222 localabstract:chrome_devtools_remote\nemulator-5554 tcp:9223 localabstract:chrome_devtools_remote\n")
+	})
+
+	forwards, err := server.client().ListForward(context.Background())
+	if err != nil {
+		t.Fatalf("ListForward() error = %v", err)
+	}
+
+	want := []Forward{
+		{Serial: "R58M123ABC", Local: "tcp:9222", Remote: "localabstract:chrome_devtools_remote"},
+		{Serial: "emulator-5554", Local: "tcp:9223", Remote: "localabstract:chrome_devtools_remote"},
+	}
+	if !reflect.DeepEqual(forwards, want) {
+		t.Errorf("ListForward() = %+v, want %+v", forwards, want)
+	}
+}
+
+func TestClientServerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr string
+	}{
+		{name: "failure", reply: "FAIL" + message("protocol fault"), wantErr: "adb server: protocol fault"},
+		{name: "unknown status", reply: "WHAT", wantErr: "unexpected adb server status"},
+		{name: "bad length", reply: "OKAYzzzz", wantErr: "invalid adb message length"},
+		{name: "short message", reply: "OKAY0010abc", wantErr: "failed to read adb message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t, func(service string) string { return tt.reply })
+
+			_, err := server.client().ListForward(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ListForward() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientNoServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = NewClient(address, time.Second, false).Devices(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "is it running?") {
+		t.Fatalf("Devices() error = %v, want a connection error", err)
+	}
+}
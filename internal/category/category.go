@@ -0,0 +1,282 @@
+package category
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/match"
+)
+
+// Rule puts the tabs whose host matches one of Hosts, and whose path
+// matches one of Paths when Paths is set, in a category.
+//
+// A host without wildcards matches itself and its subdomains (github.com
+// matches gist.github.com too); a host with *, ? or [ is a glob matched
+// against the whole host name (amazon.* matches amazon.co.jp). A leading
+// www. of the tab's host is ignored. A path without wildcards matches
+// itself and the paths below it (/docs matches /docs/intro); a path with
+// wildcards is a glob matched against the whole path. Matching ignores case.
+type Rule struct {
+	Category string   `yaml:"category"`
+	Hosts    []string `yaml:"hosts"`
+	Paths    []string `yaml:"paths"`
+
+	hosts []hostPattern
+	paths []pathPattern
+}
+
+// RulesFile is the user-editable rules file
+type RulesFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+type hostPattern struct {
+	host string
+	glob *match.Pattern
+}
+
+type pathPattern struct {
+	prefix string
+	glob   *match.Pattern
+}
+
+// Other is the category of tabs no rule matches
+const Other = "Other"
+
+// Categorizer puts tabs in categories by the user's rules and, for tabs
+// none of them match, by the built-in heuristic. The first matching rule
+// wins.
+type Categorizer struct {
+	rules []Rule
+	// Number of rules from the user
+	userRules int
+}
+
+// DefaultPath returns the rules file used when none is configured:
+// categories.yaml in the user config directory
+func DefaultPath() string {
+	if configDir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(configDir, "mcp-android-chrome", "categories.yaml")
+	}
+	return ""
+}
+
+// New creates a categorizer from the user's rules, which are tried before
+// the built-in ones
+func New(rules []Rule) (*Categorizer, error) {
+	c := &Categorizer{userRules: len(rules)}
+	for i, rule := range rules {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		c.rules = append(c.rules, rule)
+	}
+	c.rules = append(c.rules, builtinRules...)
+	return c, nil
+}
+
+// Load creates a categorizer from the rules file at path. A missing file
+// leaves only the built-in rules.
+func Load(path string) (*Categorizer, error) {
+	if path == "" {
+		return New(nil)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read category rules: %w", err)
+	}
+
+	var file RulesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse category rules %s: %w", path, err)
+	}
+
+	c, err := New(file.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid category rules %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// UserRules returns the number of rules from the user
+func (c *Categorizer) UserRules() int {
+	return c.userRules
+}
+
+// Category returns the category of a tab: Browser for browser pages such
+// as chrome://newtab, the category of the first user rule it matches, Local
+// for pages on this device or network, the category of the first built-in
+// rule it matches, or Other
+func (c *Categorizer) Category(tab loader.Tab) string {
+	parsed, err := url.Parse(tab.URL)
+	if err != nil {
+		return Other
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+	case "":
+		return Other
+	default:
+		return Browser
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	path := strings.ToLower(parsed.Path)
+	for i := range c.rules {
+		// Local pages, e.g. a dev server, are matched before the built-in rules
+		if i == c.userRules && isLocal(host) {
+			return Local
+		}
+		if c.rules[i].match(host, path) {
+			return c.rules[i].Category
+		}
+	}
+	return Other
+}
+
+// Is reports whether a tab is in the category, ignoring case
+func (c *Categorizer) Is(tab loader.Tab, category string) bool {
+	return strings.EqualFold(c.Category(tab), category)
+}
+
+// Group puts the tabs in their categories. Larger categories come first and
+// Other comes last; tabs keep their order within a category.
+func (c *Categorizer) Group(tabs []loader.Tab) []format.TabGroup {
+	byCategory := make(map[string]*format.TabGroup)
+	var groups []*format.TabGroup
+	for _, tab := range tabs {
+		name := c.Category(tab)
+		group, ok := byCategory[name]
+		if !ok {
+			group = &format.TabGroup{Name: name}
+			byCategory[name] = group
+			groups = append(groups, group)
+		}
+		group.Tabs = append(group.Tabs, tab)
+		group.Count++
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Name == Other) != (groups[j].Name == Other) {
+			return groups[j].Name == Other
+		}
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Name < groups[j].Name
+	})
+
+	result := make([]format.TabGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	return result
+}
+
+// compile checks the rule and compiles its patterns
+func (r *Rule) compile() error {
+	if r.Category == "" {
+		return fmt.Errorf("category is required")
+	}
+	if len(r.Hosts) == 0 && len(r.Paths) == 0 {
+		return fmt.Errorf("%s: hosts or paths are required", r.Category)
+	}
+
+	r.hosts = nil
+	for _, host := range r.Hosts {
+		host = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "www.")
+		if host == "" {
+			continue
+		}
+		pattern := hostPattern{host: host}
+		if hasWildcard(host) {
+			glob, err := match.Compile(host, match.ModeGlob)
+			if err != nil {
+				return fmt.Errorf("%s: %w", r.Category, err)
+			}
+			pattern.glob = glob
+		}
+		r.hosts = append(r.hosts, pattern)
+	}
+
+	r.paths = nil
+	for _, path := range r.Paths {
+		path = strings.ToLower(strings.TrimSpace(path))
+		if path == "" {
+			continue
+		}
+		if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "*") {
+			path = "/" + path
+		}
+		pattern := pathPattern{prefix: strings.TrimRight(path, "/")}
+		if hasWildcard(path) {
+			glob, err := match.Compile(path, match.ModeGlob)
+			if err != nil {
+				return fmt.Errorf("%s: %w", r.Category, err)
+			}
+			pattern.glob = glob
+		}
+		r.paths = append(r.paths, pattern)
+	}
+	return nil
+}
+
+// match reports whether a host without www. and a lowercase path match the rule
+func (r *Rule) match(host, path string) bool {
+	if len(r.hosts) > 0 {
+		matched := false
+		for _, pattern := range r.hosts {
+			if pattern.match(host) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.paths) == 0 {
+		return true
+	}
+	for _, pattern := range r.paths {
+		if pattern.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p hostPattern) match(host string) bool {
+	if p.glob != nil {
+		return p.glob.Match(host)
+	}
+	return host == p.host || strings.HasSuffix(host, "."+p.host)
+}
+
+func (p pathPattern) match(path string) bool {
+	if p.glob != nil {
+		return p.glob.Match(path)
+	}
+	return p.prefix == "" || path == p.prefix || strings.HasPrefix(path, p.prefix+"/")
+}
+
+func hasWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
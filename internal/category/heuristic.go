@@ -0,0 +1,77 @@
+package category
+
+import (
+	"net"
+	"strings"
+)
+
+// Built-in categories
+const (
+	Docs      = "Docs"
+	Reference = "Reference"
+	Code      = "Code"
+	Search    = "Search"
+	Maps      = "Maps"
+	Mail      = "Mail"
+	Video     = "Video"
+	Social    = "Social"
+	Shopping  = "Shopping"
+	News      = "News"
+	Blogs     = "Blogs"
+	// Pages on this device or network
+	Local = "Local"
+	// Pages of the browser itself, e.g. chrome://newtab
+	Browser = "Browser"
+)
+
+// builtinRules are the heuristic for tabs the user's rules do not match:
+// well-known sites first, then hosts and paths that name what a page is
+// about, e.g. docs.example.com or example.com/cart
+var builtinRules = mustCompile([]Rule{
+	// Sites
+	{Category: Mail, Hosts: []string{"mail.google.com", "outlook.live.com", "outlook.office.com", "mail.yahoo.com", "mail.yahoo.co.jp", "mail.proton.me"}},
+	{Category: Maps, Hosts: []string{"maps.google.*", "maps.apple.com", "openstreetmap.org"}},
+	{Category: Maps, Hosts: []string{"google.*"}, Paths: []string{"/maps"}},
+	{Category: Search, Hosts: []string{"google.*", "bing.com", "yandex.*", "baidu.com"}, Paths: []string{"/search", "/webhp"}},
+	{Category: Search, Hosts: []string{"duckduckgo.com", "search.yahoo.com", "search.yahoo.co.jp", "search.brave.com", "kagi.com", "ecosia.org"}},
+	{Category: Docs, Hosts: []string{"docs.*", "developer.*", "developers.*", "*.readthedocs.io", "devdocs.io", "pkg.go.dev", "learn.microsoft.com", "cppreference.com"}},
+	{Category: Reference, Hosts: []string{"wikipedia.org", "wiktionary.org", "britannica.com", "weblio.jp"}},
+	{Category: Code, Hosts: []string{"github.com", "gitlab.com", "bitbucket.org", "codeberg.org", "sourcegraph.com", "stackoverflow.com", "*.stackexchange.com", "serverfault.com", "superuser.com", "qiita.com", "zenn.dev", "npmjs.com", "pypi.org", "crates.io"}},
+	{Category: Video, Hosts: []string{"youtube.com", "youtu.be", "vimeo.com", "twitch.tv", "dailymotion.com", "nicovideo.jp", "netflix.com", "primevideo.com", "tver.jp", "abema.tv"}},
+	{Category: Social, Hosts: []string{"twitter.com", "x.com", "facebook.com", "instagram.com", "threads.net", "bsky.app", "mastodon.social", "reddit.com", "linkedin.com", "tiktok.com", "pinterest.com", "tumblr.com", "news.ycombinator.com", "lobste.rs"}},
+	{Category: Shopping, Hosts: []string{"amazon.*", "ebay.*", "etsy.com", "aliexpress.com", "walmart.com", "target.com", "bestbuy.com", "rakuten.co.jp", "mercari.com", "shopping.yahoo.co.jp", "yodobashi.com", "kakaku.com"}},
+	{Category: News, Hosts: []string{"news.google.com", "news.yahoo.com", "news.yahoo.co.jp", "nytimes.com", "washingtonpost.com", "theguardian.com", "bbc.com", "bbc.co.uk", "cnn.com", "reuters.com", "apnews.com", "bloomberg.com", "ft.com", "economist.com", "theverge.com", "arstechnica.com", "techcrunch.com", "nikkei.com", "nhk.or.jp", "asahi.com", "itmedia.co.jp"}},
+	{Category: Blogs, Hosts: []string{"medium.com", "substack.com", "dev.to", "hashnode.dev", "note.com", "hatenablog.com", "hatenablog.jp", "blogspot.com", "wordpress.com", "ameblo.jp"}},
+
+	// Host names
+	{Category: Docs, Hosts: []string{"doc.*", "wiki.*", "manual.*", "help.*", "support.*"}},
+	{Category: Shopping, Hosts: []string{"shop.*", "store.*"}},
+	{Category: News, Hosts: []string{"news.*"}},
+	{Category: Blogs, Hosts: []string{"blog.*", "blogs.*"}},
+
+	// Paths
+	{Category: Docs, Paths: []string{"/docs", "/doc", "/documentation", "/manual", "/reference", "/guide", "/guides", "/api", "/wiki", "/help", "/tutorial", "/tutorials"}},
+	{Category: Shopping, Paths: []string{"/cart", "/checkout", "/basket", "/product", "/products", "/shop", "/store", "/dp", "*/dp/*"}},
+	{Category: News, Paths: []string{"/news"}},
+	{Category: Blogs, Paths: []string{"/blog", "/blogs", "/posts"}},
+	{Category: Search, Paths: []string{"/search"}},
+})
+
+// mustCompile compiles the built-in rules
+func mustCompile(rules []Rule) []Rule {
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			panic("category: invalid built-in rule: " + err.Error())
+		}
+	}
+	return rules
+}
+
+// isLocal reports whether a host is on this device or network
+func isLocal(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast())
+}
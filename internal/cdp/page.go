@@ -0,0 +1,166 @@
+package cdp
+
+import (
+	"context"
+	"fmt"
+)
+
+// NavigationEntry is a single entry of a tab's navigation history
+type NavigationEntry struct {
+	ID             int    `json:"id" yaml:"id"`
+	URL            string `json:"url" yaml:"url"`
+	UserTypedURL   string `json:"userTypedURL,omitempty" yaml:"userTypedURL,omitempty"`
+	Title          string `json:"title" yaml:"title"`
+	TransitionType string `json:"transitionType,omitempty" yaml:"transitionType,omitempty"`
+}
+
+// NavigationHistory is a tab's navigation history and its current position
+type NavigationHistory struct {
+	CurrentIndex int               `json:"currentIndex" yaml:"currentIndex"`
+	Entries      []NavigationEntry `json:"entries" yaml:"entries"`
+}
+
+// Navigate loads url in the session's page via Page.navigate
+func (s *Session) Navigate(ctx context.Context, url string) error {
+	var result struct {
+		FrameID   string `json:"frameId"`
+		ErrorText string `json:"errorText"`
+	}
+
+	if err := s.Call(ctx, "Page.navigate", map[string]interface{}{"url": url}, &result); err != nil {
+		return err
+	}
+	if result.ErrorText != "" {
+		return fmt.Errorf("navigation to %s failed: %s", url, result.ErrorText)
+	}
+
+	return nil
+}
+
+// Reload reloads the session's page via Page.reload, optionally bypassing the cache
+func (s *Session) Reload(ctx context.Context, ignoreCache bool) error {
+	return s.Call(ctx, "Page.reload", map[string]interface{}{"ignoreCache": ignoreCache}, nil)
+}
+
+// NavigationHistory returns the page's history via Page.getNavigationHistory
+func (s *Session) NavigationHistory(ctx context.Context) (*NavigationHistory, error) {
+	var history NavigationHistory
+	if err := s.Call(ctx, "Page.getNavigationHistory", nil, &history); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// NavigateToHistoryEntry moves the page to the history entry with the given ID
+func (s *Session) NavigateToHistoryEntry(ctx context.Context, entryID int) error {
+	return s.Call(ctx, "Page.navigateToHistoryEntry", map[string]interface{}{"entryId": entryID}, nil)
+}
+
+// GoHistory moves the page delta entries through its history (-1 for back,
+// 1 for forward) and returns the entry it moved to
+func (s *Session) GoHistory(ctx context.Context, delta int) (*NavigationEntry, error) {
+	history, err := s.NavigationHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	index := history.CurrentIndex + delta
+	if index < 0 || index >= len(history.Entries) {
+		return nil, fmt.Errorf("no history entry %d steps from the current one (%d/%d)", delta, history.CurrentIndex+1, len(history.Entries))
+	}
+
+	entry := history.Entries[index]
+	if err := s.NavigateToHistoryEntry(ctx, entry.ID); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Viewport is a rectangle of the page in CSS pixels, used to clip screenshots
+type Viewport struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Scale  float64 `json:"scale"`
+}
+
+// ScreenshotOptions controls Page.captureScreenshot
+type ScreenshotOptions struct {
+	Format   string    // png (default) or jpeg
+	Quality  int       // jpeg quality 0-100, ignored for png
+	FullPage bool      // capture the whole scrollable page instead of the viewport
+	Clip     *Viewport // capture only this region, overrides FullPage
+}
+
+// MimeType returns the MIME type of screenshots taken with these options
+func (o ScreenshotOptions) MimeType() string {
+	if o.Format == "jpeg" || o.Format == "jpg" {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// CaptureScreenshot captures the page via Page.captureScreenshot and returns
+// the image as base64-encoded data
+func (s *Session) CaptureScreenshot(ctx context.Context, opts ScreenshotOptions) (string, error) {
+	switch opts.Format {
+	case "":
+		opts.Format = "png"
+	case "png", "jpeg":
+	case "jpg":
+		opts.Format = "jpeg"
+	default:
+		return "", fmt.Errorf("unsupported screenshot format: %s (use png or jpeg)", opts.Format)
+	}
+
+	params := map[string]interface{}{
+		"format": opts.Format,
+	}
+	if opts.Format == "jpeg" && opts.Quality > 0 {
+		params["quality"] = opts.Quality
+	}
+
+	clip := opts.Clip
+	if clip == nil && opts.FullPage {
+		var metrics struct {
+			CSSContentSize struct {
+				Width  float64 `json:"width"`
+				Height float64 `json:"height"`
+			} `json:"cssContentSize"`
+			ContentSize struct {
+				Width  float64 `json:"width"`
+				Height float64 `json:"height"`
+			} `json:"contentSize"`
+		}
+		if err := s.Call(ctx, "Page.getLayoutMetrics", nil, &metrics); err != nil {
+			return "", err
+		}
+
+		// cssContentSize is only reported by newer Chrome versions
+		size := metrics.CSSContentSize
+		if size.Width == 0 || size.Height == 0 {
+			size = metrics.ContentSize
+		}
+		clip = &Viewport{Width: size.Width, Height: size.Height}
+		params["captureBeyondViewport"] = true
+	}
+	if clip != nil {
+		region := *clip
+		if region.Scale == 0 {
+			region.Scale = 1
+		}
+		params["clip"] = region
+	}
+
+	var result struct {
+		Data string `json:"data"`
+	}
+	if err := s.Call(ctx, "Page.captureScreenshot", params, &result); err != nil {
+		return "", err
+	}
+
+	return result.Data, nil
+}
@@ -0,0 +1,70 @@
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Evaluate runs a JavaScript expression in the page via Runtime.evaluate and
+// decodes its value into result. Promises are awaited.
+func (s *Session) Evaluate(ctx context.Context, expression string, result interface{}) error {
+	params := map[string]interface{}{
+		"expression":    expression,
+		"returnByValue": true,
+		"awaitPromise":  true,
+	}
+
+	var response struct {
+		Result struct {
+			Type  string          `json:"type"`
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	if err := s.Call(ctx, "Runtime.evaluate", params, &response); err != nil {
+		return err
+	}
+
+	if details := response.ExceptionDetails; details != nil {
+		message := details.Exception.Description
+		if message == "" {
+			message = details.Text
+		}
+		return fmt.Errorf("script raised an exception: %s", message)
+	}
+
+	if result != nil && len(response.Result.Value) > 0 {
+		if err := json.Unmarshal(response.Result.Value, result); err != nil {
+			return fmt.Errorf("failed to decode script result: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// OuterHTML returns the serialized HTML of the whole document via DOM.getOuterHTML
+func (s *Session) OuterHTML(ctx context.Context) (string, error) {
+	var document struct {
+		Root struct {
+			NodeID int `json:"nodeId"`
+		} `json:"root"`
+	}
+	if err := s.Call(ctx, "DOM.getDocument", map[string]interface{}{"depth": 0}, &document); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		OuterHTML string `json:"outerHTML"`
+	}
+	if err := s.Call(ctx, "DOM.getOuterHTML", map[string]interface{}{"nodeId": document.Root.NodeID}, &result); err != nil {
+		return "", err
+	}
+
+	return result.OuterHTML, nil
+}
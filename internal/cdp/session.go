@@ -0,0 +1,312 @@
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultTimeout bounds a single Call when the context has no earlier deadline
+const DefaultTimeout = 10 * time.Second
+
+// ErrClosed is returned by calls on a session whose connection has gone away
+var ErrClosed = errors.New("cdp session closed")
+
+// Error is a protocol-level error returned by the browser for a command
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("cdp error %d: %s (%s)", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("cdp error %d: %s", e.Code, e.Message)
+}
+
+// Event is a protocol event pushed by the browser
+type Event struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// request is an outgoing command message
+type request struct {
+	ID     int64       `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// message is any incoming message: a response when ID is set, an event otherwise
+type message struct {
+	ID     int64           `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// subscriber receives events for one method ("" for all events)
+type subscriber struct {
+	method string
+	events chan Event
+}
+
+// Session is a Chrome DevTools Protocol connection to a single target.
+// It is safe for concurrent use: responses are matched to callers by id,
+// and events are fanned out to subscribers.
+type Session struct {
+	conn    *websocket.Conn
+	timeout time.Duration
+	debug   bool
+
+	writeMutex sync.Mutex
+
+	mutex       sync.Mutex
+	nextID      int64
+	pending     map[int64]chan *message
+	subscribers map[*subscriber]struct{}
+	err         error
+
+	done chan struct{}
+}
+
+// Dial connects to a webSocketDebuggerUrl and starts reading messages.
+// A zero timeout uses DefaultTimeout for calls without a context deadline.
+func Dial(ctx context.Context, wsURL string, timeout time.Duration, debug bool) (*Session, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	if debug {
+		fmt.Fprintf(os.Stderr, "Connecting to CDP target: %s\n", wsURL)
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = timeout
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+
+	s := &Session{
+		conn:        conn,
+		timeout:     timeout,
+		debug:       debug,
+		pending:     make(map[int64]chan *message),
+		subscribers: make(map[*subscriber]struct{}),
+		done:        make(chan struct{}),
+	}
+	go s.readLoop()
+
+	return s, nil
+}
+
+// PageURL builds the webSocketDebuggerUrl of a page target from the DevTools
+// HTTP endpoint (e.g. http://localhost:9222) and the target ID
+func PageURL(baseURL, targetID string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	return fmt.Sprintf("ws://%s/devtools/page/%s", u.Host, targetID), nil
+}
+
+// Call sends a command and waits for its response. params may be nil, and
+// result may be nil when the caller does not need the response body.
+func (s *Session) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	s.mutex.Lock()
+	if s.err != nil {
+		err := s.err
+		s.mutex.Unlock()
+		return err
+	}
+	s.nextID++
+	id := s.nextID
+	reply := make(chan *message, 1)
+	s.pending[id] = reply
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.pending, id)
+		s.mutex.Unlock()
+	}()
+
+	if s.debug {
+		fmt.Fprintf(os.Stderr, "CDP -> %d %s\n", id, method)
+	}
+
+	if err := s.write(request{ID: id, Method: method, Params: params}); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case msg := <-reply:
+		if msg.Error != nil {
+			return fmt.Errorf("%s failed: %w", method, msg.Error)
+		}
+		if result != nil && len(msg.Result) > 0 {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				return fmt.Errorf("failed to decode %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-s.done:
+		return fmt.Errorf("%s failed: %w", method, s.Err())
+	case <-ctx.Done():
+		return fmt.Errorf("%s failed: %w", method, ctx.Err())
+	}
+}
+
+// Subscribe returns a channel receiving events with the given method, or all
+// events when method is empty, and a function to cancel the subscription.
+// Events are dropped for subscribers that do not keep up with the buffer.
+func (s *Session) Subscribe(method string, buffer int) (<-chan Event, func()) {
+	if buffer <= 0 {
+		buffer = 16
+	}
+
+	sub := &subscriber{
+		method: method,
+		events: make(chan Event, buffer),
+	}
+
+	s.mutex.Lock()
+	if s.err != nil {
+		s.mutex.Unlock()
+		close(sub.events)
+		return sub.events, func() {}
+	}
+	s.subscribers[sub] = struct{}{}
+	s.mutex.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			if _, ok := s.subscribers[sub]; ok {
+				delete(s.subscribers, sub)
+				close(sub.events)
+			}
+		})
+	}
+
+	return sub.events, unsubscribe
+}
+
+// Done returns a channel closed when the session's connection is gone
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the reason the session ended, or nil while it is open
+func (s *Session) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// Close closes the connection, failing pending calls and ending subscriptions
+func (s *Session) Close() error {
+	s.writeMutex.Lock()
+	_ = s.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	s.writeMutex.Unlock()
+
+	s.shutdown(ErrClosed)
+	return s.conn.Close()
+}
+
+// write serialises writes, which gorilla/websocket does not allow concurrently
+func (s *Session) write(v interface{}) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	return s.conn.WriteJSON(v)
+}
+
+// readLoop dispatches incoming messages until the connection fails
+func (s *Session) readLoop() {
+	for {
+		var msg message
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			s.shutdown(fmt.Errorf("%w: %v", ErrClosed, err))
+			return
+		}
+
+		if msg.ID != 0 {
+			s.mutex.Lock()
+			reply, ok := s.pending[msg.ID]
+			s.mutex.Unlock()
+			if ok {
+				reply <- &msg
+			}
+			continue
+		}
+
+		if msg.Method != "" {
+			s.dispatch(Event{Method: msg.Method, Params: msg.Params})
+		}
+	}
+}
+
+// dispatch delivers an event to every matching subscriber without blocking
+func (s *Session) dispatch(event Event) {
+	if s.debug {
+		fmt.Fprintf(os.Stderr, "CDP <- event %s\n", event.Method)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for sub := range s.subscribers {
+		if sub.method != "" && sub.method != event.Method {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			if s.debug {
+				fmt.Fprintf(os.Stderr, "CDP subscriber for %s is full, dropping event\n", event.Method)
+			}
+		}
+	}
+}
+
+// shutdown records the terminal error once and releases waiters and subscribers
+func (s *Session) shutdown(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	s.err = err
+	close(s.done)
+
+	for sub := range s.subscribers {
+		close(sub.events)
+	}
+	s.subscribers = make(map[*subscriber]struct{})
+}
@@ -0,0 +1,599 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuph/mcp-android-chrome/internal/category"
+	"github.com/kazuph/mcp-android-chrome/internal/driver"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/jsonstore"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+	"github.com/kazuph/mcp-android-chrome/internal/trash"
+)
+
+// Config holds the settings of the config file. Environment variables
+// override the file, and command line flags override both.
+type Config struct {
+	Defaults   Defaults                   `yaml:"defaults"`
+	Profiles   map[string]ProfileSettings `yaml:"profiles"`
+	Cache      Cache                      `yaml:"cache"`
+	Safety     Safety                     `yaml:"safety"`
+	Server     Server                     `yaml:"server"`
+	Snapshots  Snapshots                  `yaml:"snapshots"`
+	Trash      Trash                      `yaml:"trash"`
+	Categories Categories                 `yaml:"categories"`
+
+	// Path of the loaded file, empty when none was found
+	Path string `yaml:"-"`
+}
+
+// Defaults holds the settings used when neither a profile nor a flag sets them
+type Defaults struct {
+	Profile    string `yaml:"profile"`
+	Format     string `yaml:"format"`
+	Port       int    `yaml:"port"`
+	Socket     string `yaml:"socket"`
+	Timeout    int    `yaml:"timeout"`
+	Wait       int    `yaml:"wait"`
+	ADBBackend string `yaml:"adbBackend"`
+}
+
+// ProfileSettings holds the connection settings of one device in the config
+// file. Unset fields are nil or empty and fall back to the defaults, so that
+// a profile can set a port, timeout or wait of zero.
+type ProfileSettings struct {
+	Platform   string `yaml:"platform"`
+	Serial     string `yaml:"serial"`
+	Port       *int   `yaml:"port"`
+	Socket     string `yaml:"socket"`
+	Browser    string `yaml:"browser"`
+	Timeout    *int   `yaml:"timeout"`
+	Wait       *int   `yaml:"wait"`
+	ADBBackend string `yaml:"adbBackend"`
+}
+
+// Profile holds the resolved connection settings of one device
+type Profile struct {
+	Name       string `yaml:"-"`
+	Platform   string `yaml:"platform"`
+	Serial     string `yaml:"serial"`
+	Port       int    `yaml:"port"`
+	Socket     string `yaml:"socket"`
+	Browser    string `yaml:"browser"`
+	Timeout    int    `yaml:"timeout"`
+	Wait       int    `yaml:"wait"`
+	ADBBackend string `yaml:"adbBackend"`
+}
+
+// Cache holds the settings of the MCP server's tab cache
+type Cache struct {
+	PageSize        int    `yaml:"pageSize"`
+	RefreshInterval string `yaml:"refreshInterval"`
+	// Local port forwarded by background refreshes, 0 for a free port
+	RefreshPort int `yaml:"refreshPort"`
+}
+
+// Safety holds the policies of the tab closing tools
+type Safety struct {
+	// Ask for confirm=true before closing tabs (default: true)
+	RequireConfirm *bool `yaml:"requireConfirm"`
+	// Refuse to close more tabs than this in one call, 0 for no limit
+	MaxBulkClose int `yaml:"maxBulkClose"`
+	// Tabs whose URL contains one of these are never closed
+	ProtectedURLs []string `yaml:"protectedUrls"`
+}
+
+// Server holds the settings of the MCP server's transport
+type Server struct {
+	// stdio, http (streamable HTTP) or sse
+	Transport string `yaml:"transport"`
+	// Address the http and sse transports listen on
+	Address string `yaml:"address"`
+	// Bearer token clients of the http and sse transports must send
+	Token string `yaml:"token"`
+}
+
+// Snapshots holds the retention of the tab snapshot history
+type Snapshots struct {
+	// Directory of the snapshots (default: snapshots in the user config directory)
+	Dir string `yaml:"dir"`
+	// Keep at most this many snapshots, 0 for no limit
+	MaxCount int `yaml:"maxCount"`
+	// Delete snapshots older than this many days, 0 for no limit
+	MaxAgeDays int `yaml:"maxAgeDays"`
+	// Stop saving snapshots
+	Disabled bool `yaml:"disabled"`
+}
+
+// Trash holds the retention of the closed tabs kept for undo_close
+type Trash struct {
+	// Directory of the closed tabs (default: trash in the user config directory)
+	Dir string `yaml:"dir"`
+	// Keep at most this many closed tabs, 0 for no limit
+	MaxCount int `yaml:"maxCount"`
+	// Forget closed tabs after this many days, 0 for no limit
+	MaxAgeDays int `yaml:"maxAgeDays"`
+}
+
+// Categories holds the settings of categorize_tabs and the category filters
+type Categories struct {
+	// Rules file mapping host patterns to categories (default:
+	// categories.yaml in the user config directory)
+	RulesFile string `yaml:"rulesFile"`
+}
+
+// Default returns the built-in settings
+func Default() *Config {
+	return &Config{
+		Defaults: Defaults{
+			Format:  string(format.FormatJSON),
+			Port:    9222,
+			Socket:  "chrome_devtools_remote",
+			Timeout: 10,
+			Wait:    2,
+		},
+		Profiles: make(map[string]ProfileSettings),
+		Cache: Cache{
+			PageSize: format.DefaultPageSize,
+		},
+		Server: Server{
+			Transport: "stdio",
+			Address:   "127.0.0.1:8765",
+		},
+		Snapshots: Snapshots{
+			MaxCount:   snapshot.DefaultMaxCount,
+			MaxAgeDays: int(snapshot.DefaultMaxAge / (24 * time.Hour)),
+		},
+		Trash: Trash{
+			MaxCount:   trash.DefaultMaxCount,
+			MaxAgeDays: int(trash.DefaultMaxAge / (24 * time.Hour)),
+		},
+	}
+}
+
+// DefaultPath returns the config file used when none is given:
+// $MCP_ANDROID_CHROME_CONFIG, or config.yaml in the user config directory
+func DefaultPath() string {
+	if path := os.Getenv("MCP_ANDROID_CHROME_CONFIG"); path != "" {
+		return path
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "mcp-android-chrome", "config.yaml")
+}
+
+// Load reads the config file at path over the built-in settings and applies
+// the environment. An empty path loads DefaultPath, which may be missing.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			decoder := yaml.NewDecoder(bytes.NewReader(data))
+			decoder.KnownFields(true)
+			if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+			}
+			cfg.Path = path
+		case explicit || !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	cfg.applyEnv()
+
+	if err := cfg.Validate(); err != nil {
+		if cfg.Path != "" {
+			return nil, fmt.Errorf("invalid configuration (%s and environment): %w", cfg.Path, err)
+		}
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides the file with the environment: TAB_PROFILE, TAB_FORMAT,
+// the connection settings TAB_PORT, TAB_SOCKET, TAB_TIMEOUT, TAB_WAIT and
+// ADB_BACKEND (which win over the defaults and every profile), TAB_PAGE_SIZE
+// (or TAB_CACHE_SIZE, its former name), TAB_CACHE_REFRESH_INTERVAL,
+// TAB_CACHE_REFRESH_PORT, MCP_ANDROID_CHROME_TRANSPORT,
+// MCP_ANDROID_CHROME_ADDRESS, MCP_ANDROID_CHROME_TOKEN, TAB_REQUIRE_CONFIRM,
+// TAB_MAX_BULK_CLOSE, TAB_PROTECTED_URLS (comma-separated),
+// TAB_SNAPSHOT_DIR, TAB_SNAPSHOT_MAX_COUNT, TAB_SNAPSHOT_MAX_AGE_DAYS,
+// TAB_SNAPSHOT_DISABLE, TAB_TRASH_DIR, TAB_TRASH_MAX_COUNT,
+// TAB_TRASH_MAX_AGE_DAYS and TAB_CATEGORIES_FILE
+func (c *Config) applyEnv() {
+	if profile := os.Getenv("TAB_PROFILE"); profile != "" {
+		c.Defaults.Profile = profile
+	}
+	if formatStr := os.Getenv("TAB_FORMAT"); formatStr != "" {
+		c.Defaults.Format = formatStr
+	}
+
+	var connection ProfileSettings
+	if port, err := strconv.Atoi(os.Getenv("TAB_PORT")); err == nil && port > 0 {
+		connection.Port = &port
+	}
+	connection.Socket = os.Getenv("TAB_SOCKET")
+	if timeout, err := strconv.Atoi(os.Getenv("TAB_TIMEOUT")); err == nil && timeout >= 0 {
+		connection.Timeout = &timeout
+	}
+	if wait, err := strconv.Atoi(os.Getenv("TAB_WAIT")); err == nil && wait >= 0 {
+		connection.Wait = &wait
+	}
+	connection.ADBBackend = os.Getenv("ADB_BACKEND")
+	c.OverrideAll(connection)
+
+	for _, name := range []string{"TAB_CACHE_SIZE", "TAB_PAGE_SIZE"} {
+		if size, err := strconv.Atoi(os.Getenv(name)); err == nil && size > 0 {
+			c.Cache.PageSize = size
+		}
+	}
+	if interval := os.Getenv("TAB_CACHE_REFRESH_INTERVAL"); interval != "" {
+		c.Cache.RefreshInterval = interval
+	}
+	if port, err := strconv.Atoi(os.Getenv("TAB_CACHE_REFRESH_PORT")); err == nil && port > 0 {
+		c.Cache.RefreshPort = port
+	}
+	if transport := os.Getenv("MCP_ANDROID_CHROME_TRANSPORT"); transport != "" {
+		c.Server.Transport = transport
+	}
+	if address := os.Getenv("MCP_ANDROID_CHROME_ADDRESS"); address != "" {
+		c.Server.Address = address
+	}
+	if token := os.Getenv("MCP_ANDROID_CHROME_TOKEN"); token != "" {
+		c.Server.Token = token
+	}
+	if confirm, err := strconv.ParseBool(os.Getenv("TAB_REQUIRE_CONFIRM")); err == nil {
+		c.Safety.RequireConfirm = &confirm
+	}
+	if limit, err := strconv.Atoi(os.Getenv("TAB_MAX_BULK_CLOSE")); err == nil && limit >= 0 {
+		c.Safety.MaxBulkClose = limit
+	}
+	if urls := os.Getenv("TAB_PROTECTED_URLS"); urls != "" {
+		c.Safety.ProtectedURLs = nil
+		for _, url := range strings.Split(urls, ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				c.Safety.ProtectedURLs = append(c.Safety.ProtectedURLs, url)
+			}
+		}
+	}
+	if dir := os.Getenv("TAB_SNAPSHOT_DIR"); dir != "" {
+		c.Snapshots.Dir = dir
+	}
+	if count, err := strconv.Atoi(os.Getenv("TAB_SNAPSHOT_MAX_COUNT")); err == nil && count >= 0 {
+		c.Snapshots.MaxCount = count
+	}
+	if days, err := strconv.Atoi(os.Getenv("TAB_SNAPSHOT_MAX_AGE_DAYS")); err == nil && days >= 0 {
+		c.Snapshots.MaxAgeDays = days
+	}
+	if disabled, err := strconv.ParseBool(os.Getenv("TAB_SNAPSHOT_DISABLE")); err == nil {
+		c.Snapshots.Disabled = disabled
+	}
+	if dir := os.Getenv("TAB_TRASH_DIR"); dir != "" {
+		c.Trash.Dir = dir
+	}
+	if count, err := strconv.Atoi(os.Getenv("TAB_TRASH_MAX_COUNT")); err == nil && count >= 0 {
+		c.Trash.MaxCount = count
+	}
+	if days, err := strconv.Atoi(os.Getenv("TAB_TRASH_MAX_AGE_DAYS")); err == nil && days >= 0 {
+		c.Trash.MaxAgeDays = days
+	}
+	if path := os.Getenv("TAB_CATEGORIES_FILE"); path != "" {
+		c.Categories.RulesFile = path
+	}
+}
+
+// OverrideDefaults sets the connection settings given in settings (non-nil
+// pointers and non-empty strings) on the defaults
+func (c *Config) OverrideDefaults(settings ProfileSettings) {
+	if settings.Port != nil {
+		c.Defaults.Port = *settings.Port
+	}
+	if settings.Socket != "" {
+		c.Defaults.Socket = settings.Socket
+	}
+	if settings.Timeout != nil {
+		c.Defaults.Timeout = *settings.Timeout
+	}
+	if settings.Wait != nil {
+		c.Defaults.Wait = *settings.Wait
+	}
+	if settings.ADBBackend != "" {
+		c.Defaults.ADBBackend = settings.ADBBackend
+	}
+}
+
+// OverrideProfile sets the connection settings given in settings on the
+// named profile
+func (c *Config) OverrideProfile(name string, settings ProfileSettings) {
+	profile := c.Profiles[name]
+	profile.override(settings)
+	c.Profiles[name] = profile
+}
+
+// OverrideAll sets the connection settings given in settings on the
+// defaults and every profile, so that they win over the whole config file
+func (c *Config) OverrideAll(settings ProfileSettings) {
+	c.OverrideDefaults(settings)
+	for name := range c.Profiles {
+		c.OverrideProfile(name, settings)
+	}
+}
+
+// override sets the connection settings given in settings on the profile
+func (p *ProfileSettings) override(settings ProfileSettings) {
+	if settings.Port != nil {
+		port := *settings.Port
+		p.Port = &port
+	}
+	if settings.Socket != "" {
+		p.Socket = settings.Socket
+	}
+	if settings.Timeout != nil {
+		timeout := *settings.Timeout
+		p.Timeout = &timeout
+	}
+	if settings.Wait != nil {
+		wait := *settings.Wait
+		p.Wait = &wait
+	}
+	if settings.ADBBackend != "" {
+		p.ADBBackend = settings.ADBBackend
+	}
+}
+
+// Validate checks the settings for values the tools cannot use
+func (c *Config) Validate() error {
+	if _, err := format.ParseFormat(c.Defaults.Format); err != nil {
+		return fmt.Errorf("defaults.format: %w", err)
+	}
+	if c.Defaults.Profile != "" {
+		if _, ok := c.Profiles[c.Defaults.Profile]; !ok {
+			return fmt.Errorf("unknown profile %q (defined: %s)", c.Defaults.Profile, strings.Join(c.ProfileNames(), ", "))
+		}
+	}
+	for name, profile := range c.Profiles {
+		switch profile.Platform {
+		case "", "android", "ios":
+		default:
+			return fmt.Errorf("profiles.%s.platform: unsupported platform %q (use android or ios)", name, profile.Platform)
+		}
+	}
+	if c.Cache.PageSize <= 0 {
+		return fmt.Errorf("cache.pageSize must be positive")
+	}
+	if c.Cache.RefreshPort < 0 || c.Cache.RefreshPort > 65535 {
+		return fmt.Errorf("cache.refreshPort must be between 0 and 65535")
+	}
+	if c.Safety.MaxBulkClose < 0 {
+		return fmt.Errorf("safety.maxBulkClose must not be negative")
+	}
+	switch c.Server.Transport {
+	case "stdio", "http", "sse":
+	default:
+		return fmt.Errorf("server.transport: unsupported transport %q (use stdio, http or sse)", c.Server.Transport)
+	}
+	if c.Server.Transport != "stdio" && c.Server.Address == "" {
+		return fmt.Errorf("server.address must be set for the %s transport", c.Server.Transport)
+	}
+	if c.Snapshots.MaxCount < 0 || c.Snapshots.MaxAgeDays < 0 {
+		return fmt.Errorf("snapshots.maxCount and snapshots.maxAgeDays must not be negative")
+	}
+	if c.Trash.MaxCount < 0 || c.Trash.MaxAgeDays < 0 {
+		return fmt.Errorf("trash.maxCount and trash.maxAgeDays must not be negative")
+	}
+	return nil
+}
+
+// SnapshotOptions returns the options of the store of tab snapshots
+func (c *Config) SnapshotOptions() snapshot.Options {
+	dir := c.Snapshots.Dir
+	if dir == "" {
+		dir = snapshot.DefaultDir()
+	}
+	return snapshot.Options{
+		Options: jsonstore.Options{
+			Dir:      dir,
+			MaxCount: c.Snapshots.MaxCount,
+			MaxAge:   time.Duration(c.Snapshots.MaxAgeDays) * 24 * time.Hour,
+		},
+		Disabled: c.Snapshots.Disabled,
+	}
+}
+
+// TrashOptions returns the options of the store of closed tabs
+func (c *Config) TrashOptions() trash.Options {
+	dir := c.Trash.Dir
+	if dir == "" {
+		dir = trash.DefaultDir()
+	}
+	return trash.Options{
+		Dir:      dir,
+		MaxCount: c.Trash.MaxCount,
+		MaxAge:   time.Duration(c.Trash.MaxAgeDays) * 24 * time.Hour,
+	}
+}
+
+// CategoryRulesFile returns the path of the category rules file
+func (c *Config) CategoryRulesFile() string {
+	if c.Categories.RulesFile != "" {
+		return c.Categories.RulesFile
+	}
+	return category.DefaultPath()
+}
+
+// ProfileNames returns the names of the defined profiles in order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OutputFormat returns the given format, or the default format when it is empty
+func (c *Config) OutputFormat(formatStr string) (format.Format, error) {
+	if formatStr == "" {
+		formatStr = c.Defaults.Format
+	}
+	return format.ParseFormat(formatStr)
+}
+
+// Resolve returns the connection settings for a device on the platform. The
+// device is a profile name or an ADB serial (the profile with that serial, if
+// any); when it is empty the default profile is used if it is for the
+// platform. Unset fields are filled from the defaults.
+func (c *Config) Resolve(device, platform string) (Profile, error) {
+	var (
+		name     string
+		settings ProfileSettings
+	)
+	switch {
+	case device == "":
+		if defaultProfile, ok := c.Profiles[c.Defaults.Profile]; ok && profilePlatform(defaultProfile) == platform {
+			name, settings = c.Defaults.Profile, defaultProfile
+		}
+
+	default:
+		if named, ok := c.Profiles[device]; ok {
+			if profilePlatform(named) != platform {
+				return Profile{}, fmt.Errorf("profile %s is for %s, not %s", device, profilePlatform(named), platform)
+			}
+			name, settings = device, named
+			break
+		}
+		for _, profileName := range c.ProfileNames() {
+			if named := c.Profiles[profileName]; named.Serial == device && profilePlatform(named) == platform {
+				name, settings = profileName, named
+				break
+			}
+		}
+		if name == "" {
+			if platform != "android" {
+				return Profile{}, fmt.Errorf("unknown %s profile: %s", platform, device)
+			}
+			settings.Serial = device
+		}
+	}
+
+	profile := Profile{
+		Name:       name,
+		Platform:   platform,
+		Serial:     settings.Serial,
+		Port:       c.Defaults.Port,
+		Socket:     settings.Socket,
+		Browser:    settings.Browser,
+		Timeout:    c.Defaults.Timeout,
+		Wait:       c.Defaults.Wait,
+		ADBBackend: settings.ADBBackend,
+	}
+	if settings.Port != nil {
+		profile.Port = *settings.Port
+	}
+	if profile.Socket == "" {
+		profile.Socket = c.Defaults.Socket
+	}
+	if settings.Timeout != nil {
+		profile.Timeout = *settings.Timeout
+	}
+	if settings.Wait != nil {
+		profile.Wait = *settings.Wait
+	}
+	if profile.ADBBackend == "" {
+		profile.ADBBackend = c.Defaults.ADBBackend
+	}
+	return profile, nil
+}
+
+// profilePlatform returns the platform of a profile, android unless set
+func profilePlatform(profile ProfileSettings) string {
+	if profile.Platform == "" {
+		return "android"
+	}
+	return profile.Platform
+}
+
+// TimeoutDuration returns the network timeout of the profile
+func (p Profile) TimeoutDuration() time.Duration {
+	return time.Duration(p.Timeout) * time.Second
+}
+
+// OperationTimeout returns the time an operation on a device may take:
+// starting the driver plus one network timeout for the operation itself
+func (p Profile) OperationTimeout() time.Duration {
+	return p.BatchTimeout(0) + p.TimeoutDuration()
+}
+
+// perTabTimeout is the time batch operations allow for each tab
+const perTabTimeout = 2 * time.Second
+
+// BatchTimeout returns the time a batch operation on the given number of
+// tabs may take: starting the driver plus perTabTimeout for every tab
+func (p Profile) BatchTimeout(tabs int) time.Duration {
+	return p.TimeoutDuration() + time.Duration(p.Wait)*time.Second + 10*time.Second + time.Duration(tabs)*perTabTimeout
+}
+
+// AndroidConfig returns the Android driver configuration of the profile
+func (p Profile) AndroidConfig(debug bool) driver.AndroidConfig {
+	return driver.AndroidConfig{
+		DriverConfig: driver.DriverConfig{
+			Port:    p.Port,
+			Timeout: p.TimeoutDuration(),
+			Debug:   debug,
+		},
+		Serial:     p.Serial,
+		ADBBackend: p.ADBBackend,
+		Socket:     p.Socket,
+		Browser:    p.Browser,
+		Wait:       time.Duration(p.Wait) * time.Second,
+	}
+}
+
+// IOSConfig returns the iOS driver configuration of the profile
+func (p Profile) IOSConfig(debug bool) driver.IOSConfig {
+	return driver.IOSConfig{
+		DriverConfig: driver.DriverConfig{
+			Port:    p.Port,
+			Timeout: p.TimeoutDuration(),
+			Debug:   debug,
+		},
+		Wait: time.Duration(p.Wait) * time.Second,
+	}
+}
+
+// ConfirmRequired reports whether closing tabs needs confirm=true
+func (s Safety) ConfirmRequired() bool {
+	return s.RequireConfirm == nil || *s.RequireConfirm
+}
+
+// Protected reports whether a tab URL must never be closed
+func (s Safety) Protected(url string) bool {
+	url = strings.ToLower(url)
+	for _, pattern := range s.ProtectedURLs {
+		if pattern != "" && strings.Contains(url, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package content
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Mode selects how page content is extracted
+type Mode string
+
+const (
+	ModeHTML    Mode = "html"
+	ModeText    Mode = "text"
+	ModeArticle Mode = "article"
+)
+
+// ParseMode parses a mode string and returns the Mode enum
+func ParseMode(modeStr string) (Mode, error) {
+	switch modeStr {
+	case "", "article", "markdown", "md":
+		return ModeArticle, nil
+	case "text", "txt":
+		return ModeText, nil
+	case "html":
+		return ModeHTML, nil
+	default:
+		return ModeArticle, fmt.Errorf("unsupported content mode: %s (supported: article, text, html)", modeStr)
+	}
+}
+
+// Page is the content extracted from a tab
+type Page struct {
+	TabID     string `json:"tabId" yaml:"tabId"`
+	Title     string `json:"title" yaml:"title"`
+	URL       string `json:"url" yaml:"url"`
+	Mode      Mode   `json:"mode" yaml:"mode"`
+	Length    int    `json:"length" yaml:"length"`
+	Truncated bool   `json:"truncated" yaml:"truncated"`
+	Content   string `json:"content" yaml:"content"`
+}
+
+// Truncate limits s to at most limit characters (runes), cutting at the last
+// line break or space in the final tenth of the text when there is one.
+// A limit of zero or less returns s unchanged.
+func Truncate(s string, limit int) (string, bool) {
+	if limit <= 0 || utf8.RuneCountInString(s) <= limit {
+		return s, false
+	}
+
+	runes := []rune(s)[:limit]
+	for i := len(runes) - 1; i >= limit-limit/10 && i > 0; i-- {
+		if runes[i] == '\n' || runes[i] == ' ' {
+			runes = runes[:i]
+			break
+		}
+	}
+
+	return string(runes), true
+}
+
+// PageInfoScript returns the document title and URL
+const PageInfoScript = `({title: document.title, url: location.href})`
+
+// TextScript returns the rendered text of the page body
+const TextScript = `(document.body ? document.body.innerText : document.documentElement.innerText || "").trim()`
+
+// ArticleScript picks the main content of the page, Readability-style, and
+// converts it to Markdown. Candidates are scored by the amount of paragraph
+// text they hold, penalised by link density and unlikely class/id names.
+const ArticleScript = `(() => {
+  const BT = String.fromCharCode(96);
+  const SKIP = new Set(["SCRIPT", "STYLE", "NOSCRIPT", "NAV", "FOOTER", "ASIDE", "FORM", "IFRAME",
+    "SVG", "CANVAS", "BUTTON", "INPUT", "SELECT", "TEXTAREA", "TEMPLATE", "DIALOG"]);
+  const UNLIKELY = /comment|sidebar|footer|masthead|nav|menu|share|social|related|promo|banner|advert|cookie|popup|modal|subscribe|newsletter/i;
+  const text = (el) => (el.innerText || el.textContent || "").trim();
+  const linkDensity = (el) => {
+    const total = text(el).length || 1;
+    let links = 0;
+    el.querySelectorAll("a").forEach((a) => { links += text(a).length; });
+    return links / total;
+  };
+  const unlikely = (el) => UNLIKELY.test((typeof el.className === "string" ? el.className : "") + " " + (el.id || ""));
+
+  const pickRoot = () => {
+    const explicit = document.querySelector("article, main, [role=main]");
+    if (explicit && text(explicit).length > 500) return explicit;
+
+    const scores = new Map();
+    document.querySelectorAll("p, pre, td, blockquote").forEach((p) => {
+      const length = text(p).length;
+      if (length < 25) return;
+      const score = 1 + (text(p).split(/[,、，。]/).length) + Math.min(Math.floor(length / 100), 3);
+      const parent = p.parentElement;
+      if (!parent) return;
+      scores.set(parent, (scores.get(parent) || 0) + score);
+      const grandparent = parent.parentElement;
+      if (grandparent) scores.set(grandparent, (scores.get(grandparent) || 0) + score / 2);
+    });
+
+    let best = null;
+    let bestScore = 0;
+    scores.forEach((score, el) => {
+      let adjusted = score * (1 - linkDensity(el));
+      if (unlikely(el)) adjusted *= 0.25;
+      if (adjusted > bestScore) { best = el; bestScore = adjusted; }
+    });
+    return best || explicit || document.body || document.documentElement;
+  };
+
+  const root = pickRoot();
+
+  const inline = (node) => Array.from(node.childNodes).map(convert).join("");
+  const block = (s) => "\n\n" + s.trim() + "\n\n";
+
+  const list = (node, ordered, depth) => {
+    let index = 0;
+    return "\n" + Array.from(node.children).filter((li) => li.tagName === "LI").map((li) => {
+      index++;
+      const marker = ordered ? index + ". " : "- ";
+      const parts = Array.from(li.childNodes).map((c) =>
+        c.nodeType === 1 && (c.tagName === "UL" || c.tagName === "OL") ? list(c, c.tagName === "OL", depth + 1) : convert(c));
+      return "  ".repeat(depth) + marker + parts.join("").replace(/\s*\n\s*\n\s*/g, "\n").trim();
+    }).join("\n") + "\n";
+  };
+
+  const table = (node) => {
+    const rows = Array.from(node.querySelectorAll("tr")).map((tr) =>
+      "| " + Array.from(tr.children).map((cell) => inline(cell).replace(/\s+/g, " ").replace(/\|/g, "\\|").trim()).join(" | ") + " |");
+    if (rows.length === 0) return "";
+    const columns = node.querySelector("tr").children.length;
+    rows.splice(1, 0, "|" + " --- |".repeat(columns));
+    return block(rows.join("\n"));
+  };
+
+  function convert(node) {
+    if (node.nodeType === 3) return node.textContent.replace(/\s+/g, " ");
+    if (node.nodeType !== 1) return "";
+    const tag = node.tagName;
+    if (SKIP.has(tag) || node.hidden || node.getAttribute("aria-hidden") === "true") return "";
+    if (node !== root && unlikely(node) && linkDensity(node) > 0.5) return "";
+
+    switch (tag) {
+      case "H1": case "H2": case "H3": case "H4": case "H5": case "H6": {
+        const title = inline(node).replace(/\s+/g, " ").trim();
+        return title ? block("#".repeat(Number(tag[1])) + " " + title) : "";
+      }
+      case "P": return block(inline(node));
+      case "BR": return "\n";
+      case "HR": return block("---");
+      case "STRONG": case "B": { const t = inline(node).trim(); return t ? "**" + t + "**" : ""; }
+      case "EM": case "I": { const t = inline(node).trim(); return t ? "_" + t + "_" : ""; }
+      case "CODE": return BT + node.textContent + BT;
+      case "PRE": return block(BT.repeat(3) + "\n" + node.textContent.replace(/\n+$/, "") + "\n" + BT.repeat(3));
+      case "A": {
+        const t = inline(node).trim();
+        const href = node.href || "";
+        if (!t) return "";
+        return href && !href.startsWith("javascript:") ? "[" + t + "](" + href + ")" : t;
+      }
+      case "IMG": {
+        const src = node.currentSrc || node.src || "";
+        return src && !src.startsWith("data:") ? "![" + (node.alt || "").trim() + "](" + src + ")" : "";
+      }
+      case "UL": return block(list(node, false, 0));
+      case "OL": return block(list(node, true, 0));
+      case "BLOCKQUOTE": return block(inline(node).trim().split("\n").map((line) => "> " + line).join("\n"));
+      case "TABLE": return table(node);
+      case "DIV": case "SECTION": case "ARTICLE": case "MAIN": case "HEADER": case "FIGURE": case "FIGCAPTION":
+        return "\n" + inline(node) + "\n";
+      default: return inline(node);
+    }
+  }
+
+  const body = convert(root)
+    .replace(/[ \t]+\n/g, "\n")
+    .replace(/\n[ \t]+(?=[^ \t\-\d])/g, "\n")
+    .replace(/\n{3,}/g, "\n\n")
+    .trim();
+  const title = document.title.trim();
+  return (title && !body.startsWith("# ") ? "# " + title + "\n\n" : "") + body;
+})()`
@@ -0,0 +1,241 @@
+package dedupe
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/search"
+)
+
+// Ways tabs end up in one group
+const (
+	// The tabs share a normalized URL
+	MatchURL = "url"
+	// Similar titles joined tabs of one site with different URLs
+	MatchTitle = "title"
+)
+
+// DefaultTitleSimilarity is the share of title words two tabs must have in
+// common to be grouped by title
+const DefaultTitleSimilarity = 0.8
+
+// Options tune duplicate detection
+type Options struct {
+	// Also group tabs of one site whose titles are similar
+	ByTitle bool
+	// Share of common title words (0 to 1) for ByTitle (default: DefaultTitleSimilarity)
+	TitleSimilarity float64
+}
+
+// Tab is a tab of a group
+type Tab struct {
+	ID    string `json:"id" yaml:"id"`
+	Title string `json:"title" yaml:"title"`
+	URL   string `json:"url" yaml:"url"`
+}
+
+// Group is a set of tabs on one device showing the same page. One of them
+// is suggested to keep; the others can be closed.
+type Group struct {
+	Device string `json:"device,omitempty" yaml:"device,omitempty"`
+	// The normalized URL of the kept tab
+	Key   string `json:"key" yaml:"key"`
+	Match string `json:"match" yaml:"match"`
+	Keep  Tab    `json:"keep" yaml:"keep"`
+	Close []Tab  `json:"close" yaml:"close"`
+}
+
+// Report lists the duplicate groups among a set of tabs
+type Report struct {
+	Tabs int `json:"tabs" yaml:"tabs"`
+	// Number of tabs that can be closed, all groups together
+	Duplicates int     `json:"duplicates" yaml:"duplicates"`
+	Groups     []Group `json:"groups" yaml:"groups"`
+}
+
+// CloseIDs returns the IDs of the tabs to close per device, in group order
+func (r *Report) CloseIDs() map[string][]string {
+	ids := make(map[string][]string)
+	for _, group := range r.Groups {
+		for _, tab := range group.Close {
+			ids[group.Device] = append(ids[group.Device], tab.ID)
+		}
+	}
+	return ids
+}
+
+// cluster is a set of tabs of one device found to be duplicates
+type cluster struct {
+	device string
+	key    string
+	match  string
+	tabs   []loader.Tab
+	// Index of the tab that opened the cluster, for a stable order
+	first int
+}
+
+// Find groups the tabs showing the same page on the same device. Tabs are
+// grouped by NormalizeURL and, with ByTitle, groups of one site with similar
+// titles are joined. The suggested keeper of a group is the tab with the
+// tidiest URL (https, no mobile or AMP variant, no tracking parameters or
+// anchor), the first listed among equals. Groups with more tabs come first.
+func Find(tabs []loader.Tab, options Options) *Report {
+	var clusters []*cluster
+	byKey := make(map[string]*cluster)
+	for i, tab := range tabs {
+		if tab.URL == "" {
+			continue
+		}
+		key := NormalizeURL(tab.URL)
+		c, ok := byKey[tab.Device+"\x00"+key]
+		if !ok {
+			c = &cluster{device: tab.Device, key: key, match: MatchURL, first: i}
+			byKey[tab.Device+"\x00"+key] = c
+			clusters = append(clusters, c)
+		}
+		c.tabs = append(c.tabs, tab)
+	}
+
+	if options.ByTitle {
+		threshold := options.TitleSimilarity
+		if threshold <= 0 || threshold > 1 {
+			threshold = DefaultTitleSimilarity
+		}
+		clusters = joinSimilarTitles(clusters, threshold)
+	}
+
+	report := &Report{Tabs: len(tabs), Groups: make([]Group, 0)}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].tabs) != len(clusters[j].tabs) {
+			return len(clusters[i].tabs) > len(clusters[j].tabs)
+		}
+		return clusters[i].first < clusters[j].first
+	})
+	for _, c := range clusters {
+		if len(c.tabs) < 2 {
+			continue
+		}
+
+		keeper := 0
+		for i, tab := range c.tabs {
+			if untidiness(tab.URL) < untidiness(c.tabs[keeper].URL) {
+				keeper = i
+			}
+		}
+
+		group := Group{
+			Device: c.device,
+			Key:    NormalizeURL(c.tabs[keeper].URL),
+			Match:  c.match,
+			Keep:   groupTab(c.tabs[keeper]),
+		}
+		for i, tab := range c.tabs {
+			if i != keeper {
+				group.Close = append(group.Close, groupTab(tab))
+			}
+		}
+		report.Groups = append(report.Groups, group)
+		report.Duplicates += len(group.Close)
+	}
+	return report
+}
+
+func groupTab(tab loader.Tab) Tab {
+	return Tab{ID: tab.ID, Title: tab.Title, URL: tab.URL}
+}
+
+// joinSimilarTitles merges the clusters of one device and site whose titles
+// share at least threshold of their words
+func joinSimilarTitles(clusters []*cluster, threshold float64) []*cluster {
+	words := make([]map[string]bool, len(clusters))
+	for i, c := range clusters {
+		words[i] = titleWords(c.tabs[0].Title)
+	}
+
+	// Union-find over the clusters
+	parent := make([]int, len(clusters))
+	for i := range parent {
+		parent[i] = i
+	}
+	root := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for i := range clusters {
+		// Titles of a word or two say too little to join pages
+		if len(words[i]) < 3 {
+			continue
+		}
+		for j := i + 1; j < len(clusters); j++ {
+			if len(words[j]) < 3 || clusters[i].device != clusters[j].device || site(clusters[i].key) != site(clusters[j].key) {
+				continue
+			}
+			if similarity(words[i], words[j]) >= threshold {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	joined := make(map[int]*cluster)
+	var result []*cluster
+	for i, c := range clusters {
+		r := root(i)
+		target, ok := joined[r]
+		if !ok {
+			joined[r] = c
+			result = append(result, c)
+			continue
+		}
+		target.tabs = append(target.tabs, c.tabs...)
+		target.match = MatchTitle
+	}
+	return result
+}
+
+// titleSeparators split a page title from the site name after it
+var titleSeparators = []string{" | ", " - ", " – ", " — ", " :: ", " · "}
+
+// titleWords returns the words of a title without the site name that often
+// ends it, e.g. "Article - Example News"
+func titleWords(title string) map[string]bool {
+	for _, separator := range titleSeparators {
+		if i := strings.LastIndex(title, separator); i > 0 {
+			title = title[:i]
+			break
+		}
+	}
+	words := make(map[string]bool)
+	for _, token := range search.Tokenize(title) {
+		words[token] = true
+	}
+	return words
+}
+
+// similarity returns the share of common words of two titles (Jaccard index)
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// site returns the host of a normalized URL
+func site(key string) string {
+	if _, rest, found := strings.Cut(key, "://"); found {
+		key = rest
+	}
+	host, _, _ := strings.Cut(key, "/")
+	host, _, _ = strings.Cut(host, "?")
+	return host
+}
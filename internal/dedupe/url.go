@@ -0,0 +1,160 @@
+package dedupe
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only track where a visit came
+// from. Parameters starting with utm_ are dropped too.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"gclsrc":  true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_ga":     true,
+	"_gl":     true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"ref_src": true,
+}
+
+// mobileHostPrefixes are host name prefixes of mobile and AMP variants of a site
+var mobileHostPrefixes = []string{"m.", "mobile.", "amp."}
+
+// isTrackingParam reports whether a query parameter only tracks the visit
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return trackingParams[key] || strings.HasPrefix(key, "utm_")
+}
+
+// isRouteFragment reports whether a fragment selects a page of a hash-routed
+// app (#inbox/abc, #!/settings) rather than an anchor within the page
+func isRouteFragment(fragment string) bool {
+	return strings.Contains(fragment, "/") || strings.HasPrefix(fragment, "!")
+}
+
+// NormalizeURL returns the form of a URL that equal pages share. The scheme
+// (http or https), a leading www., m. or amp. of the host, AMP variants
+// (/amp paths, ?amp=1 and the Google AMP caches), in-page anchors, tracking
+// parameters (utm_*, fbclid, gclid and the like) and a trailing slash are
+// dropped, and the remaining query parameters are sorted. Fragments that
+// look like routes of hash-routed apps are kept, as they name different pages.
+func NormalizeURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		withoutFragment, fragment, _ := strings.Cut(rawURL, "#")
+		normalized := strings.TrimRight(withoutFragment, "/")
+		if isRouteFragment(fragment) {
+			normalized += "#" + fragment
+		}
+		return normalized
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if inner, ok := ampCacheTarget(host, parsed.EscapedPath()); ok {
+		return NormalizeURL(inner)
+	}
+
+	host = strings.TrimPrefix(host, "www.")
+	for _, prefix := range mobileHostPrefixes {
+		if strings.HasPrefix(host, prefix) {
+			host = strings.TrimPrefix(host, prefix)
+			break
+		}
+	}
+	if port := parsed.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := strings.TrimRight(parsed.EscapedPath(), "/")
+	path = strings.TrimSuffix(path, "/amp")
+	path = strings.TrimSuffix(path, ".amp")
+	path = strings.TrimRight(path, "/")
+
+	values := parsed.Query()
+	for key := range values {
+		if isTrackingParam(key) || strings.EqualFold(key, "amp") {
+			values.Del(key)
+		}
+	}
+
+	normalized := host + path
+	if scheme := strings.ToLower(parsed.Scheme); scheme != "http" && scheme != "https" {
+		normalized = scheme + "://" + normalized
+	}
+	if query := values.Encode(); query != "" {
+		normalized += "?" + query
+	}
+	if isRouteFragment(parsed.Fragment) {
+		normalized += "#" + parsed.EscapedFragment()
+	}
+	return normalized
+}
+
+// ampCacheTarget returns the URL of the page behind a Google AMP cache URL:
+// www.google.com/amp/s/example.com/a and example-com.cdn.ampproject.org/c/s/example.com/a
+func ampCacheTarget(host, path string) (string, bool) {
+	var rest string
+	switch {
+	case (host == "google.com" || host == "www.google.com") && strings.HasPrefix(path, "/amp/"):
+		rest = strings.TrimPrefix(path, "/amp/")
+	case strings.HasSuffix(host, ".cdn.ampproject.org"):
+		rest = strings.TrimPrefix(path, "/")
+		for _, marker := range []string{"c/", "v/", "i/"} {
+			rest = strings.TrimPrefix(rest, marker)
+		}
+	default:
+		return "", false
+	}
+	// s/ marks an https target
+	rest = strings.TrimPrefix(rest, "s/")
+	if rest == "" {
+		return "", false
+	}
+	return "https://" + rest, true
+}
+
+// untidiness counts what normalization removes from a URL: the variants a
+// keeper should not be chosen from. A plain https URL scores 0.
+func untidiness(rawURL string) int {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+
+	score := 0
+	if strings.EqualFold(parsed.Scheme, "http") {
+		score++
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if _, ok := ampCacheTarget(strings.ToLower(parsed.Hostname()), parsed.EscapedPath()); ok {
+		score++
+	} else {
+		for _, prefix := range mobileHostPrefixes {
+			if strings.HasPrefix(host, prefix) {
+				score++
+				break
+			}
+		}
+	}
+	path := strings.TrimRight(parsed.EscapedPath(), "/")
+	if strings.HasSuffix(path, "/amp") || strings.HasSuffix(path, ".amp") || parsed.Query().Has("amp") {
+		score++
+	}
+	for key := range parsed.Query() {
+		if isTrackingParam(key) {
+			score++
+			break
+		}
+	}
+	if parsed.Fragment != "" && !isRouteFragment(parsed.Fragment) {
+		score++
+	}
+	return score
+}
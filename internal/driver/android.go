@@ -5,17 +5,24 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/kazuph/mcp-android-chrome/internal/adb"
+	"github.com/kazuph/mcp-android-chrome/internal/cdp"
+	"github.com/kazuph/mcp-android-chrome/internal/content"
 	"github.com/kazuph/mcp-android-chrome/internal/loader"
 	"github.com/kazuph/mcp-android-chrome/internal/platform"
 )
 
 // AndroidDriver implements Driver for Android devices using ADB
 type AndroidDriver struct {
-	config   AndroidConfig
+	config    AndroidConfig
+	adb       adb.Backend
 	tabLoader *loader.HTTPTabLoader
+	device    string
+	browser   string
 }
 
 // NewAndroidDriver creates a new Android driver
@@ -27,27 +34,29 @@ func NewAndroidDriver(config AndroidConfig) *AndroidDriver {
 
 // Start sets up ADB port forwarding
 func (d *AndroidDriver) Start(ctx context.Context) error {
-	if err := d.CheckEnvironment(); err != nil {
+	if err := d.CheckEnvironment(ctx); err != nil {
 		return fmt.Errorf("environment check failed: %w", err)
 	}
 
 	// Check if Android device is connected
-	if err := platform.CheckADBDeviceConnected(); err != nil {
+	devices, err := d.adb.Devices(ctx)
+	if err != nil {
+		return fmt.Errorf("device connection check failed: %w", err)
+	}
+	if err := platform.CheckADBDevices(devices, d.config.Serial); err != nil {
 		return fmt.Errorf("device connection check failed: %w", err)
 	}
+	d.device = selectDevice(devices, d.config.Serial)
 
-	// Setup ADB port forwarding using absolute path
-	adbPath := platform.FindADBPath()
-	cmd := exec.CommandContext(ctx, adbPath, "-d", "forward", 
-		fmt.Sprintf("tcp:%d", d.config.Port),
-		fmt.Sprintf("localabstract:%s", d.config.Socket))
-	
+	// Setup ADB port forwarding
 	if d.config.Debug {
-		fmt.Fprintf(os.Stderr, "Executing: %s\n", cmd.String())
+		fmt.Fprintf(os.Stderr, "Using %s ADB backend\n", d.adb.Name())
 	}
-	
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to setup ADB port forwarding: %w", err)
+
+	if err := d.adb.Forward(ctx, d.config.Serial,
+		fmt.Sprintf("tcp:%d", d.config.Port),
+		fmt.Sprintf("localabstract:%s", d.config.Socket)); err != nil {
+		return err
 	}
 
 	// Wait for connection to be ready
@@ -58,28 +67,75 @@ func (d *AndroidDriver) Start(ctx context.Context) error {
 	// Initialize HTTP tab loader
 	d.tabLoader = loader.NewHTTPTabLoader(d.GetURL(), d.config.Timeout, d.config.Debug)
 	
+	// Identify the browser for tagging tabs (best effort)
+	version, err := loader.LoadBrowserVersion(ctx, d.baseURL(), d.config.Timeout)
+	if err == nil {
+		d.browser = version.Browser
+	} else if d.config.Debug {
+		fmt.Fprintf(os.Stderr, "Failed to load browser version: %v\n", err)
+	}
+	
+	// Make sure the socket belongs to the expected browser
+	if d.config.Browser != "" {
+		if err != nil {
+			d.Stop(ctx)
+			return fmt.Errorf("failed to identify the browser on socket %s: %w", d.config.Socket, err)
+		}
+		name, _, _ := strings.Cut(d.browser, "/")
+		if !strings.EqualFold(name, d.config.Browser) {
+			d.Stop(ctx)
+			return fmt.Errorf("socket %s serves %s, not %s", d.config.Socket, d.browser, d.config.Browser)
+		}
+	}
+	
 	return nil
 }
 
-// Stop cleans up ADB port forwarding
-func (d *AndroidDriver) Stop(ctx context.Context) error {
-	if d.config.SkipCleanup {
-		return nil
+// selectDevice returns the serial of the device ADB operates on: the given
+// serial, or the USB-attached device like adb -d when it is empty
+func selectDevice(devices []platform.ADBDevice, serial string) string {
+	if serial != "" {
+		return serial
 	}
-
-	adbPath := platform.FindADBPath()
-	cmd := exec.CommandContext(ctx, adbPath, "-d", "forward", "--remove",
-		fmt.Sprintf("tcp:%d", d.config.Port))
 	
-	if d.config.Debug {
-		fmt.Fprintf(os.Stderr, "Executing cleanup: %s\n", cmd.String())
+	fallback := ""
+	for _, device := range devices {
+		if device.State != "device" {
+			continue
+		}
+		if device.USB != "" {
+			return device.Serial
+		}
+		if fallback == "" {
+			fallback = device.Serial
+		}
 	}
 	
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to cleanup ADB port forwarding: %w", err)
+	return fallback
+}
+
+// baseURL returns the Chrome DevTools HTTP endpoint forwarded from the device
+func (d *AndroidDriver) baseURL() string {
+	return fmt.Sprintf("http://localhost:%d", d.config.Port)
+}
+
+// Device returns the serial of the device the driver is attached to
+func (d *AndroidDriver) Device() string {
+	return d.device
+}
+
+// Browser returns the product of the browser on the device, e.g. Chrome/126.0.6478.71
+func (d *AndroidDriver) Browser() string {
+	return d.browser
+}
+
+// Stop cleans up ADB port forwarding
+func (d *AndroidDriver) Stop(ctx context.Context) error {
+	if d.config.SkipCleanup || d.adb == nil {
+		return nil
 	}
-	
-	return nil
+
+	return d.adb.KillForward(ctx, d.config.Serial, fmt.Sprintf("tcp:%d", d.config.Port))
 }
 
 // GetURL returns the Chrome DevTools Protocol URL
@@ -87,9 +143,18 @@ func (d *AndroidDriver) GetURL() string {
 	return fmt.Sprintf("http://localhost:%d/json/list", d.config.Port)
 }
 
-// CheckEnvironment verifies ADB is available
-func (d *AndroidDriver) CheckEnvironment() error {
-	return platform.CheckADBAvailable()
+// CheckEnvironment resolves the configured ADB backend and verifies it is
+// available within ctx
+func (d *AndroidDriver) CheckEnvironment(ctx context.Context) error {
+	if d.adb == nil {
+		backend, err := adb.NewBackend(d.config.ADBBackend, d.config.Timeout, d.config.Debug)
+		if err != nil {
+			return err
+		}
+		d.adb = backend
+	}
+
+	return d.adb.CheckAvailable(ctx)
 }
 
 // LoadTabs retrieves tabs from the Android device
@@ -98,17 +163,23 @@ func (d *AndroidDriver) LoadTabs(ctx context.Context) ([]loader.Tab, error) {
 		return nil, fmt.Errorf("driver not started")
 	}
 	
-	return d.tabLoader.LoadTabs(ctx)
+	tabs, err := d.tabLoader.LoadTabs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	loader.TagTabs(tabs, d.device, d.browser, time.Now())
+	return tabs, nil
 }
 
 // RestoreTabs implements RestoreDriver interface for Android
-func (d *AndroidDriver) RestoreTabs(ctx context.Context, tabs []loader.Tab) error {
+func (d *AndroidDriver) RestoreTabs(ctx context.Context, tabs []loader.Tab) (*loader.BatchResult, error) {
 	if d.tabLoader == nil {
-		return fmt.Errorf("driver not started")
+		return nil, fmt.Errorf("driver not started")
 	}
 	
-	baseURL := fmt.Sprintf("http://localhost:%d", d.config.Port)
-	restorer := loader.NewHTTPTabRestorer(baseURL, d.config.Timeout, d.config.Debug)
+	restorer := loader.NewHTTPTabRestorer(d.baseURL(), d.config.Timeout, d.config.Debug)
+	restorer.OnProgress = d.config.Progress
 	
 	return restorer.RestoreTabs(ctx, tabs)
 }
@@ -126,7 +197,7 @@ func (d *AndroidDriver) CloseTab(ctx context.Context, tabID string) error {
 		return fmt.Errorf("tab with ID '%s' does not exist", tabID)
 	}
 	
-	closeURL := fmt.Sprintf("http://localhost:%d/json/close/%s", d.config.Port, tabID)
+	closeURL := fmt.Sprintf("%s/json/close/%s", d.baseURL(), tabID)
 	
 	if d.config.Debug {
 		fmt.Fprintf(os.Stderr, "Closing tab: %s -> %s\n", tabID, closeURL)
@@ -155,66 +226,236 @@ func (d *AndroidDriver) CloseTab(ctx context.Context, tabID string) error {
 	return nil
 }
 
-// tabExists checks if a tab with the given ID exists
-func (d *AndroidDriver) tabExists(ctx context.Context, tabID string) (bool, error) {
-	tabs, err := d.LoadTabs(ctx)
+// ActivateTab brings the tab with the given ID to the front
+func (d *AndroidDriver) ActivateTab(ctx context.Context, tabID string) error {
+	if d.tabLoader == nil {
+		return fmt.Errorf("driver not started")
+	}
+	
+	// First, verify the tab exists
+	if exists, err := d.tabExists(ctx, tabID); err != nil {
+		return fmt.Errorf("failed to verify tab existence: %w", err)
+	} else if !exists {
+		return fmt.Errorf("tab with ID '%s' does not exist", tabID)
+	}
+	
+	activateURL := fmt.Sprintf("%s/json/activate/%s", d.baseURL(), tabID)
+	
+	if d.config.Debug {
+		fmt.Fprintf(os.Stderr, "Activating tab: %s -> %s\n", tabID, activateURL)
+	}
+	
+	req, err := http.NewRequestWithContext(ctx, "GET", activateURL, nil)
 	if err != nil {
-		return false, err
+		return fmt.Errorf("failed to create activate request: %w", err)
+	}
+	
+	client := &http.Client{Timeout: d.config.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to activate tab: %w", err)
 	}
+	defer resp.Body.Close()
 	
-	for _, tab := range tabs {
-		if tab.ID == tabID {
-			return true, nil
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code when activating tab: %d", resp.StatusCode)
+	}
+	
+	return nil
+}
+
+// AttachTab opens a Chrome DevTools Protocol session to the tab with the given ID.
+// The caller must close the session.
+func (d *AndroidDriver) AttachTab(ctx context.Context, tabID string) (*cdp.Session, error) {
+	if d.tabLoader == nil {
+		return nil, fmt.Errorf("driver not started")
+	}
+	
+	tab, err := d.findTab(ctx, tabID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify tab existence: %w", err)
+	} else if tab == nil {
+		return nil, fmt.Errorf("tab with ID '%s' does not exist", tabID)
+	}
+	
+	wsURL := tab.WebSocketDebuggerURL
+	if wsURL == "" {
+		// Chrome omits the URL while DevTools is attached to the tab elsewhere
+		if wsURL, err = cdp.PageURL(d.baseURL(), tabID); err != nil {
+			return nil, err
 		}
 	}
 	
-	return false, nil
+	session, err := cdp.Dial(ctx, wsURL, d.config.Timeout, d.config.Debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to tab %s: %w", tabID, err)
+	}
+	
+	return session, nil
+}
+
+// withTab runs fn with a CDP session attached to the tab with the given ID
+func (d *AndroidDriver) withTab(ctx context.Context, tabID string, fn func(*cdp.Session) error) error {
+	session, err := d.AttachTab(ctx, tabID)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	
+	return fn(session)
 }
 
-// TabCloseResult represents the result of closing multiple tabs
-type TabCloseResult struct {
-	SuccessCount int
-	FailedCount  int
-	FailedTabIDs []string
-	FailedErrors map[string]string // tabID -> error message
+// NavigateTab loads a new URL in an existing tab, keeping its history
+func (d *AndroidDriver) NavigateTab(ctx context.Context, tabID, url string) error {
+	return d.withTab(ctx, tabID, func(session *cdp.Session) error {
+		return session.Navigate(ctx, url)
+	})
 }
 
-// CloseTabs closes multiple tabs by their IDs and returns detailed results
-func (d *AndroidDriver) CloseTabs(ctx context.Context, tabIDs []string) error {
-	if d.tabLoader == nil {
-		return fmt.Errorf("driver not started")
+// ReloadTab reloads an existing tab, optionally bypassing the cache
+func (d *AndroidDriver) ReloadTab(ctx context.Context, tabID string, ignoreCache bool) error {
+	return d.withTab(ctx, tabID, func(session *cdp.Session) error {
+		return session.Reload(ctx, ignoreCache)
+	})
+}
+
+// TabHistory returns the navigation history of an existing tab
+func (d *AndroidDriver) TabHistory(ctx context.Context, tabID string) (*cdp.NavigationHistory, error) {
+	var history *cdp.NavigationHistory
+	err := d.withTab(ctx, tabID, func(session *cdp.Session) error {
+		var err error
+		history, err = session.NavigationHistory(ctx)
+		return err
+	})
+	
+	return history, err
+}
+
+// GoTabHistory moves an existing tab delta entries through its history
+// (-1 for back, 1 for forward) and returns the entry it moved to
+func (d *AndroidDriver) GoTabHistory(ctx context.Context, tabID string, delta int) (*cdp.NavigationEntry, error) {
+	var entry *cdp.NavigationEntry
+	err := d.withTab(ctx, tabID, func(session *cdp.Session) error {
+		var err error
+		entry, err = session.GoHistory(ctx, delta)
+		return err
+	})
+	
+	return entry, err
+}
+
+// NavigateTabToHistoryEntry moves an existing tab to the history entry with the given ID
+func (d *AndroidDriver) NavigateTabToHistoryEntry(ctx context.Context, tabID string, entryID int) error {
+	return d.withTab(ctx, tabID, func(session *cdp.Session) error {
+		return session.NavigateToHistoryEntry(ctx, entryID)
+	})
+}
+
+// CaptureTabScreenshot captures a screenshot of an existing tab and returns
+// it as base64-encoded image data
+func (d *AndroidDriver) CaptureTabScreenshot(ctx context.Context, tabID string, opts cdp.ScreenshotOptions) (string, error) {
+	var data string
+	err := d.withTab(ctx, tabID, func(session *cdp.Session) error {
+		var err error
+		data, err = session.CaptureScreenshot(ctx, opts)
+		return err
+	})
+	
+	return data, err
+}
+
+// TabContent extracts the content of an existing tab as raw HTML, plain text
+// or the main article converted to Markdown
+func (d *AndroidDriver) TabContent(ctx context.Context, tabID string, mode content.Mode) (*content.Page, error) {
+	page := &content.Page{
+		TabID: tabID,
+		Mode:  mode,
 	}
 	
-	if d.config.Debug {
-		fmt.Fprintf(os.Stderr, "Closing %d tabs\n", len(tabIDs))
+	err := d.withTab(ctx, tabID, func(session *cdp.Session) error {
+		var info struct {
+			Title string `json:"title"`
+			URL   string `json:"url"`
+		}
+		if err := session.Evaluate(ctx, content.PageInfoScript, &info); err != nil {
+			return err
+		}
+		page.Title = info.Title
+		page.URL = info.URL
+		
+		switch mode {
+		case content.ModeHTML:
+			html, err := session.OuterHTML(ctx)
+			if err != nil {
+				return err
+			}
+			page.Content = html
+		case content.ModeText:
+			return session.Evaluate(ctx, content.TextScript, &page.Content)
+		case content.ModeArticle:
+			return session.Evaluate(ctx, content.ArticleScript, &page.Content)
+		default:
+			return fmt.Errorf("unsupported content mode: %s", mode)
+		}
+		
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	
-	result := TabCloseResult{
-		FailedTabIDs: make([]string, 0),
-		FailedErrors: make(map[string]string),
+	page.Length = utf8.RuneCountInString(page.Content)
+	return page, nil
+}
+
+// tabExists checks if a tab with the given ID exists
+func (d *AndroidDriver) tabExists(ctx context.Context, tabID string) (bool, error) {
+	tab, err := d.findTab(ctx, tabID)
+	if err != nil {
+		return false, err
 	}
 	
-	for _, tabID := range tabIDs {
-		if err := d.CloseTab(ctx, tabID); err != nil {
-			if d.config.Debug {
-				fmt.Fprintf(os.Stderr, "Failed to close tab %s: %v\n", tabID, err)
-			}
-			result.FailedCount++
-			result.FailedTabIDs = append(result.FailedTabIDs, tabID)
-			result.FailedErrors[tabID] = err.Error()
-		} else {
-			result.SuccessCount++
+	return tab != nil, nil
+}
+
+// findTab returns the tab with the given ID, or nil if it does not exist
+func (d *AndroidDriver) findTab(ctx context.Context, tabID string) (*loader.Tab, error) {
+	tabs, err := d.LoadTabs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	for i := range tabs {
+		if tabs[i].ID == tabID {
+			return &tabs[i], nil
 		}
 	}
 	
-	if result.FailedCount > 0 {
-		return fmt.Errorf("partially successful: closed %d/%d tabs successfully. Failed tabs: %v", 
-			result.SuccessCount, len(tabIDs), result.FailedTabIDs)
+	return nil, nil
+}
+
+// CloseTabs closes multiple tabs by their IDs and reports the outcome of
+// every tab. When ctx is cancelled or expires partway through, it stops before
+// the next tab and returns a *loader.BatchError along with the result.
+func (d *AndroidDriver) CloseTabs(ctx context.Context, tabIDs []string) (*loader.BatchResult, error) {
+	if d.tabLoader == nil {
+		return nil, fmt.Errorf("driver not started")
 	}
 	
 	if d.config.Debug {
-		fmt.Fprintf(os.Stderr, "Successfully closed all %d tabs\n", len(tabIDs))
+		fmt.Fprintf(os.Stderr, "Closing %d tabs\n", len(tabIDs))
 	}
 	
-	return nil
+	// Titles and URLs for the result; closing works without them
+	tabs, err := d.LoadTabs(ctx)
+	if err != nil && d.config.Debug {
+		fmt.Fprintf(os.Stderr, "Failed to load tabs before closing: %v\n", err)
+	}
+	
+	result, err := closeTabs(ctx, tabIDs, tabs, d.CloseTab, d.config.Progress)
+	if err == nil && d.config.Debug {
+		fmt.Fprintf(os.Stderr, "Closed %d of %d tabs\n", result.Succeeded, len(tabIDs))
+	}
+	
+	return result, err
 }
\ No newline at end of file
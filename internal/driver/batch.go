@@ -0,0 +1,62 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// closeTabs closes tabs one by one with closeTab and records the outcome of
+// every tab, taking titles and URLs from the tabs loaded before. A tab that
+// fails to close does not stop the others. When ctx is cancelled or expires
+// partway through, it stops before the next tab and records the rest as
+// skipped.
+func closeTabs(ctx context.Context, tabIDs []string, loaded []loader.Tab, closeTab func(context.Context, string) error, progress loader.ProgressFunc) (*loader.BatchResult, error) {
+	known := make(map[string]loader.Tab, len(loaded))
+	for _, tab := range loaded {
+		known[tab.ID] = tab
+	}
+	resultOf := func(tabID string) loader.TabResult {
+		tab, ok := known[tabID]
+		if !ok {
+			tab = loader.Tab{ID: tabID}
+		}
+		return loader.TabResultOf(tab)
+	}
+	remaining := func(tabIDs []string) []loader.TabResult {
+		results := make([]loader.TabResult, 0, len(tabIDs))
+		for _, tabID := range tabIDs {
+			results = append(results, resultOf(tabID))
+		}
+		return results
+	}
+
+	result := loader.NewBatchResult("close", len(tabIDs))
+	for i, tabID := range tabIDs {
+		// Stop cleanly when cancelled or out of time
+		if ctx.Err() != nil {
+			return result, result.Stop("closing", remaining(tabIDs[i:]), ctx.Err())
+		}
+
+		tabResult := resultOf(tabID)
+		message := fmt.Sprintf("Closed tab %s", tabID)
+		if err := closeTab(ctx, tabID); err != nil {
+			if ctx.Err() != nil {
+				return result, result.Stop("closing", remaining(tabIDs[i:]), ctx.Err())
+			}
+			tabResult.Status = loader.StatusFailed
+			tabResult.Error = err.Error()
+			message = fmt.Sprintf("Failed to close tab %s", tabID)
+		} else {
+			tabResult.Status = loader.StatusClosed
+		}
+		result.Record(tabResult)
+
+		if progress != nil {
+			progress(i+1, len(tabIDs), message)
+		}
+	}
+
+	return result, nil
+}
@@ -2,9 +2,12 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/kazuph/mcp-android-chrome/internal/loader"
@@ -16,6 +19,7 @@ type IOSDriver struct {
 	config   IOSConfig
 	cmd      *exec.Cmd
 	tabLoader *loader.HTTPTabLoader
+	device    string
 }
 
 // NewIOSDriver creates a new iOS driver
@@ -27,7 +31,7 @@ func NewIOSDriver(config IOSConfig) *IOSDriver {
 
 // Start launches ios_webkit_debug_proxy as a background process
 func (d *IOSDriver) Start(ctx context.Context) error {
-	if err := d.CheckEnvironment(); err != nil {
+	if err := d.CheckEnvironment(ctx); err != nil {
 		return fmt.Errorf("environment check failed: %w", err)
 	}
 
@@ -56,9 +60,67 @@ func (d *IOSDriver) Start(ctx context.Context) error {
 	// Initialize HTTP tab loader
 	d.tabLoader = loader.NewHTTPTabLoader(d.GetURL(), d.config.Timeout, d.config.Debug)
 	
+	// Identify the device for tagging tabs (best effort)
+	d.device = d.resolveDevice(ctx)
+	
 	return nil
 }
 
+// iosDeviceEntry is an entry of the ios_webkit_debug_proxy device list on port 9221
+type iosDeviceEntry struct {
+	DeviceID   string `json:"deviceId"`
+	DeviceName string `json:"deviceName"`
+	URL        string `json:"url"`
+}
+
+// resolveDevice looks up the name of the device served on the configured
+// port from the proxy's device list, falling back to "ios"
+func (d *IOSDriver) resolveDevice(ctx context.Context) string {
+	loadCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	
+	req, err := http.NewRequestWithContext(loadCtx, "GET", "http://localhost:9221/json", nil)
+	if err != nil {
+		return "ios"
+	}
+	
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if d.config.Debug {
+			fmt.Fprintf(os.Stderr, "Failed to load iOS device list: %v\n", err)
+		}
+		return "ios"
+	}
+	defer resp.Body.Close()
+	
+	var entries []iosDeviceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return "ios"
+	}
+	
+	suffix := fmt.Sprintf(":%d", d.config.Port)
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.URL, suffix) {
+			if entry.DeviceName != "" {
+				return entry.DeviceName
+			}
+			return entry.DeviceID
+		}
+	}
+	
+	return "ios"
+}
+
+// Device returns the name of the device the driver is attached to
+func (d *IOSDriver) Device() string {
+	return d.device
+}
+
+// Browser returns the browser engine of the device, which is always WebKit on iOS
+func (d *IOSDriver) Browser() string {
+	return "WebKit"
+}
+
 // Stop terminates the ios_webkit_debug_proxy process
 func (d *IOSDriver) Stop(ctx context.Context) error {
 	if d.cmd == nil {
@@ -86,7 +148,7 @@ func (d *IOSDriver) GetURL() string {
 }
 
 // CheckEnvironment verifies ios_webkit_debug_proxy is available
-func (d *IOSDriver) CheckEnvironment() error {
+func (d *IOSDriver) CheckEnvironment(ctx context.Context) error {
 	return platform.CheckIOSWebKitDebugProxyAvailable()
 }
 
@@ -96,13 +158,19 @@ func (d *IOSDriver) LoadTabs(ctx context.Context) ([]loader.Tab, error) {
 		return nil, fmt.Errorf("driver not started")
 	}
 	
-	return d.tabLoader.LoadTabs(ctx)
+	tabs, err := d.tabLoader.LoadTabs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	loader.TagTabs(tabs, d.device, d.Browser(), time.Now())
+	return tabs, nil
 }
 
 // RestoreTabs implements RestoreDriver interface for iOS using WebSocket
-func (d *IOSDriver) RestoreTabs(ctx context.Context, tabs []loader.Tab) error {
+func (d *IOSDriver) RestoreTabs(ctx context.Context, tabs []loader.Tab) (*loader.BatchResult, error) {
 	if d.cmd == nil {
-		return fmt.Errorf("driver not started")
+		return nil, fmt.Errorf("driver not started")
 	}
 
 	// For iOS restoration, we need to use the WebSocket approach
@@ -110,7 +178,14 @@ func (d *IOSDriver) RestoreTabs(ctx context.Context, tabs []loader.Tab) error {
 	baseURL := fmt.Sprintf("http://localhost:%d", d.config.Port)
 	restorer := loader.NewWebSocketTabRestorer(baseURL, d.config.Debug)
 	
-	return restorer.RestoreTabs(ctx, tabs)
+	result, err := restorer.RestoreTabs(ctx, tabs)
+	if err != nil {
+		return nil, err
+	}
+	
+	// The browser restores all tabs at once
+	d.reportProgress(len(tabs), len(tabs), "Opened the restoration page in the browser")
+	return result, nil
 }
 
 // CloseTab closes a single tab by its ID (iOS implementation)
@@ -130,40 +205,38 @@ func (d *IOSDriver) CloseTab(ctx context.Context, tabID string) error {
 	return d.closeTabViaWebSocket(ctx, tabID)
 }
 
-// CloseTabs closes multiple tabs by their IDs (iOS implementation)
-func (d *IOSDriver) CloseTabs(ctx context.Context, tabIDs []string) error {
+// reportProgress reports the progress of a batch operation, if requested
+func (d *IOSDriver) reportProgress(done, total int, message string) {
+	if d.config.Progress != nil {
+		d.config.Progress(done, total, message)
+	}
+}
+
+// CloseTabs closes multiple tabs by their IDs (iOS implementation) and
+// reports the outcome of every tab. When ctx is cancelled or expires partway
+// through, it stops before the next tab and returns a *loader.BatchError along
+// with the result.
+func (d *IOSDriver) CloseTabs(ctx context.Context, tabIDs []string) (*loader.BatchResult, error) {
 	if d.tabLoader == nil {
-		return fmt.Errorf("driver not started")
+		return nil, fmt.Errorf("driver not started")
 	}
 	
 	if d.config.Debug {
 		fmt.Fprintf(os.Stderr, "Closing %d tabs on iOS\n", len(tabIDs))
 	}
 	
-	successCount := 0
-	var failedTabs []string
-	
-	for _, tabID := range tabIDs {
-		if err := d.CloseTab(ctx, tabID); err != nil {
-			if d.config.Debug {
-				fmt.Fprintf(os.Stderr, "Failed to close iOS tab %s: %v\n", tabID, err)
-			}
-			failedTabs = append(failedTabs, tabID)
-		} else {
-			successCount++
-		}
+	// Titles and URLs for the result; closing works without them
+	tabs, err := d.LoadTabs(ctx)
+	if err != nil && d.config.Debug {
+		fmt.Fprintf(os.Stderr, "Failed to load iOS tabs before closing: %v\n", err)
 	}
 	
-	if len(failedTabs) > 0 {
-		return fmt.Errorf("partially successful: closed %d/%d tabs successfully. Failed tabs: %v", 
-			successCount, len(tabIDs), failedTabs)
+	result, err := closeTabs(ctx, tabIDs, tabs, d.CloseTab, d.config.Progress)
+	if err == nil && d.config.Debug {
+		fmt.Fprintf(os.Stderr, "Closed %d of %d iOS tabs\n", result.Succeeded, len(tabIDs))
 	}
 	
-	if d.config.Debug {
-		fmt.Fprintf(os.Stderr, "Successfully closed all %d iOS tabs\n", len(tabIDs))
-	}
-	
-	return nil
+	return result, err
 }
 
 // tabExists checks if a tab with the given ID exists (iOS)
@@ -205,7 +278,7 @@ func (d *IOSDriver) closeTabViaWebSocket(ctx context.Context, tabID string) erro
 	}
 	
 	// Execute the close command
-	if err := restorer.RestoreTabs(ctx, closeTabs); err != nil {
+	if _, err := restorer.RestoreTabs(ctx, closeTabs); err != nil {
 		return fmt.Errorf("failed to send close command to iOS tab: %w", err)
 	}
 	
@@ -14,6 +14,10 @@ type DriverConfig struct {
 	Timeout time.Duration `json:"timeout"`
 	Debug   bool          `json:"debug"`
 	File    string        `json:"file"`
+
+	// Progress, if set, is called after each tab of a batch operation
+	// (restoring or closing tabs)
+	Progress loader.ProgressFunc `json:"-"`
 }
 
 // Driver interface defines the common functionality for all drivers
@@ -21,14 +25,17 @@ type Driver interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
 	GetURL() string
-	CheckEnvironment() error
+	CheckEnvironment(ctx context.Context) error
 	LoadTabs(ctx context.Context) ([]loader.Tab, error)
 }
 
 // AndroidConfig extends DriverConfig with Android-specific options
 type AndroidConfig struct {
 	DriverConfig
+	Serial      string        `json:"serial"`
+	ADBBackend  string        `json:"adbBackend"`
 	Socket      string        `json:"socket"`
+	Browser     string        `json:"browser"`
 	Wait        time.Duration `json:"wait"`
 	SkipCleanup bool          `json:"skipCleanup"`
 }
@@ -42,5 +49,5 @@ type IOSConfig struct {
 // RestoreDriver interface for tab restoration functionality
 type RestoreDriver interface {
 	Driver
-	RestoreTabs(ctx context.Context, tabs []loader.Tab) error
+	RestoreTabs(ctx context.Context, tabs []loader.Tab) (*loader.BatchResult, error)
 }
\ No newline at end of file
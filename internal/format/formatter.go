@@ -15,6 +15,8 @@ type Format string
 const (
 	FormatJSON Format = "json"
 	FormatYAML Format = "yaml"
+	// FormatMarkdown is only supported for grouped tabs, see FormatGroups
+	FormatMarkdown Format = "markdown"
 )
 
 // TabFormatter handles formatting of tab data in different formats
@@ -83,6 +85,8 @@ func (f *TabFormatter) GetMimeType() string {
 		return "application/json"
 	case FormatYAML:
 		return "application/x-yaml"
+	case FormatMarkdown:
+		return "text/markdown"
 	default:
 		return "text/plain"
 	}
@@ -111,20 +115,30 @@ type SearchResult struct {
 
 // FormatSearchResults formats search results in the specified format
 func (f *TabFormatter) FormatSearchResults(results interface{}) (string, error) {
+	return f.FormatData(results)
+}
+
+// FormatData formats any JSON/YAML serializable value in the specified format
+func (f *TabFormatter) FormatData(data interface{}) (string, error) {
 	switch f.format {
 	case FormatJSON:
-		data, err := json.MarshalIndent(results, "", "  ")
+		out, err := json.MarshalIndent(data, "", "  ")
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal search results as JSON: %w", err)
+			return "", fmt.Errorf("failed to marshal JSON: %w", err)
 		}
-		return string(data), nil
+		return string(out), nil
 	case FormatYAML:
-		data, err := yaml.Marshal(results)
+		out, err := yaml.Marshal(data)
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal search results as YAML: %w", err)
+			return "", fmt.Errorf("failed to marshal YAML: %w", err)
 		}
-		return string(data), nil
+		return string(out), nil
 	default:
 		return "", fmt.Errorf("unsupported format: %s", f.format)
 	}
-}
\ No newline at end of file
+}
+
+// FormatDiff formats the difference between two sets of tabs in the specified format
+func (f *TabFormatter) FormatDiff(diff *loader.TabDiff) (string, error) {
+	return f.FormatData(diff)
+}
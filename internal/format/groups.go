@@ -0,0 +1,74 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// TabGroup is a named group of tabs, e.g. the tabs of one category
+type TabGroup struct {
+	Name  string `json:"name" yaml:"name"`
+	Count int    `json:"count" yaml:"count"`
+	// Left out when only the counts are listed
+	Tabs []loader.Tab `json:"tabs,omitempty" yaml:"tabs,omitempty"`
+}
+
+// ParseGroupFormat parses the format of grouped tabs, which can also be
+// markdown (or md)
+func ParseGroupFormat(formatStr string) (Format, error) {
+	switch strings.ToLower(formatStr) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	}
+	if format, err := ParseFormat(formatStr); err == nil {
+		return format, nil
+	}
+	return FormatJSON, fmt.Errorf("unsupported format: %s (supported: json, yaml, markdown)", formatStr)
+}
+
+// FormatGroups formats groups of tabs in the specified format. As Markdown
+// every group is a section listing its tabs as links with their IDs.
+func (f *TabFormatter) FormatGroups(groups []TabGroup) (string, error) {
+	if f.format != FormatMarkdown {
+		return f.FormatData(groups)
+	}
+
+	var out strings.Builder
+	for i, group := range groups {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		if len(group.Tabs) == 0 {
+			fmt.Fprintf(&out, "- **%s** (%d)", group.Name, group.Count)
+			continue
+		}
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "## %s (%d)\n\n", group.Name, group.Count)
+		for j, tab := range group.Tabs {
+			if j > 0 {
+				out.WriteString("\n")
+			}
+			fmt.Fprintf(&out, "- %s (ID: `%s`", markdownLink(tab), tab.ID)
+			if tab.Device != "" {
+				fmt.Fprintf(&out, ", device: `%s`", tab.Device)
+			}
+			out.WriteString(")")
+		}
+	}
+	return out.String(), nil
+}
+
+// markdownLink renders a tab as a Markdown link to its URL
+func markdownLink(tab loader.Tab) string {
+	title := tab.Title
+	if title == "" {
+		title = tab.URL
+	}
+	title = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "\n", " ").Replace(title)
+	target := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(tab.URL)
+	return fmt.Sprintf("[%s](%s)", title, target)
+}
@@ -0,0 +1,92 @@
+package format
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultPageSize is the number of tabs per page unless configured otherwise
+const DefaultPageSize = 50
+
+// Cursor marks where the next page of a listing starts. Listings of a fetch
+// also name the snapshot they were saved as, so that later pages are read
+// from the same tabs instead of a new fetch.
+type Cursor struct {
+	Offset   int    `json:"o"`
+	Snapshot string `json:"s,omitempty"`
+}
+
+// Encode returns the cursor as an opaque string for tool results
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a cursor returned by Encode. An empty string is the
+// start of the listing.
+func ParseCursor(s string) (Cursor, error) {
+	var cursor Cursor
+	if s == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, fmt.Errorf("invalid cursor: %s", s)
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return cursor, fmt.Errorf("invalid cursor: %s", s)
+	}
+	return cursor, nil
+}
+
+// Page describes one page of a listing
+type Page struct {
+	Start      int    `json:"start" yaml:"start"`
+	End        int    `json:"end" yaml:"end"`
+	Total      int    `json:"total" yaml:"total"`
+	NextCursor string `json:"nextCursor,omitempty" yaml:"nextCursor,omitempty"`
+}
+
+// Paginate returns the page of a listing of total items that starts at the
+// cursor and holds at most limit items. The items of the page are
+// items[page.Start:page.End]. NextCursor is empty on the last page.
+func Paginate(total int, cursor Cursor, limit int) Page {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	page := Page{Start: cursor.Offset, Total: total}
+	if page.Start > total {
+		page.Start = total
+	}
+	page.End = page.Start + limit
+	if page.End > total {
+		page.End = total
+	}
+
+	if page.End < total {
+		page.NextCursor = Cursor{Offset: page.End, Snapshot: cursor.Snapshot}.Encode()
+	}
+	return page
+}
+
+// Describe returns a short description of the page such as "tabs 1-50 of 312"
+func (p Page) Describe(noun string) string {
+	if p.Total == 0 {
+		return fmt.Sprintf("no %s", noun)
+	}
+	if p.Start == 0 && p.End == p.Total {
+		return fmt.Sprintf("all %d %s", p.Total, noun)
+	}
+	return fmt.Sprintf("%s %d-%d of %d", noun, p.Start+1, p.End, p.Total)
+}
+
+// Footer returns the hint on how to fetch the next page, or "" on the last page
+func (p Page) Footer() string {
+	if p.NextCursor == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\nMore results available. Call again with cursor=%s for the next page.", p.NextCursor)
+}
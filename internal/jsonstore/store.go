@@ -0,0 +1,273 @@
+// Package jsonstore keeps records as JSON files in a directory, one file per
+// record named after its ID, with retention limits. The snapshot history and
+// the trash of closed tabs are built on it.
+package jsonstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotExist is wrapped by the errors about records that do not exist
+var ErrNotExist = errors.New("does not exist")
+
+// Options configures a Store
+type Options struct {
+	Dir      string
+	MaxCount int           // keep at most this many records, 0 for no limit
+	MaxAge   time.Duration // delete records older than this, 0 for no limit
+}
+
+// Filter selects records in List
+type Filter struct {
+	Platform string
+	Device   string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// Header is what a Store orders, filters and prunes a record by
+type Header struct {
+	ID       string
+	Platform string
+	Device   string
+	Time     time.Time
+	// Order of records with the same time, lowest first
+	Position int
+}
+
+// Record is the pointer type of the records of a Store
+type Record[T any] interface {
+	*T
+	Header() Header
+	SetID(id string)
+}
+
+// Store keeps records of type T as JSON files in a directory
+type Store[T any, P Record[T]] struct {
+	options Options
+	// What a record is, e.g. "snapshot", for messages
+	kind string
+}
+
+// New creates a store of the given kind of records
+func New[T any, P Record[T]](options Options, kind string) *Store[T, P] {
+	return &Store[T, P]{
+		options: options,
+		kind:    kind,
+	}
+}
+
+// DefaultDir returns the directory with the given name in the user config
+// directory, used when no directory is configured
+func DefaultDir(name string) string {
+	if configDir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(configDir, "mcp-android-chrome", name)
+	}
+	return filepath.Join(os.TempDir(), "mcp-android-chrome", name)
+}
+
+// Dir returns the directory the records are stored in
+func (s *Store[T, P]) Dir() string {
+	return s.options.Dir
+}
+
+// Write stores a record atomically so a crash never leaves a truncated file
+func (s *Store[T, P]) Write(record *T) error {
+	if err := os.MkdirAll(s.options.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", s.options.Dir, err)
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", s.kind, err)
+	}
+
+	path := s.path(P(record).Header().ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.kind, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", s.kind, err)
+	}
+	return nil
+}
+
+// Read reads the record with the given ID
+func (s *Store[T, P]) Read(id string) (*T, error) {
+	if err := s.ValidateID(id); err != nil {
+		return nil, err
+	}
+	return s.read(s.path(id))
+}
+
+// List returns the records matching filter and, when set, match: most
+// recent first
+func (s *Store[T, P]) List(filter Filter, match func(*T) bool) ([]*T, error) {
+	records, err := s.loadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*T, 0, len(records))
+	for _, record := range records {
+		header := P(record).Header()
+		if filter.Platform != "" && header.Platform != filter.Platform {
+			continue
+		}
+		if filter.Device != "" && !strings.EqualFold(header.Device, filter.Device) {
+			continue
+		}
+		if !filter.Since.IsZero() && header.Time.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && header.Time.After(filter.Until) {
+			continue
+		}
+		if match != nil && !match(record) {
+			continue
+		}
+		matched = append(matched, record)
+	}
+
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+
+	return matched, nil
+}
+
+// Remove deletes the record with the given ID. The error of a missing
+// record wraps ErrNotExist.
+func (s *Store[T, P]) Remove(id string) error {
+	if err := s.ValidateID(id); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.path(id)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s '%s' %w", s.kind, id, ErrNotExist)
+		}
+		return fmt.Errorf("failed to delete %s %s: %w", s.kind, id, err)
+	}
+	return nil
+}
+
+// Prune deletes records beyond the retention limits and returns how many
+// were deleted
+func (s *Store[T, P]) Prune() (int, error) {
+	records, err := s.loadAll()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	cutoff := time.Now().Add(-s.options.MaxAge)
+	for i, record := range records {
+		header := P(record).Header()
+		expired := s.options.MaxAge > 0 && header.Time.Before(cutoff)
+		overflow := s.options.MaxCount > 0 && i >= s.options.MaxCount
+		if !expired && !overflow {
+			continue
+		}
+		if err := os.Remove(s.path(header.ID)); err != nil && !os.IsNotExist(err) {
+			return deleted, fmt.Errorf("failed to delete %s %s: %w", s.kind, header.ID, err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// loadAll reads every record, most recent first. Unreadable files are skipped.
+func (s *Store[T, P]) loadAll() ([]*T, error) {
+	files, err := os.ReadDir(s.options.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory %s: %w", s.options.Dir, err)
+	}
+
+	records := make([]*T, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		record, err := s.read(filepath.Join(s.options.Dir, file.Name()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping unreadable %s %s: %v\n", s.kind, file.Name(), err)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := P(records[i]).Header(), P(records[j]).Header()
+		if !a.Time.Equal(b.Time) {
+			return a.Time.After(b.Time)
+		}
+		return a.Position < b.Position
+	})
+
+	return records, nil
+}
+
+// read decodes a record file. The ID of the record is its file name.
+func (s *Store[T, P]) read(path string) (*T, error) {
+	id := strings.TrimSuffix(filepath.Base(path), ".json")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s '%s' %w", s.kind, id, ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", s.kind, err)
+	}
+
+	record := new(T)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.kind, err)
+	}
+	P(record).SetID(id)
+
+	return record, nil
+}
+
+// path returns the file path of the record with the given ID
+func (s *Store[T, P]) path(id string) string {
+	return filepath.Join(s.options.Dir, id+".json")
+}
+
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// NewID builds a sortable, filesystem-safe ID from a time and the given
+// parts, e.g. 20261016T053700.123Z-android-R58M123ABC. Empty parts are left out.
+func NewID(t time.Time, parts ...string) string {
+	id := t.UTC().Format("20060102T150405.000Z")
+	for _, part := range parts {
+		if part != "" {
+			id += "-" + part
+		}
+	}
+	return unsafeIDChars.ReplaceAllString(id, "_")
+}
+
+// ValidateID rejects IDs that could escape the directory of the store
+func (s *Store[T, P]) ValidateID(id string) error {
+	if id == "" || unsafeIDChars.MatchString(id) || strings.Contains(id, "..") {
+		return fmt.Errorf("invalid %s ID: %q", s.kind, id)
+	}
+	return nil
+}
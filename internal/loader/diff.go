@@ -0,0 +1,108 @@
+package loader
+
+import "fmt"
+
+// TabChange describes a tab that is open in both sets but changed
+type TabChange struct {
+	ID       string `json:"id" yaml:"id"`
+	Title    string `json:"title" yaml:"title"`
+	URL      string `json:"url" yaml:"url"`
+	OldTitle string `json:"oldTitle,omitempty" yaml:"oldTitle,omitempty"`
+	OldURL   string `json:"oldUrl,omitempty" yaml:"oldUrl,omitempty"`
+}
+
+// TabDiff is the difference between two sets of tabs
+type TabDiff struct {
+	From      string      `json:"from" yaml:"from"`
+	To        string      `json:"to" yaml:"to"`
+	Added     []Tab       `json:"added" yaml:"added"`
+	Removed   []Tab       `json:"removed" yaml:"removed"`
+	Navigated []TabChange `json:"navigated" yaml:"navigated"`
+	Retitled  []TabChange `json:"retitled" yaml:"retitled"`
+	Unchanged int         `json:"unchanged" yaml:"unchanged"`
+}
+
+// DiffTabs compares two sets of tabs. Tabs are matched by ID; a tab that is
+// open in both sets with a new URL is navigated, one with only a new title is
+// retitled. Tab IDs do not survive a browser restart, so tabs left unmatched
+// by ID are then paired up by URL and counted as unchanged.
+func DiffTabs(from, to []Tab) *TabDiff {
+	diff := &TabDiff{
+		Added:     make([]Tab, 0),
+		Removed:   make([]Tab, 0),
+		Navigated: make([]TabChange, 0),
+		Retitled:  make([]TabChange, 0),
+	}
+
+	fromByID := make(map[string]Tab, len(from))
+	for _, tab := range from {
+		fromByID[tab.ID] = tab
+	}
+
+	matched := make(map[string]bool, len(from))
+	var added []Tab
+	for _, tab := range to {
+		old, ok := fromByID[tab.ID]
+		if !ok || matched[tab.ID] {
+			added = append(added, tab)
+			continue
+		}
+		matched[tab.ID] = true
+
+		change := TabChange{ID: tab.ID, Title: tab.Title, URL: tab.URL}
+		switch {
+		case old.URL != tab.URL:
+			change.OldURL = old.URL
+			if old.Title != tab.Title {
+				change.OldTitle = old.Title
+			}
+			diff.Navigated = append(diff.Navigated, change)
+		case old.Title != tab.Title:
+			change.OldTitle = old.Title
+			diff.Retitled = append(diff.Retitled, change)
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	// Pair the remaining tabs by URL
+	removedByURL := make(map[string][]Tab)
+	var removed []Tab
+	for _, tab := range from {
+		if !matched[tab.ID] {
+			removed = append(removed, tab)
+			removedByURL[tab.URL] = append(removedByURL[tab.URL], tab)
+		}
+	}
+
+	paired := make(map[string]int)
+	for _, tab := range added {
+		if candidates := removedByURL[tab.URL]; len(candidates) > 0 {
+			removedByURL[tab.URL] = candidates[1:]
+			paired[tab.URL]++
+			diff.Unchanged++
+			continue
+		}
+		diff.Added = append(diff.Added, tab)
+	}
+	for _, tab := range removed {
+		if paired[tab.URL] > 0 {
+			paired[tab.URL]--
+			continue
+		}
+		diff.Removed = append(diff.Removed, tab)
+	}
+
+	return diff
+}
+
+// IsEmpty reports whether the two sets of tabs were the same
+func (d *TabDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Navigated) == 0 && len(d.Retitled) == 0
+}
+
+// Summary returns a one-line count of the changes
+func (d *TabDiff) Summary() string {
+	return fmt.Sprintf("%d added, %d removed, %d navigated, %d retitled, %d unchanged",
+		len(d.Added), len(d.Removed), len(d.Navigated), len(d.Retitled), d.Unchanged)
+}
@@ -63,12 +63,53 @@ func (h *HTTPTabLoader) LoadTabs(ctx context.Context) ([]Tab, error) {
 	return tabs, nil
 }
 
+// BrowserVersion is the response of the /json/version endpoint
+type BrowserVersion struct {
+	Browser              string `json:"Browser"`
+	ProtocolVersion      string `json:"Protocol-Version"`
+	UserAgent            string `json:"User-Agent"`
+	AndroidPackage       string `json:"Android-Package,omitempty"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl,omitempty"`
+}
+
+// LoadBrowserVersion retrieves browser information from the /json/version endpoint
+func LoadBrowserVersion(ctx context.Context, baseURL string, timeout time.Duration) (*BrowserVersion, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/json/version", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch browser version: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var version BrowserVersion
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &version, nil
+}
+
+// restoreDelay is the pause between restored tabs
+const restoreDelay = 100 * time.Millisecond
+
 // HTTPTabRestorer handles HTTP-based tab restoration
 type HTTPTabRestorer struct {
 	baseURL string
 	timeout time.Duration
 	debug   bool
 	client  *http.Client
+
+	// OnProgress, if set, is called after each restored tab
+	OnProgress ProgressFunc
 }
 
 // NewHTTPTabRestorer creates a new HTTP tab restorer
@@ -83,23 +124,68 @@ func NewHTTPTabRestorer(baseURL string, timeout time.Duration, debug bool) *HTTP
 	}
 }
 
-// RestoreTabs restores tabs using Chrome DevTools Protocol
-func (h *HTTPTabRestorer) RestoreTabs(ctx context.Context, tabs []Tab) error {
+// RestoreTabs restores tabs using Chrome DevTools Protocol and reports the
+// outcome of every tab. A tab that fails to open does not stop the others.
+// When ctx is cancelled or expires partway through, it stops before the next
+// tab, records the rest as skipped and returns a *BatchError with the result.
+func (h *HTTPTabRestorer) RestoreTabs(ctx context.Context, tabs []Tab) (*BatchResult, error) {
 	if h.debug {
 		fmt.Fprintf(os.Stderr, "Restoring %d tabs\n", len(tabs))
 	}
 
+	result := NewBatchResult("restore", len(tabs))
 	for i, tab := range tabs {
-		if err := h.restoreTab(ctx, tab, i); err != nil {
-			return fmt.Errorf("failed to restore tab %d (%s): %w", i, tab.Title, err)
+		if i > 0 {
+			// Small delay between tab restorations to avoid overwhelming the browser
+			select {
+			case <-ctx.Done():
+			case <-time.After(restoreDelay):
+			}
+		}
+		if ctx.Err() != nil {
+			return result, result.Stop("restoring", remainingResults(tabs[i:]), ctx.Err())
+		}
+
+		tabResult := TabResultOf(tab)
+		newID, err := h.restoreTab(ctx, tab, i)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, result.Stop("restoring", remainingResults(tabs[i:]), ctx.Err())
+			}
+			if h.debug {
+				fmt.Fprintf(os.Stderr, "Failed to restore tab %d (%s): %v\n", i+1, tab.Title, err)
+			}
+			tabResult.Status = StatusFailed
+			tabResult.Error = err.Error()
+		} else {
+			tabResult.Status = StatusRestored
+			tabResult.NewID = newID
+		}
+		result.Record(tabResult)
+
+		if h.OnProgress != nil {
+			message := "Restored " + tab.URL
+			if err != nil {
+				message = "Failed to restore " + tab.URL
+			}
+			h.OnProgress(i+1, len(tabs), message)
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
-// restoreTab restores a single tab
-func (h *HTTPTabRestorer) restoreTab(ctx context.Context, tab Tab, index int) error {
+// remainingResults returns results without a status for tabs not restored yet
+func remainingResults(tabs []Tab) []TabResult {
+	results := make([]TabResult, 0, len(tabs))
+	for _, tab := range tabs {
+		results = append(results, TabResultOf(tab))
+	}
+	return results
+}
+
+// restoreTab restores a single tab and returns the ID of the new tab
+func (h *HTTPTabRestorer) restoreTab(ctx context.Context, tab Tab, index int) (string, error) {
 	// Construct URL for creating new tab
 	createURL := fmt.Sprintf("%s/json/new?%s", h.baseURL, url.QueryEscape(tab.URL))
 	
@@ -109,21 +195,24 @@ func (h *HTTPTabRestorer) restoreTab(ctx context.Context, tab Tab, index int) er
 
 	req, err := http.NewRequestWithContext(ctx, "PUT", createURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := h.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to restore tab: %w", err)
+		return "", fmt.Errorf("failed to restore tab: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	// Small delay between tab restorations to avoid overwhelming the browser
-	time.Sleep(100 * time.Millisecond)
+	// The response describes the new tab; its ID is informational only
+	var created Tab
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil && h.debug {
+		fmt.Fprintf(os.Stderr, "Failed to decode new tab of %s: %v\n", tab.URL, err)
+	}
 
-	return nil
-}
\ No newline at end of file
+	return created.ID, nil
+}
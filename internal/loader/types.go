@@ -1,9 +1,133 @@
 package loader
 
+import (
+	"fmt"
+	"time"
+)
+
 // Tab represents a browser tab
 type Tab struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
-	URL   string `json:"url"`
-	Type  string `json:"type,omitempty"`
-}
\ No newline at end of file
+	ID                   string `json:"id" yaml:"id"`
+	Title                string `json:"title" yaml:"title"`
+	URL                  string `json:"url" yaml:"url"`
+	Type                 string `json:"type,omitempty" yaml:"type,omitempty"`
+	Description          string `json:"description,omitempty" yaml:"description,omitempty"`
+	FaviconURL           string `json:"faviconUrl,omitempty" yaml:"faviconUrl,omitempty"`
+	ParentID             string `json:"parentId,omitempty" yaml:"parentId,omitempty"`
+	DevtoolsFrontendURL  string `json:"devtoolsFrontendUrl,omitempty" yaml:"devtoolsFrontendUrl,omitempty"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl,omitempty" yaml:"webSocketDebuggerUrl,omitempty"`
+
+	// Source of the tab, set by the driver that loaded it
+	Device     string    `json:"device,omitempty" yaml:"device,omitempty"`
+	Browser    string    `json:"browser,omitempty" yaml:"browser,omitempty"`
+	CapturedAt time.Time `json:"capturedAt,omitempty" yaml:"capturedAt,omitempty"`
+}
+
+// TagTabs records the device, browser and capture time on every tab
+func TagTabs(tabs []Tab, device, browser string, capturedAt time.Time) {
+	for i := range tabs {
+		tabs[i].Device = device
+		tabs[i].Browser = browser
+		tabs[i].CapturedAt = capturedAt
+	}
+}
+
+// ProgressFunc is called after each tab of a batch operation with the number
+// of tabs done so far, the number of tabs in the batch and what was done
+type ProgressFunc func(done, total int, message string)
+
+// BatchError reports a batch operation on tabs that stopped partway through,
+// because it was cancelled or ran out of time
+type BatchError struct {
+	// What the batch does, e.g. "restoring" or "closing"
+	Op    string
+	Done  int
+	Total int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("stopped after %s %d of %d tabs: %v", e.Op, e.Done, e.Total, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Statuses of a tab in a BatchResult
+const (
+	StatusRestored = "restored"
+	StatusClosed   = "closed"
+	// The browser was asked to open the tab but does not confirm it (iOS)
+	StatusRequested = "requested"
+	StatusFailed    = "failed"
+	// Not attempted because the batch stopped early
+	StatusSkipped = "skipped"
+)
+
+// TabResult is the outcome of a batch operation for one tab
+type TabResult struct {
+	ID     string `json:"id,omitempty" yaml:"id,omitempty"`
+	URL    string `json:"url,omitempty" yaml:"url,omitempty"`
+	Title  string `json:"title,omitempty" yaml:"title,omitempty"`
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// ID of the tab a restore opened
+	NewID string `json:"newId,omitempty" yaml:"newId,omitempty"`
+}
+
+// TabResultOf returns a result for tab without a status
+func TabResultOf(tab Tab) TabResult {
+	return TabResult{ID: tab.ID, URL: tab.URL, Title: tab.Title}
+}
+
+// BatchResult reports the outcome of a batch operation on tabs per tab, so
+// that callers can retry only the tabs that failed or were skipped
+type BatchResult struct {
+	// What the batch does: "restore" or "close"
+	Operation string `json:"operation" yaml:"operation"`
+	Total     int    `json:"total" yaml:"total"`
+	Succeeded int    `json:"succeeded" yaml:"succeeded"`
+	Failed    int    `json:"failed" yaml:"failed"`
+	Skipped   int    `json:"skipped" yaml:"skipped"`
+
+	// Why the batch stopped early, if it did
+	Stopped string      `json:"stopped,omitempty" yaml:"stopped,omitempty"`
+	Tabs    []TabResult `json:"tabs" yaml:"tabs"`
+}
+
+// NewBatchResult returns an empty result of a batch of total tabs
+func NewBatchResult(operation string, total int) *BatchResult {
+	return &BatchResult{Operation: operation, Total: total, Tabs: []TabResult{}}
+}
+
+// Record adds the outcome of one tab
+func (r *BatchResult) Record(tab TabResult) {
+	r.Tabs = append(r.Tabs, tab)
+	switch tab.Status {
+	case StatusFailed:
+		r.Failed++
+	case StatusSkipped:
+		r.Skipped++
+	default:
+		r.Succeeded++
+	}
+}
+
+// Stop records the tabs a batch did not get to as skipped and returns the
+// *BatchError of the stop. op says what the batch was doing, e.g. "restoring".
+func (r *BatchResult) Stop(op string, remaining []TabResult, err error) error {
+	done := len(r.Tabs)
+	for _, tab := range remaining {
+		tab.Status = StatusSkipped
+		r.Record(tab)
+	}
+	r.Stopped = err.Error()
+	return &BatchError{Op: op, Done: done, Total: len(r.Tabs), Err: err}
+}
+
+// Complete reports whether every tab of the batch succeeded
+func (r *BatchResult) Complete() bool {
+	return r.Failed == 0 && r.Skipped == 0
+}
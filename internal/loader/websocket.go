@@ -7,9 +7,10 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/kazuph/mcp-android-chrome/internal/cdp"
 	"github.com/kazuph/mcp-android-chrome/internal/platform"
 	"github.com/kazuph/mcp-android-chrome/internal/template"
 )
@@ -28,8 +29,10 @@ func NewWebSocketTabRestorer(baseURL string, debug bool) *WebSocketTabRestorer {
 	}
 }
 
-// RestoreTabs restores tabs using WebKit Debug Protocol via WebSocket
-func (w *WebSocketTabRestorer) RestoreTabs(ctx context.Context, tabs []Tab) error {
+// RestoreTabs restores tabs using WebKit Debug Protocol via WebSocket. The
+// browser opens the tabs without confirming them, so on success every tab is
+// reported as requested.
+func (w *WebSocketTabRestorer) RestoreTabs(ctx context.Context, tabs []Tab) (*BatchResult, error) {
 	if w.debug {
 		fmt.Fprintf(os.Stderr, "Restoring %d tabs via WebSocket\n", len(tabs))
 	}
@@ -37,24 +40,30 @@ func (w *WebSocketTabRestorer) RestoreTabs(ctx context.Context, tabs []Tab) erro
 	// First, get the target page ID
 	targetPageID, err := w.getTargetPageID(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get target page ID: %w", err)
+		return nil, fmt.Errorf("failed to get target page ID: %w", err)
 	}
 
 	// Create temporary HTML file with WebSocket client
 	htmlFile, err := w.createWebSocketClient(tabs, targetPageID)
 	if err != nil {
-		return fmt.Errorf("failed to create WebSocket client: %w", err)
+		return nil, fmt.Errorf("failed to create WebSocket client: %w", err)
 	}
 
 	// Open the HTML file in browser to execute the restoration
 	if err := platform.OpenInBrowser("file://" + htmlFile); err != nil {
-		return fmt.Errorf("failed to open browser: %w", err)
+		return nil, fmt.Errorf("failed to open browser: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "WebSocket client opened in browser. Please check your iOS device for restored tabs.\n")
 	fmt.Fprintf(os.Stderr, "HTML file: %s\n", htmlFile)
 
-	return nil
+	result := NewBatchResult("restore", len(tabs))
+	for _, tab := range tabs {
+		tabResult := TabResultOf(tab)
+		tabResult.Status = StatusRequested
+		result.Record(tabResult)
+	}
+	return result, nil
 }
 
 // getTargetPageID retrieves the target page ID for WebSocket communication
@@ -114,39 +123,27 @@ type WebSocketMessage struct {
 	Params map[string]interface{} `json:"params"`
 }
 
-// Alternative direct WebSocket implementation (if needed)
+// Alternative direct implementation over a CDP session (if needed)
 func (w *WebSocketTabRestorer) restoreTabsDirect(ctx context.Context, tabs []Tab, targetPageID string) error {
-	u, err := url.Parse(w.baseURL)
+	wsURL, err := cdp.PageURL(w.baseURL, targetPageID)
 	if err != nil {
-		return fmt.Errorf("failed to parse base URL: %w", err)
+		return err
 	}
 
-	wsURL := fmt.Sprintf("ws://%s/devtools/page/%s", u.Host, targetPageID)
-	
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	session, err := cdp.Dial(ctx, wsURL, 10*time.Second, w.debug)
 	if err != nil {
-		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+		return err
 	}
-	defer conn.Close()
+	defer session.Close()
 
 	for i, tab := range tabs {
-		msg := WebSocketMessage{
-			ID:     i + 1,
-			Method: "Target.sendMessageToTarget",
-			Params: map[string]interface{}{
-				"targetId": targetPageID,
-				"message": fmt.Sprintf(`{"id":1,"method":"Runtime.evaluate","params":{"expression":"window.open('%s');"}}`, tab.URL),
-			},
-		}
-
-		if err := conn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("failed to send WebSocket message: %w", err)
+		expression := fmt.Sprintf("window.open(%s);", strconv.Quote(tab.URL))
+		params := map[string]interface{}{
+			"expression": expression,
 		}
 
-		// Read response
-		var response map[string]interface{}
-		if err := conn.ReadJSON(&response); err != nil {
-			return fmt.Errorf("failed to read WebSocket response: %w", err)
+		if err := session.Call(ctx, "Runtime.evaluate", params, nil); err != nil {
+			return fmt.Errorf("failed to restore tab %d (%s): %w", i, tab.Title, err)
 		}
 
 		if w.debug {
@@ -158,4 +155,4 @@ func (w *WebSocketTabRestorer) restoreTabsDirect(ctx context.Context, tabs []Tab
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,148 @@
+package match
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// FilterOptions holds the criteria of a TabFilter as given by the user.
+// Empty criteria are not applied.
+type FilterOptions struct {
+	// How URL, Title, Host and the exclusions are interpreted: glob or regex
+	Mode string
+
+	URL   string
+	Title string
+	Host  string
+	// The URL path must start with this, e.g. /watch
+	PathPrefix string
+	// The URL must have this query parameter: key, or key=value for a value
+	Query string
+
+	// Tabs whose URL or title matches one of these are never selected
+	ExcludeURL   []string
+	ExcludeTitle []string
+}
+
+// TabFilter selects tabs by URL, title and URL components. A tab is selected
+// when it matches every set criterion and none of the exclusions.
+type TabFilter struct {
+	url        *Pattern
+	title      *Pattern
+	host       *Pattern
+	pathPrefix string
+	queryKey   string
+	queryValue *string
+
+	excludeURL   []*Pattern
+	excludeTitle []*Pattern
+}
+
+// NewTabFilter compiles the criteria of a filter
+func NewTabFilter(options FilterOptions) (*TabFilter, error) {
+	mode, err := ParseMode(options.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &TabFilter{pathPrefix: options.PathPrefix}
+	for _, criterion := range []struct {
+		name    string
+		pattern string
+		target  **Pattern
+	}{
+		{"url", options.URL, &filter.url},
+		{"title", options.Title, &filter.title},
+		{"host", options.Host, &filter.host},
+	} {
+		if criterion.pattern == "" {
+			continue
+		}
+		if *criterion.target, err = Compile(criterion.pattern, mode); err != nil {
+			return nil, fmt.Errorf("%s filter: %w", criterion.name, err)
+		}
+	}
+
+	if filter.pathPrefix != "" && !strings.HasPrefix(filter.pathPrefix, "/") {
+		filter.pathPrefix = "/" + filter.pathPrefix
+	}
+	if options.Query != "" {
+		key, value, hasValue := strings.Cut(options.Query, "=")
+		filter.queryKey = key
+		if hasValue {
+			filter.queryValue = &value
+		}
+	}
+
+	if filter.excludeURL, err = CompileAll(options.ExcludeURL, mode); err != nil {
+		return nil, fmt.Errorf("url exclusion: %w", err)
+	}
+	if filter.excludeTitle, err = CompileAll(options.ExcludeTitle, mode); err != nil {
+		return nil, fmt.Errorf("title exclusion: %w", err)
+	}
+
+	return filter, nil
+}
+
+// HasCriteria reports whether the filter selects by anything but exclusions
+func (f *TabFilter) HasCriteria() bool {
+	return f.url != nil || f.title != nil || f.host != nil || f.pathPrefix != "" || f.queryKey != ""
+}
+
+// Match reports whether a tab matches every criterion and no exclusion
+func (f *TabFilter) Match(tab loader.Tab) bool {
+	return f.MatchCriteria(tab) && !f.Excluded(tab)
+}
+
+// MatchCriteria reports whether a tab matches every criterion, ignoring the exclusions
+func (f *TabFilter) MatchCriteria(tab loader.Tab) bool {
+	if f.url != nil && !f.url.Match(tab.URL) {
+		return false
+	}
+	if f.title != nil && !f.title.Match(tab.Title) {
+		return false
+	}
+
+	if f.host != nil || f.pathPrefix != "" || f.queryKey != "" {
+		parsed, err := url.Parse(tab.URL)
+		if err != nil {
+			return false
+		}
+		if f.host != nil && !f.host.Match(parsed.Hostname()) {
+			return false
+		}
+		if f.pathPrefix != "" && !strings.HasPrefix(parsed.EscapedPath(), f.pathPrefix) && !strings.HasPrefix(parsed.Path, f.pathPrefix) {
+			return false
+		}
+		if f.queryKey != "" {
+			values, ok := parsed.Query()[f.queryKey]
+			if !ok {
+				return false
+			}
+			if f.queryValue != nil && !slices.Contains(values, *f.queryValue) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Excluded reports whether a tab matches one of the exclusions
+func (f *TabFilter) Excluded(tab loader.Tab) bool {
+	for _, pattern := range f.excludeURL {
+		if pattern.Match(tab.URL) {
+			return true
+		}
+	}
+	for _, pattern := range f.excludeTitle {
+		if pattern.Match(tab.Title) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package match
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mode says how a pattern is interpreted
+type Mode string
+
+const (
+	// ModeGlob matches the whole text against a shell-style glob: * matches
+	// any run of characters, ? any one character, [abc] and [a-z] one of a
+	// set ([!abc] none of it), and \ escapes the next character
+	ModeGlob Mode = "glob"
+	// ModeRegex searches the text for a regular expression (RE2 syntax)
+	ModeRegex Mode = "regex"
+)
+
+// ParseMode parses a match mode; an empty string selects ModeGlob
+func ParseMode(mode string) (Mode, error) {
+	switch strings.ToLower(mode) {
+	case "", string(ModeGlob):
+		return ModeGlob, nil
+	case string(ModeRegex), "regexp":
+		return ModeRegex, nil
+	default:
+		return "", fmt.Errorf("unsupported match mode: %s (use glob or regex)", mode)
+	}
+}
+
+// Pattern is a compiled glob or regular expression. Patterns match case-insensitively.
+type Pattern struct {
+	source string
+	mode   Mode
+	re     *regexp.Regexp
+}
+
+// Compile compiles a pattern in the given mode
+func Compile(pattern string, mode Mode) (*Pattern, error) {
+	var expr string
+	switch mode {
+	case ModeGlob, "":
+		mode = ModeGlob
+		converted, err := globToRegexp(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		expr = converted
+	case ModeRegex:
+		expr = pattern
+	default:
+		return nil, fmt.Errorf("unsupported match mode: %s (use glob or regex)", mode)
+	}
+
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", mode, pattern, err)
+	}
+	return &Pattern{source: pattern, mode: mode, re: re}, nil
+}
+
+// CompileAll compiles patterns in the given mode, skipping empty ones
+func CompileAll(patterns []string, mode Mode) ([]*Pattern, error) {
+	var compiled []*Pattern
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		p, err := Compile(pattern, mode)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, p)
+	}
+	return compiled, nil
+}
+
+// Match reports whether text matches the pattern
+func (p *Pattern) Match(text string) bool {
+	return p.re.MatchString(text)
+}
+
+// String returns the pattern as written
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// globToRegexp converts a glob to an anchored regular expression
+func globToRegexp(glob string) (string, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			// Runs of stars are one star
+			for i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+			}
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 == len(runes) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			// A ] right after the opening bracket is part of the set
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return "", fmt.Errorf("unterminated character class")
+			}
+
+			class := runes[i+1 : end]
+			expr.WriteString("[")
+			if len(class) > 0 && (class[0] == '!' || class[0] == '^') {
+				expr.WriteString("^")
+				class = class[1:]
+			}
+			for _, c := range class {
+				if c == '\\' || c == '[' || c == ']' || c == '^' {
+					expr.WriteString(`\`)
+				}
+				expr.WriteRune(c)
+			}
+			expr.WriteString("]")
+			i = end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	expr.WriteString("$")
+	return expr.String(), nil
+}
@@ -0,0 +1,103 @@
+package match
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		want    string
+		wantErr string
+	}{
+		{glob: "*.google.com/*", want: `^.*\.google\.com/.*$`},
+		{glob: "a**b", want: `^a.*b$`},
+		{glob: "v?.txt", want: `^v.\.txt$`},
+		{glob: "[a-z]", want: `^[a-z]$`},
+		{glob: "[!a-z]", want: `^[^a-z]$`},
+		{glob: "[^0-9]x", want: `^[^0-9]x$`},
+		{glob: "[]a]", want: `^[\]a]$`},
+		{glob: "[!]a]", want: `^[^\]a]$`},
+		{glob: `\*`, want: `^\*$`},
+		{glob: `a\?b\[c`, want: `^a\?b\[c$`},
+		{glob: "(docs)+", want: `^\(docs\)\+$`},
+		{glob: "[abc", wantErr: "unterminated character class"},
+		{glob: "[]", wantErr: "unterminated character class"},
+		{glob: `abc\`, wantErr: "trailing backslash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			got, err := globToRegexp(tt.glob)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("globToRegexp(%q) error = %v, want %q", tt.glob, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("globToRegexp(%q) error = %v", tt.glob, err)
+			}
+			if got != tt.want {
+				t.Errorf("globToRegexp(%q) = %q, want %q", tt.glob, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		mode    Mode
+		text    string
+		want    bool
+	}{
+		{pattern: "*.google.com/*", text: "https://docs.google.com/document", want: true},
+		{pattern: "*.google.com/*", text: "https://google.com/", want: false},
+		{pattern: "*.google.com/*", text: "https://MAIL.GOOGLE.COM/inbox", want: true},
+		{pattern: "[!a-z]*", text: "1st tab", want: true},
+		{pattern: "[!a-z]*", text: "first tab", want: false},
+		{pattern: "[]x]", text: "]", want: true},
+		{pattern: "[]x]", text: "x", want: true},
+		{pattern: "[]x]", text: "y", want: false},
+		{pattern: `\*`, text: "*", want: true},
+		{pattern: `\*`, text: "anything", want: false},
+		{pattern: "github.com", text: "https://github.com/", want: false},
+		{pattern: "github", mode: ModeRegex, text: "https://GitHub.com/", want: true},
+		{pattern: "^https://github", mode: ModeRegex, text: "http://github.com/", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+" "+tt.pattern+" "+tt.text, func(t *testing.T) {
+			p, err := Compile(tt.pattern, tt.mode)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.pattern, err)
+			}
+			if got := p.Match(tt.text); got != tt.want {
+				t.Errorf("Compile(%q).Match(%q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		mode    Mode
+		wantErr string
+	}{
+		{pattern: "[a-", mode: ModeGlob, wantErr: "unterminated character class"},
+		{pattern: "(", mode: ModeRegex, wantErr: "invalid regex"},
+		{pattern: "x", mode: "fuzzy", wantErr: "unsupported match mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := Compile(tt.pattern, tt.mode)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Compile(%q, %q) error = %v, want %q", tt.pattern, tt.mode, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/adb"
+	"github.com/kazuph/mcp-android-chrome/internal/driver"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/platform"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+)
+
+// tabCacheEntry holds the cached tabs of one browser on one device
+type tabCacheEntry struct {
+	Platform    string       `json:"platform" yaml:"platform"`
+	Device      string       `json:"device" yaml:"device"`
+	Browser     string       `json:"browser,omitempty" yaml:"browser,omitempty"`
+	Tabs        []loader.Tab `json:"-" yaml:"-"`
+	TabCount    int          `json:"tabCount" yaml:"tabCount"`
+	LastUpdated time.Time    `json:"lastUpdated" yaml:"lastUpdated"`
+}
+
+// cacheKey identifies a cache entry. The browser version is left out so that
+// a browser update does not leave a stale entry behind.
+func cacheKey(platform, device, browser string) string {
+	return platform + "/" + device + "/" + browserName(browser)
+}
+
+// browserName strips the version from a browser product, e.g. Chrome/126.0 -> Chrome
+func browserName(browser string) string {
+	if i := strings.Index(browser, "/"); i >= 0 {
+		return browser[:i]
+	}
+	return browser
+}
+
+// matches reports whether the entry is selected by a device filter, which
+// names a device (ADB serial or iOS device name), a platform or a browser
+func (e *tabCacheEntry) matches(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	return strings.EqualFold(e.Device, filter) ||
+		strings.EqualFold(e.Platform, filter) ||
+		strings.EqualFold(browserName(e.Browser), filter)
+}
+
+// storeTabs replaces the cached tabs of one browser on one device and returns
+// the changes since the previous contents, and whether the entry is new
+func (s *TabTransferServer) storeTabs(platform, device, browser string, tabs []loader.Tab) (*loader.TabDiff, bool) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
+	key := cacheKey(platform, device, browser)
+	previous, existed := s.tabCache[key]
+	var previousTabs []loader.Tab
+	if existed {
+		previousTabs = previous.Tabs
+	}
+
+	s.tabCache[key] = &tabCacheEntry{
+		Platform:    platform,
+		Device:      device,
+		Browser:     browser,
+		Tabs:        tabs,
+		TabCount:    len(tabs),
+		LastUpdated: time.Now(),
+	}
+	s.searchIndex.Update(key, tabs)
+
+	return loader.DiffTabs(previousTabs, tabs), !existed
+}
+
+// cacheEntries returns copies of the cache entries selected by the device
+// filter, ordered by platform, device and browser
+func (s *TabTransferServer) cacheEntries(filter string) []tabCacheEntry {
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+
+	keys := make([]string, 0, len(s.tabCache))
+	for key := range s.tabCache {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]tabCacheEntry, 0, len(keys))
+	for _, key := range keys {
+		if entry := s.tabCache[key]; entry.matches(filter) {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// cachedTabs returns the merged cached tabs of the devices selected by the
+// device filter. Every tab carries its device and browser.
+func (s *TabTransferServer) cachedTabs(filter string) []loader.Tab {
+	var tabs []loader.Tab
+	for _, entry := range s.cacheEntries(filter) {
+		tabs = append(tabs, entry.Tabs...)
+	}
+	if tabs == nil {
+		tabs = make([]loader.Tab, 0)
+	}
+	return tabs
+}
+
+// cacheLastUpdated returns when any cache entry was last updated
+func (s *TabTransferServer) cacheLastUpdated() time.Time {
+	var last time.Time
+	for _, entry := range s.cacheEntries("") {
+		if entry.LastUpdated.After(last) {
+			last = entry.LastUpdated
+		}
+	}
+	return last
+}
+
+// deviceRefresh is the outcome of refreshing the cached tabs of one device
+type deviceRefresh struct {
+	Platform string
+	Device   string
+	Diff     *loader.TabDiff
+}
+
+// String describes the refresh for tool results and logs
+func (r deviceRefresh) String() string {
+	return fmt.Sprintf("%s %s: %s", r.Platform, r.Device, r.Diff.Summary())
+}
+
+// refreshAllTabCaches refreshes the cache from every attached Android device
+// and, when ios_webkit_debug_proxy is installed, from the iOS device. It
+// returns the devices that were refreshed; devices that could not be read
+// keep their previous tabs and are reported in the error. Background
+// refreshes leave out the iOS device (see fetchAndCacheIOSTabs).
+func (s *TabTransferServer) refreshAllTabCaches(background bool) ([]deviceRefresh, error) {
+	var refreshed []deviceRefresh
+	var errs []error
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	backend, err := adb.NewBackend(s.config.Defaults.ADBBackend, 10*time.Second, false)
+	var devices []platform.ADBDevice
+	if err == nil {
+		devices, err = backend.Devices(ctx)
+	}
+	cancel()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("android: %w", err))
+	}
+
+	for _, device := range devices {
+		if device.State != "device" {
+			continue
+		}
+		diff, err := s.fetchAndCacheAndroidTabs(device.Serial, background)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("android %s: %w", device.Serial, err))
+			continue
+		}
+		refreshed = append(refreshed, deviceRefresh{Platform: "android", Device: device.Serial, Diff: diff})
+	}
+
+	if !background && platform.CheckIOSWebKitDebugProxyAvailable() == nil {
+		device, diff, err := s.fetchAndCacheIOSTabs()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ios: %w", err))
+		} else {
+			refreshed = append(refreshed, deviceRefresh{Platform: "ios", Device: device, Diff: diff})
+		}
+	}
+
+	return refreshed, errors.Join(errs...)
+}
+
+// fetchAndCacheIOSTabs fetches tabs from the iOS device of the default iOS
+// profile (the proxy's first device port), updates its cache entry and saves
+// a snapshot. It is not used for background refreshes: the proxy it starts
+// listens on the ports from 9221 up, which tool calls need.
+func (s *TabTransferServer) fetchAndCacheIOSTabs() (string, *loader.TabDiff, error) {
+	profile, err := s.config.Resolve("", "ios")
+	if err != nil {
+		return "", nil, err
+	}
+	iosDriver := driver.NewIOSDriver(profile.IOSConfig(false)) // Don't spam logs during auto-fetch
+
+	ctx, cancel := context.WithTimeout(context.Background(), profile.TimeoutDuration()+5*time.Second)
+	defer cancel()
+
+	if err := iosDriver.Start(ctx); err != nil {
+		return "", nil, fmt.Errorf("failed to start iOS driver: %w", err)
+	}
+	defer iosDriver.Stop(ctx)
+
+	tabs, err := iosDriver.LoadTabs(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load tabs: %w", err)
+	}
+
+	diff, added := s.storeTabs("ios", iosDriver.Device(), iosDriver.Browser(), tabs)
+	s.cacheChanged("ios", iosDriver.Device(), tabs, diff, added, false)
+
+	return iosDriver.Device(), diff, nil
+}
+
+// cacheChanged saves a snapshot and notifies clients after a cache entry was
+// updated. Background refreshes only do so when the tabs changed. It returns
+// the saved snapshot, if any.
+func (s *TabTransferServer) cacheChanged(platform, device string, tabs []loader.Tab, diff *loader.TabDiff, added, background bool) *snapshot.Meta {
+	changed := added || !diff.IsEmpty()
+
+	var meta *snapshot.Meta
+	if !background || changed {
+		meta = s.saveSnapshot(platform, tabs)
+	}
+
+	if changed {
+		// Expose per-tab and per-device resources for the new cache contents
+		s.syncTabResources()
+		s.notifyResourceUpdated("tabs://current")
+		s.notifyResourceUpdated(deviceResourceURI(device))
+	}
+
+	return meta
+}
+
+// deviceResourceURI returns the resource URI of the cached tabs of one device
+func deviceResourceURI(device string) string {
+	return "tabs://device/" + url.PathEscape(device)
+}
+
+// deviceTabsResource returns the handler of a device's tabs resource
+func (s *TabTransferServer) deviceTabsResource(device string) func() (*mcp_golang.ResourceResponse, error) {
+	return func() (*mcp_golang.ResourceResponse, error) {
+		formatter := format.YAMLFormatter()
+		tabsData, err := formatter.FormatTabs(s.cachedTabs(device))
+		if err != nil {
+			return nil, fmt.Errorf("failed to format cached tabs as YAML: %w", err)
+		}
+
+		resource := mcp_golang.NewTextEmbeddedResource(deviceResourceURI(device), tabsData, formatter.GetMimeType())
+		return mcp_golang.NewResourceResponse(resource), nil
+	}
+}
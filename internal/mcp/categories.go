@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/category"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/query"
+)
+
+// CategorizeTabsArgs represents arguments for grouping the cached tabs by category
+type CategorizeTabsArgs struct {
+	Device   string `json:"device" jsonschema:"description=Only tabs of this device: ADB serial or iOS device name or android or ios (default: all cached devices)"`
+	Query    string `json:"query" jsonschema:"description=Only tabs matching this search query in the syntax of search_tabs"`
+	Category string `json:"category" jsonschema:"description=Only list this category (e.g. Docs)"`
+	Summary  bool   `json:"summary" jsonschema:"description=Only count the tabs per category (default: false)"`
+	Format   string `json:"format" jsonschema:"description=Output format: json or yaml or markdown (default: the configured format or json)"`
+}
+
+// categorizer loads the category rules file, so that edits apply without a restart
+func (s *TabTransferServer) categorizer() (*category.Categorizer, error) {
+	return category.Load(s.config.CategoryRulesFile())
+}
+
+// categorizeTabs implements the tab categorization tool
+func (s *TabTransferServer) categorizeTabs(args CategorizeTabsArgs) (*mcp_golang.ToolResponse, error) {
+	q, err := query.Parse(args.Query)
+	if err != nil {
+		return nil, err
+	}
+	categorizer, err := s.categorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	cachedTabs := s.cachedTabs(args.Device)
+	if len(cachedTabs) == 0 {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("No tabs are currently cached. Use refresh_tab_cache tool to populate cache first.")), nil
+	}
+
+	groups := categorizer.Group(q.Filter(cachedTabs))
+	if args.Category != "" {
+		var selected []format.TabGroup
+		for _, group := range groups {
+			if strings.EqualFold(group.Name, args.Category) {
+				selected = append(selected, group)
+			}
+		}
+		if len(selected) == 0 {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("No cached tabs in category %s.", args.Category))), nil
+		}
+		groups = selected
+	}
+	if args.Summary {
+		for i := range groups {
+			groups[i].Tabs = nil
+		}
+	}
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if args.Format != "" {
+		if parsedFormat, err := format.ParseGroupFormat(args.Format); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	total := 0
+	for _, group := range groups {
+		total += group.Count
+	}
+
+	formattedGroups, err := format.NewTabFormatter(outputFormat).FormatGroups(groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format tab categories: %w", err)
+	}
+
+	rulesNote := fmt.Sprintf("%d rules from %s and the built-in heuristic", categorizer.UserRules(), s.config.CategoryRulesFile())
+	if categorizer.UserRules() == 0 {
+		rulesNote = "the built-in heuristic; add rules to " + s.config.CategoryRulesFile() + " to choose your own categories"
+	}
+	result := fmt.Sprintf("🗂️ %d tabs in %d categories (format: %s):\n\n%s\n\nCategories come from %s.", total, len(groups), outputFormat, formattedGroups, rulesNote)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
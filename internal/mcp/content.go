@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/content"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+)
+
+// defaultContentLimit caps extracted page content so one tab cannot flood the context
+const defaultContentLimit = 20000
+
+// GetTabContentArgs represents arguments for extracting a tab's content
+type GetTabContentArgs struct {
+	TabId     string `json:"tabId" jsonschema:"required,description=Unique tab ID to read"`
+	Mode      string `json:"mode" jsonschema:"description=article (main content as Markdown) or text or html (default: article)"`
+	MaxLength int    `json:"maxLength" jsonschema:"description=Maximum number of characters to return (default: 20000 or -1 for no limit)"`
+	Serial    string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Format    string `json:"format" jsonschema:"description=Output format: text or json or yaml (default: text)"`
+	Call      string `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// getTabContent implements the tab content extraction tool
+func (s *TabTransferServer) getTabContent(args GetTabContentArgs) (*mcp_golang.ToolResponse, error) {
+	if args.TabId == "" {
+		return nil, fmt.Errorf("tabId is required")
+	}
+
+	mode, err := content.ParseMode(args.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := args.MaxLength
+	if limit == 0 {
+		limit = defaultContentLimit
+	}
+
+	page, err := s.readTabContent(s.lookupCall(args.Call).Context(), args.Serial, args.TabId, mode, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plain text output keeps the content readable without escaping
+	if args.Format == "" || args.Format == "text" {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(contentText(page, limit))), nil
+	}
+
+	outputFormat, err := format.ParseFormat(args.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	formattedPage, err := format.NewTabFormatter(outputFormat).FormatData(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format tab content: %w", err)
+	}
+
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(formattedPage)), nil
+}
+
+// readTabContent starts an Android driver and extracts a tab's content,
+// truncated to limit characters (negative for no limit)
+func (s *TabTransferServer) readTabContent(parent context.Context, serial, tabID string, mode content.Mode, limit int) (*content.Page, error) {
+	ctx, cancel, err := s.deviceContext(parent, serial, "android")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	androidDriver, err := s.startAndroidDriver(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+	defer androidDriver.Stop(context.WithoutCancel(ctx))
+
+	page, err := androidDriver.TabContent(ctx, tabID, mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tab content: %w", err)
+	}
+	if mode != content.ModeHTML {
+		// Make the tab findable by its text in search_tabs
+		s.searchIndex.SetText(androidDriver.Device(), tabID, page.Content)
+	}
+	page.Content, page.Truncated = content.Truncate(page.Content, limit)
+
+	return page, nil
+}
+
+// contentText returns extracted content as plain text with a header naming
+// the page, the mode and the length
+func contentText(page *content.Page, limit int) string {
+	var header string
+	if page.Truncated {
+		header = fmt.Sprintf("📄 %s\nURL: %s\nMode: %s (truncated to %d of %d characters)\n\n", page.Title, page.URL, page.Mode, limit, page.Length)
+	} else {
+		header = fmt.Sprintf("📄 %s\nURL: %s\nMode: %s (%d characters)\n\n", page.Title, page.URL, page.Mode, page.Length)
+	}
+	return header + page.Content
+}
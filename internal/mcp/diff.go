@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/driver"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// DiffTabsArgs represents arguments for comparing two sets of tabs
+type DiffTabsArgs struct {
+	From     string `json:"from" jsonschema:"description=Tabs to compare from: cache or live or latest or a snapshot ID or a tabs file path (default: cache)"`
+	To       string `json:"to" jsonschema:"description=Tabs to compare to: cache or live or latest or a snapshot ID or a tabs file path (default: live)"`
+	Platform string `json:"platform" jsonschema:"description=Platform for live tabs: android or ios (default: android)"`
+	Serial   string `json:"serial" jsonschema:"description=ADB device serial or profile name for live tabs (default: the default profile or the single USB-attached device)"`
+	Format   string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+	Call     string `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// diffTabs implements the tab diff tool
+func (s *TabTransferServer) diffTabs(args DiffTabsArgs) (*mcp_golang.ToolResponse, error) {
+	if args.From == "" {
+		args.From = "cache"
+	}
+	if args.To == "" {
+		args.To = "live"
+	}
+	if args.Platform == "" {
+		args.Platform = "android"
+	}
+
+	// Live tabs are loaded within the operation timeout of the device profile
+	ctx := s.lookupCall(args.Call).Context()
+
+	fromTabs, err := s.resolveTabs(ctx, args.From, args.Platform, args.Serial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load '%s' tabs: %w", args.From, err)
+	}
+	toTabs, err := s.resolveTabs(ctx, args.To, args.Platform, args.Serial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load '%s' tabs: %w", args.To, err)
+	}
+
+	diff := loader.DiffTabs(fromTabs, toTabs)
+	diff.From = args.From
+	diff.To = args.To
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if args.Format != "" {
+		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	formattedDiff, err := format.NewTabFormatter(outputFormat).FormatDiff(diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format tab diff: %w", err)
+	}
+
+	result := fmt.Sprintf("🔀 Tab changes from %s to %s: %s (format: %s):\n\n%s", args.From, args.To, diff.Summary(), outputFormat, formattedDiff)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
+
+// resolveTabs loads the tabs named by source: the cached tabs of the device,
+// the live device, a snapshot ID or "latest", or a tabs file path
+func (s *TabTransferServer) resolveTabs(ctx context.Context, source, platform, serial string) ([]loader.Tab, error) {
+	switch source {
+	case "cache":
+		// Compare against the cached tabs of the same device only
+		filter := serial
+		if filter == "" {
+			filter = platform
+		}
+		if len(s.cacheEntries(filter)) == 0 {
+			return nil, fmt.Errorf("no cached tabs for %s - use refresh_tab_cache first", filter)
+		}
+		return s.cachedTabs(filter), nil
+
+	case "live":
+		return s.loadLiveTabs(ctx, platform, serial)
+
+	default:
+		return s.snapshots.LoadTabs(source)
+	}
+}
+
+// loadLiveTabs fetches the tabs currently open on the device and records
+// them in the snapshot history
+func (s *TabTransferServer) loadLiveTabs(parent context.Context, platform, serial string) ([]loader.Tab, error) {
+	ctx, cancel, err := s.deviceContext(parent, serial, platform)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var tabs []loader.Tab
+
+	switch platform {
+	case "android":
+		androidDriver, startErr := s.startAndroidDriver(ctx, serial)
+		if startErr != nil {
+			return nil, startErr
+		}
+		defer androidDriver.Stop(context.WithoutCancel(ctx))
+
+		tabs, err = androidDriver.LoadTabs(ctx)
+
+	case "ios":
+		profile, resolveErr := s.config.Resolve(serial, "ios")
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+		iosDriver := driver.NewIOSDriver(profile.IOSConfig(false))
+		if startErr := iosDriver.Start(ctx); startErr != nil {
+			return nil, fmt.Errorf("failed to start iOS driver: %w", startErr)
+		}
+		defer iosDriver.Stop(context.WithoutCancel(ctx))
+
+		tabs, err = iosDriver.LoadTabs(ctx)
+
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s (use 'android' or 'ios')", platform)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tabs: %w", err)
+	}
+	s.saveSnapshot(platform, tabs)
+
+	return tabs, nil
+}
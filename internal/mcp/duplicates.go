@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/dedupe"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+)
+
+// FindDuplicateTabsArgs represents arguments for finding duplicate tabs
+type FindDuplicateTabsArgs struct {
+	Device          string  `json:"device" jsonschema:"description=Only tabs of this device: ADB serial or iOS device name or android or ios (default: all cached devices)"`
+	ByTitle         bool    `json:"byTitle" jsonschema:"description=Also group tabs of one site whose titles are similar (default: false)"`
+	TitleSimilarity float64 `json:"titleSimilarity" jsonschema:"description=Share of common title words from 0 to 1 for byTitle (default: 0.8)"`
+	Close           bool    `json:"close" jsonschema:"description=Close the duplicates through close_tabs_bulk keeping one tab per group (a dry run unless confirm is true)"`
+	Confirm         bool    `json:"confirm" jsonschema:"description=With close: actually close the duplicates (default: false)"`
+	Format          string  `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+	Call            string  `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// findDuplicateTabs implements the duplicate tabs tool
+func (s *TabTransferServer) findDuplicateTabs(args FindDuplicateTabsArgs) (*mcp_golang.ToolResponse, error) {
+	entries := s.cacheEntries(args.Device)
+	if len(entries) == 0 {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("No tabs are currently cached. Use refresh_tab_cache or copy_tabs_android/copy_tabs_ios first.")), nil
+	}
+
+	report := dedupe.Find(s.cachedTabs(args.Device), dedupe.Options{
+		ByTitle:         args.ByTitle,
+		TitleSimilarity: args.TitleSimilarity,
+	})
+	if len(report.Groups) == 0 {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("✅ No duplicate tabs among %d cached tabs.", report.Tabs))), nil
+	}
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if args.Format != "" {
+		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	formattedReport, err := format.NewTabFormatter(outputFormat).FormatData(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format duplicate tabs: %w", err)
+	}
+
+	result := fmt.Sprintf("🔁 Found %d duplicate tabs in %d groups among %d cached tabs (format: %s):\n\n%s", report.Duplicates, len(report.Groups), report.Tabs, outputFormat, formattedReport)
+	if !args.Close {
+		result += "\n\nThe keep tab of every group is the suggested one to keep. Call this tool again with close=true to preview closing the others."
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+	}
+
+	// Platform of every cached device
+	platforms := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		platforms[entry.Device] = entry.Platform
+	}
+
+	// Close the duplicates device by device, as a dry run unless confirmed
+	response := mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result))
+	closeIDs := report.CloseIDs()
+	devices := make([]string, 0, len(closeIDs))
+	for device := range closeIDs {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+	for _, device := range devices {
+		tabIDs := closeIDs[device]
+		bulkArgs := CloseTabsBulkArgs{
+			TabIds:   tabIDs,
+			Platform: platforms[device],
+			DryRun:   !args.Confirm,
+			Confirm:  args.Confirm,
+			Format:   args.Format,
+			Call:     args.Call,
+		}
+		if bulkArgs.Platform == "android" {
+			bulkArgs.Serial = device
+		}
+
+		closed, err := s.closeTabsBulk(bulkArgs)
+		if err != nil {
+			response.Content = append(response.Content, mcp_golang.NewTextContent(fmt.Sprintf("❌ Failed to close duplicates on %s: %v", device, err)))
+			continue
+		}
+		response.Content = append(response.Content, closed.Content...)
+	}
+	if !args.Confirm {
+		response.Content = append(response.Content, mcp_golang.NewTextContent("To actually close the duplicates, call this tool again with close=true and confirm=true."))
+	}
+	return response, nil
+}
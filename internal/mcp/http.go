@@ -0,0 +1,769 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// Limits of the HTTP transports
+const (
+	maxMessageSize     = 4 << 20
+	streamBufferSize   = 64
+	sessionIdleTimeout = time.Hour
+	readHeaderTimeout  = 10 * time.Second
+)
+
+// HTTPOptions configures serving MCP over HTTP
+type HTTPOptions struct {
+	// Transport is "http" for the streamable HTTP transport (one /mcp
+	// endpoint) or "sse" for the HTTP+SSE transport (/sse and /messages)
+	Transport string
+	// Address to listen on, e.g. 127.0.0.1:8765
+	Address string
+	// Token required as "Authorization: Bearer <token>", none when empty
+	Token string
+}
+
+// StartHTTP serves MCP over HTTP. Every client gets its own session; the tab
+// cache is shared and stays warm between sessions.
+func (s *TabTransferServer) StartHTTP(options HTTPOptions) error {
+	handler := &httpHandler{
+		server:   s,
+		sessions: make(map[string]*httpSession),
+	}
+
+	mux := http.NewServeMux()
+	var endpoint string
+	switch options.Transport {
+	case "http":
+		endpoint = "/mcp"
+		mux.HandleFunc(endpoint, handler.handleStreamable)
+	case "sse":
+		endpoint = "/sse"
+		mux.HandleFunc(endpoint, handler.handleSSE)
+		mux.HandleFunc("/messages", handler.handleSSEMessage)
+	default:
+		return fmt.Errorf("unsupported transport: %s (use 'stdio', 'http' or 'sse')", options.Transport)
+	}
+
+	if options.Token == "" && !isLoopback(options.Address) {
+		fmt.Fprintf(os.Stderr, "Warning: serving MCP on %s without a token; anyone on the network can control the device\n", options.Address)
+	}
+
+	// Auto-populate tab cache on startup and keep it current (non-blocking)
+	s.startTabCache()
+	go handler.expireIdleSessions()
+
+	fmt.Fprintf(os.Stderr, "Serving MCP (%s transport) on http://%s%s\n", options.Transport, options.Address, endpoint)
+	httpServer := &http.Server{
+		Addr:              options.Address,
+		Handler:           requireToken(options.Token, mux),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	return httpServer.ListenAndServe()
+}
+
+// requireToken rejects requests without the bearer token, if one is set
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-android-chrome"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback reports whether an address only accepts local connections
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// httpSession is the session of one HTTP client
+type httpSession struct {
+	transport *httpTransport
+	session   *session
+}
+
+// httpHandler serves the HTTP transports and keeps track of their sessions
+type httpHandler struct {
+	server   *TabTransferServer
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// openSession starts a new session for an HTTP client
+func (h *httpHandler) openSession(streaming bool) (*httpSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	tr := newHTTPTransport(id, streaming)
+	sess, err := h.server.newSession(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	httpSess := &httpSession{transport: tr, session: sess}
+	h.mu.Lock()
+	h.sessions[id] = httpSess
+	h.mu.Unlock()
+	return httpSess, nil
+}
+
+// lookupSession returns the session with the given ID, or nil
+func (h *httpHandler) lookupSession(id string) *httpSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	httpSess := h.sessions[id]
+	if httpSess != nil {
+		httpSess.transport.touch()
+	}
+	return httpSess
+}
+
+// closeSession ends a session and forgets it
+func (h *httpHandler) closeSession(id string) {
+	h.mu.Lock()
+	httpSess := h.sessions[id]
+	delete(h.sessions, id)
+	h.mu.Unlock()
+
+	if httpSess == nil {
+		return
+	}
+	httpSess.transport.Close()
+	h.server.closeSession(httpSess.session)
+}
+
+// expireIdleSessions closes sessions of clients that went away without
+// ending their session
+func (h *httpHandler) expireIdleSessions() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		var idle []string
+		h.mu.Lock()
+		for id, httpSess := range h.sessions {
+			if httpSess.transport.idleSince(sessionIdleTimeout) {
+				idle = append(idle, id)
+			}
+		}
+		h.mu.Unlock()
+
+		for _, id := range idle {
+			h.closeSession(id)
+		}
+	}
+}
+
+// handleStreamable serves the streamable HTTP transport: clients POST
+// JSON-RPC messages and get the responses in the HTTP response, GET opens an
+// event stream for notifications and DELETE ends the session
+func (h *httpHandler) handleStreamable(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.handleStreamablePost(w, r)
+
+	case http.MethodGet:
+		httpSess := h.lookupSession(r.Header.Get("Mcp-Session-Id"))
+		if httpSess == nil {
+			http.Error(w, "unknown or missing Mcp-Session-Id", http.StatusNotFound)
+			return
+		}
+		h.stream(w, r, httpSess.transport, "")
+
+	case http.MethodDelete:
+		id := r.Header.Get("Mcp-Session-Id")
+		if h.lookupSession(id) == nil {
+			http.Error(w, "unknown or missing Mcp-Session-Id", http.StatusNotFound)
+			return
+		}
+		h.closeSession(id)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleStreamablePost delivers posted messages and answers with the
+// responses to the posted requests. When a request carries a progress token
+// the answer is an event stream with its progress notifications followed by
+// the responses, so that clients without a GET stream still see progress.
+func (h *httpHandler) handleStreamablePost(w http.ResponseWriter, r *http.Request) {
+	messages, batch, err := readMessages(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var httpSess *httpSession
+	if id := r.Header.Get("Mcp-Session-Id"); id != "" {
+		httpSess = h.lookupSession(id)
+		if httpSess == nil {
+			http.Error(w, "unknown Mcp-Session-Id", http.StatusNotFound)
+			return
+		}
+	} else {
+		if !hasInitialize(messages) {
+			http.Error(w, "missing Mcp-Session-Id", http.StatusBadRequest)
+			return
+		}
+		httpSess, err = h.openSession(false)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Mcp-Session-Id", httpSess.transport.id)
+	}
+
+	// Wait for the responses to the requests, in order
+	var (
+		waits  []chan *transport.BaseJsonRpcMessage
+		tokens []string
+	)
+	for _, message := range messages {
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType {
+			waits = append(waits, httpSess.transport.await(message.JsonRpcRequest.Id))
+			if token, ok := progressToken(message.JsonRpcRequest); ok {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	flusher, canFlush := w.(http.Flusher)
+	if len(tokens) > 0 && canFlush {
+		progress := httpSess.transport.routeProgress(tokens)
+		defer httpSess.transport.unrouteProgress(tokens)
+
+		for _, message := range messages {
+			httpSess.transport.deliver(message)
+		}
+		h.streamResponses(w, r, flusher, waits, progress)
+		return
+	}
+
+	for _, message := range messages {
+		httpSess.transport.deliver(message)
+	}
+
+	if len(waits) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	responses := make([]*transport.BaseJsonRpcMessage, 0, len(waits))
+	for _, wait := range waits {
+		select {
+		case response := <-wait:
+			responses = append(responses, response)
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	var body any = responses[0]
+	if batch {
+		body = responses
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// streamResponses answers a POST with an event stream: the progress
+// notifications of its requests as they are sent, then every response
+func (h *httpHandler) streamResponses(w http.ResponseWriter, r *http.Request, flusher http.Flusher, waits []chan *transport.BaseJsonRpcMessage, progress <-chan *transport.BaseJsonRpcMessage) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for _, wait := range waits {
+		waiting := true
+		for waiting {
+			select {
+			case response := <-wait:
+				// Progress sent before the response goes first
+				for queued := true; queued; {
+					select {
+					case notification := <-progress:
+						writeEvent(w, flusher, notification)
+					default:
+						queued = false
+					}
+				}
+				writeEvent(w, flusher, response)
+				waiting = false
+			case notification := <-progress:
+				writeEvent(w, flusher, notification)
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes a message as a server-sent event
+func writeEvent(w io.Writer, flusher http.Flusher, message *transport.BaseJsonRpcMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to marshal message: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+	flusher.Flush()
+}
+
+// handleSSE serves the event stream of the HTTP+SSE transport. The first
+// event names the endpoint the client posts its messages to; responses and
+// notifications follow as message events.
+func (h *httpHandler) handleSSE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	httpSess, err := h.openSession(true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer h.closeSession(httpSess.transport.id)
+
+	h.stream(w, r, httpSess.transport, "/messages?sessionId="+httpSess.transport.id)
+}
+
+// handleSSEMessage delivers a message posted by an HTTP+SSE client
+func (h *httpHandler) handleSSEMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	httpSess := h.lookupSession(r.URL.Query().Get("sessionId"))
+	if httpSess == nil {
+		http.Error(w, "unknown or missing sessionId", http.StatusNotFound)
+		return
+	}
+
+	messages, _, err := readMessages(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, message := range messages {
+		httpSess.transport.deliver(message)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// stream writes the messages the server sends on a session as server-sent
+// events until the client disconnects. A non-empty endpoint is announced
+// first.
+func (h *httpHandler) stream(w http.ResponseWriter, r *http.Request, tr *httpTransport, endpoint string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	messages, err := tr.attachStream()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	defer tr.detachStream()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if endpoint != "" {
+		fmt.Fprintf(w, "event: endpoint\ndata: %s\n\n", endpoint)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case message := <-messages:
+			writeEvent(w, flusher, message)
+			tr.touch()
+		case <-r.Context().Done():
+			return
+		case <-tr.done:
+			return
+		}
+	}
+}
+
+// readMessages decodes the JSON-RPC message or batch of messages in a request body
+func readMessages(r *http.Request) ([]*transport.BaseJsonRpcMessage, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	body = bytes.TrimSpace(body)
+	batch := len(body) > 0 && body[0] == '['
+
+	var raws []json.RawMessage
+	if batch {
+		if err := json.Unmarshal(body, &raws); err != nil {
+			return nil, false, fmt.Errorf("invalid JSON-RPC batch: %w", err)
+		}
+	} else {
+		raws = []json.RawMessage{body}
+	}
+	if len(raws) == 0 {
+		return nil, false, errors.New("empty JSON-RPC batch")
+	}
+
+	messages := make([]*transport.BaseJsonRpcMessage, 0, len(raws))
+	for _, raw := range raws {
+		message, err := decodeMessage(raw)
+		if err != nil {
+			return nil, false, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, batch, nil
+}
+
+// decodeMessage decodes a JSON-RPC request, notification, response or error,
+// like the stdio transport of mcp-golang does
+func decodeMessage(data []byte) (*transport.BaseJsonRpcMessage, error) {
+	var request transport.BaseJSONRPCRequest
+	if err := json.Unmarshal(data, &request); err == nil {
+		return transport.NewBaseMessageRequest(&request), nil
+	}
+
+	var notification transport.BaseJSONRPCNotification
+	if err := json.Unmarshal(data, &notification); err == nil {
+		return transport.NewBaseMessageNotification(&notification), nil
+	}
+
+	var response transport.BaseJSONRPCResponse
+	if err := json.Unmarshal(data, &response); err == nil {
+		return transport.NewBaseMessageResponse(&response), nil
+	}
+
+	var errorResponse transport.BaseJSONRPCError
+	if err := json.Unmarshal(data, &errorResponse); err == nil {
+		return transport.NewBaseMessageError(&errorResponse), nil
+	}
+
+	return nil, errors.New("failed to unmarshal JSON-RPC message, unrecognized type")
+}
+
+// hasInitialize reports whether the messages contain an initialize request
+func hasInitialize(messages []*transport.BaseJsonRpcMessage) bool {
+	for _, message := range messages {
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType && message.JsonRpcRequest.Method == "initialize" {
+			return true
+		}
+	}
+	return false
+}
+
+// progressToken returns the progress token of a request, compacted so that
+// it can be compared with the token of a progress notification
+func progressToken(request *transport.BaseJSONRPCRequest) (string, bool) {
+	var params struct {
+		Meta struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil || len(params.Meta.ProgressToken) == 0 {
+		return "", false
+	}
+	return compactJSON(params.Meta.ProgressToken)
+}
+
+// notificationProgressToken returns the progress token of a
+// notifications/progress message
+func notificationProgressToken(message *transport.BaseJsonRpcMessage) (string, bool) {
+	if message.Type != transport.BaseMessageTypeJSONRPCNotificationType || message.JsonRpcNotification.Method != "notifications/progress" {
+		return "", false
+	}
+	var params struct {
+		ProgressToken json.RawMessage `json:"progressToken"`
+	}
+	if err := json.Unmarshal(message.JsonRpcNotification.Params, &params); err != nil || len(params.ProgressToken) == 0 {
+		return "", false
+	}
+	return compactJSON(params.ProgressToken)
+}
+
+// compactJSON returns raw JSON without insignificant whitespace
+func compactJSON(raw json.RawMessage) (string, bool) {
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, raw); err != nil {
+		return "", false
+	}
+	return buffer.String(), true
+}
+
+// newSessionID returns a random session ID
+func newSessionID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to create session ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// httpTransport is the transport of one HTTP session. Responses to requests
+// posted with the streamable HTTP transport go back in the POST response, as
+// do the progress notifications of requests answered with an event stream;
+// everything else goes to the session's event stream. Messages sent while a
+// streamable HTTP client has no event stream open are dropped.
+type httpTransport struct {
+	id string
+
+	mu        sync.Mutex
+	onMessage func(message *transport.BaseJsonRpcMessage)
+	onClose   func()
+	onError   func(error)
+	waiters   map[transport.RequestId]chan *transport.BaseJsonRpcMessage
+	streaming bool
+	attached  bool
+	dropping  bool
+	lastUsed  time.Time
+	closed    bool
+	progress  map[string]chan *transport.BaseJsonRpcMessage
+
+	stream chan *transport.BaseJsonRpcMessage
+	done   chan struct{}
+}
+
+// newHTTPTransport creates the transport of a session. With streaming set,
+// messages are queued for the event stream even before it is attached.
+func newHTTPTransport(id string, streaming bool) *httpTransport {
+	return &httpTransport{
+		id:        id,
+		waiters:   make(map[transport.RequestId]chan *transport.BaseJsonRpcMessage),
+		streaming: streaming,
+		progress:  make(map[string]chan *transport.BaseJsonRpcMessage),
+		lastUsed:  time.Now(),
+		stream:    make(chan *transport.BaseJsonRpcMessage, streamBufferSize),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start implements transport.Transport; messages arrive over HTTP requests
+func (t *httpTransport) Start(ctx context.Context) error {
+	return nil
+}
+
+// Send routes a message to the POST request waiting for it, or to the event stream
+func (t *httpTransport) Send(message *transport.BaseJsonRpcMessage) error {
+	t.mu.Lock()
+	if id, ok := replyID(message); ok {
+		if wait, ok := t.waiters[id]; ok {
+			delete(t.waiters, id)
+			t.mu.Unlock()
+			wait <- message
+			return nil
+		}
+	}
+	defer t.mu.Unlock()
+
+	if token, ok := notificationProgressToken(message); ok {
+		if progress, ok := t.progress[token]; ok {
+			select {
+			case progress <- message:
+				return nil
+			default:
+				return fmt.Errorf("progress stream of session %s is full", t.id)
+			}
+		}
+	}
+
+	// Without a listening client there is nobody to notify
+	if !t.streaming {
+		if !t.dropping {
+			t.dropping = true
+			fmt.Fprintf(os.Stderr, "Session %s has no event stream; dropping notifications until the client opens one with GET\n", t.id)
+		}
+		return nil
+	}
+	t.dropping = false
+
+	// Queue under the lock so that nothing is queued once the stream detached
+	select {
+	case t.stream <- message:
+		return nil
+	default:
+		return fmt.Errorf("event stream of session %s is full", t.id)
+	}
+}
+
+// Close ends the session
+func (t *httpTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	close(t.done)
+	handler := t.onClose
+	t.mu.Unlock()
+
+	if handler != nil {
+		handler()
+	}
+	return nil
+}
+
+// SetCloseHandler sets the handler for close events
+func (t *httpTransport) SetCloseHandler(handler func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onClose = handler
+}
+
+// SetErrorHandler sets the handler for error events
+func (t *httpTransport) SetErrorHandler(handler func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onError = handler
+}
+
+// SetMessageHandler sets the handler for incoming messages
+func (t *httpTransport) SetMessageHandler(handler func(message *transport.BaseJsonRpcMessage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onMessage = handler
+}
+
+// deliver passes a message received over HTTP to the MCP server
+func (t *httpTransport) deliver(message *transport.BaseJsonRpcMessage) {
+	t.mu.Lock()
+	handler := t.onMessage
+	t.lastUsed = time.Now()
+	t.mu.Unlock()
+
+	if handler != nil {
+		handler(message)
+	}
+}
+
+// await returns the channel the response to a request will be sent on
+func (t *httpTransport) await(id transport.RequestId) chan *transport.BaseJsonRpcMessage {
+	wait := make(chan *transport.BaseJsonRpcMessage, 1)
+	t.mu.Lock()
+	t.waiters[id] = wait
+	t.mu.Unlock()
+	return wait
+}
+
+// routeProgress sends the progress notifications with the given tokens to
+// the returned channel until unrouteProgress is called
+func (t *httpTransport) routeProgress(tokens []string) <-chan *transport.BaseJsonRpcMessage {
+	progress := make(chan *transport.BaseJsonRpcMessage, streamBufferSize)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, token := range tokens {
+		t.progress[token] = progress
+	}
+	return progress
+}
+
+// unrouteProgress stops routing the progress notifications with the given tokens
+func (t *httpTransport) unrouteProgress(tokens []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, token := range tokens {
+		delete(t.progress, token)
+	}
+}
+
+// attachStream connects the client's event stream. A session has at most one.
+func (t *httpTransport) attachStream() (<-chan *transport.BaseJsonRpcMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.attached {
+		return nil, fmt.Errorf("session %s already has an event stream", t.id)
+	}
+	t.attached = true
+	t.streaming = true
+	return t.stream, nil
+}
+
+// detachStream disconnects the client's event stream. Until the client
+// listens again, messages for the stream are dropped rather than queued, and
+// the ones still queued are discarded.
+func (t *httpTransport) detachStream() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.attached = false
+	t.streaming = false
+	t.lastUsed = time.Now()
+
+	for {
+		select {
+		case <-t.stream:
+		default:
+			return
+		}
+	}
+}
+
+// touch marks the session as used
+func (t *httpTransport) touch() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastUsed = time.Now()
+}
+
+// idleSince reports whether the session has neither an event stream nor
+// been used for the given time
+func (t *httpTransport) idleSince(timeout time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.attached && time.Since(t.lastUsed) > timeout
+}
+
+// Ensure httpTransport implements transport.Transport
+var _ transport.Transport = (*httpTransport)(nil)
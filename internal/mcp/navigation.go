@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+)
+
+// NavigateTabArgs represents arguments for navigating an existing tab
+type NavigateTabArgs struct {
+	TabId  string `json:"tabId" jsonschema:"required,description=Unique tab ID to navigate"`
+	URL    string `json:"url" jsonschema:"required,description=URL to load in the tab"`
+	Serial string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Call   string `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// ReloadTabArgs represents arguments for reloading an existing tab
+type ReloadTabArgs struct {
+	TabId       string `json:"tabId" jsonschema:"required,description=Unique tab ID to reload"`
+	IgnoreCache bool   `json:"ignoreCache" jsonschema:"description=Bypass the browser cache (default: false)"`
+	Serial      string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Call        string `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// TabHistoryArgs represents arguments for inspecting or stepping through a tab's history
+type TabHistoryArgs struct {
+	TabId   string `json:"tabId" jsonschema:"required,description=Unique tab ID"`
+	Action  string `json:"action" jsonschema:"description=list or back or forward or goto (default: list)"`
+	EntryId int    `json:"entryId" jsonschema:"description=History entry ID for action=goto (from action=list)"`
+	Serial  string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Format  string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+	Call    string `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// navigateTab implements the tab navigation tool
+func (s *TabTransferServer) navigateTab(args NavigateTabArgs) (*mcp_golang.ToolResponse, error) {
+	if args.TabId == "" {
+		return nil, fmt.Errorf("tabId is required")
+	}
+	if args.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	ctx, cancel, err := s.deviceContext(s.lookupCall(args.Call).Context(), args.Serial, "android")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	androidDriver, err := s.startAndroidDriver(ctx, args.Serial)
+	if err != nil {
+		return nil, err
+	}
+	defer androidDriver.Stop(context.WithoutCancel(ctx))
+
+	if err := androidDriver.NavigateTab(ctx, args.TabId, args.URL); err != nil {
+		return nil, fmt.Errorf("failed to navigate Android tab: %w", err)
+	}
+
+	result := fmt.Sprintf("✅ Navigated Android tab %s to %s", args.TabId, args.URL)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
+
+// reloadTab implements the tab reload tool
+func (s *TabTransferServer) reloadTab(args ReloadTabArgs) (*mcp_golang.ToolResponse, error) {
+	if args.TabId == "" {
+		return nil, fmt.Errorf("tabId is required")
+	}
+
+	ctx, cancel, err := s.deviceContext(s.lookupCall(args.Call).Context(), args.Serial, "android")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	androidDriver, err := s.startAndroidDriver(ctx, args.Serial)
+	if err != nil {
+		return nil, err
+	}
+	defer androidDriver.Stop(context.WithoutCancel(ctx))
+
+	if err := androidDriver.ReloadTab(ctx, args.TabId, args.IgnoreCache); err != nil {
+		return nil, fmt.Errorf("failed to reload Android tab: %w", err)
+	}
+
+	result := fmt.Sprintf("✅ Reloaded Android tab: %s", args.TabId)
+	if args.IgnoreCache {
+		result += " (cache bypassed)"
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
+
+// tabHistory implements the tab history tool
+func (s *TabTransferServer) tabHistory(args TabHistoryArgs) (*mcp_golang.ToolResponse, error) {
+	if args.TabId == "" {
+		return nil, fmt.Errorf("tabId is required")
+	}
+
+	action := args.Action
+	if action == "" {
+		action = "list"
+	}
+	switch action {
+	case "list", "back", "forward":
+	case "goto":
+		if args.EntryId == 0 {
+			return nil, fmt.Errorf("entryId is required for action=goto (use action=list to get entry IDs)")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported action: %s (use list, back, forward or goto)", action)
+	}
+
+	ctx, cancel, err := s.deviceContext(s.lookupCall(args.Call).Context(), args.Serial, "android")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	androidDriver, err := s.startAndroidDriver(ctx, args.Serial)
+	if err != nil {
+		return nil, err
+	}
+	defer androidDriver.Stop(context.WithoutCancel(ctx))
+
+	var result string
+
+	switch action {
+	case "list":
+		history, err := androidDriver.TabHistory(ctx, args.TabId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tab history: %w", err)
+		}
+
+		// Determine output format
+		outputFormat := s.defaultFormat
+		if args.Format != "" {
+			if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
+				outputFormat = parsedFormat
+			}
+		}
+
+		formattedHistory, err := format.NewTabFormatter(outputFormat).FormatData(history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format tab history: %w", err)
+		}
+
+		result = fmt.Sprintf("📜 History of tab %s (%d entries, current index %d, format: %s):\n\n%s",
+			args.TabId, len(history.Entries), history.CurrentIndex, outputFormat, formattedHistory)
+
+	case "back", "forward":
+		delta := -1
+		if action == "forward" {
+			delta = 1
+		}
+
+		entry, err := androidDriver.GoTabHistory(ctx, args.TabId, delta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to go %s: %w", action, err)
+		}
+
+		result = fmt.Sprintf("✅ Went %s in tab %s: %s\nURL: %s", action, args.TabId, entry.Title, entry.URL)
+
+	case "goto":
+		if err := androidDriver.NavigateTabToHistoryEntry(ctx, args.TabId, args.EntryId); err != nil {
+			return nil, fmt.Errorf("failed to navigate to history entry: %w", err)
+		}
+
+		result = fmt.Sprintf("✅ Navigated tab %s to history entry %d", args.TabId, args.EntryId)
+	}
+
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
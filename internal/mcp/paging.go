@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"fmt"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/query"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+)
+
+// currentTabsPageURI returns the resource URI of one page of the cached tabs.
+// The first page is tabs://current itself.
+func currentTabsPageURI(page int) string {
+	if page <= 1 {
+		return "tabs://current"
+	}
+	return fmt.Sprintf("tabs://current/page/%d", page)
+}
+
+// currentTabsPageCount returns the number of pages of tabs://current
+func (s *TabTransferServer) currentTabsPageCount() int {
+	total := len(s.cachedTabs(""))
+	if total == 0 {
+		return 1
+	}
+	return (total + s.pageSize - 1) / s.pageSize
+}
+
+// currentTabsPage returns the handler of one page of the cached tabs of all
+// devices. The YAML starts with a comment naming the page and the URI of the
+// next page.
+func (s *TabTransferServer) currentTabsPage(number int) func() (*mcp_golang.ResourceResponse, error) {
+	return func() (*mcp_golang.ResourceResponse, error) {
+		cachedTabs := s.cachedTabs("")
+		page := format.Paginate(len(cachedTabs), format.Cursor{Offset: (number - 1) * s.pageSize}, s.pageSize)
+
+		formatter := format.YAMLFormatter()
+		tabsData, err := formatter.FormatTabs(cachedTabs[page.Start:page.End])
+		if err != nil {
+			return nil, fmt.Errorf("failed to format cached tabs as YAML: %w", err)
+		}
+
+		header := fmt.Sprintf("# %s\n", page.Describe("tabs"))
+		if page.NextCursor != "" {
+			header += fmt.Sprintf("# Next page: %s\n", currentTabsPageURI(number+1))
+		}
+
+		resource := mcp_golang.NewTextEmbeddedResource(currentTabsPageURI(number), header+tabsData, formatter.GetMimeType())
+		return mcp_golang.NewResourceResponse(resource), nil
+	}
+}
+
+// copiedTabsResponse formats one page of the tabs fetched by a copy tool that
+// match the query. The cursor of the next page names the snapshot of the
+// fetch, so that later pages come from the same tabs; without a snapshot they
+// are fetched again.
+func (s *TabTransferServer) copiedTabsResponse(device string, tabs []loader.Tab, queryStr string, cursor format.Cursor, limit int, formatStr string, meta *snapshot.Meta) (*mcp_golang.ToolResponse, error) {
+	q, err := query.Parse(queryStr)
+	if err != nil {
+		return nil, err
+	}
+	copied := len(tabs)
+	queryNote := ""
+	if !q.Empty() {
+		tabs = q.Filter(tabs)
+		queryNote = fmt.Sprintf(", %d matching %s", len(tabs), q)
+	}
+
+	if limit <= 0 {
+		limit = s.pageSize
+	}
+	page := format.Paginate(len(tabs), cursor, limit)
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if formatStr != "" {
+		if parsedFormat, err := format.ParseFormat(formatStr); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	// Format tabs according to specified format
+	formatter := format.NewTabFormatter(outputFormat)
+	formattedTabs, err := formatter.FormatTabs(tabs[page.Start:page.End])
+	if err != nil {
+		return nil, fmt.Errorf("failed to format tabs: %w", err)
+	}
+
+	result := fmt.Sprintf("Successfully copied %d tabs from %s device%s (%s, format: %s):\n\n%s%s%s", copied, device, queryNote, page.Describe("tabs"), outputFormat, formattedTabs, snapshotNote(meta), page.Footer())
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
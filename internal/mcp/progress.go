@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// callArgument is the tool argument the session transport passes the key of
+// a tool call in. mcp-golang gives tool handlers their arguments only, so the
+// call travels with them.
+const callArgument = "_call"
+
+// toolCall is a running tool call of a client. Clients may ask for progress
+// notifications by sending a progress token with the call, and cancel the
+// call with notifications/cancelled.
+type toolCall struct {
+	key           string
+	ctx           context.Context
+	cancel        context.CancelFunc
+	progressToken json.RawMessage
+	transport     transport.Transport
+}
+
+// startCall tracks a tools/call request and passes the key of the call to the
+// tool in its arguments
+func (t *sessionTransport) startCall(request *transport.BaseJSONRPCRequest) {
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return
+	}
+
+	var arguments map[string]json.RawMessage
+	if len(params["arguments"]) > 0 {
+		if err := json.Unmarshal(params["arguments"], &arguments); err != nil {
+			return
+		}
+	}
+	if arguments == nil {
+		arguments = make(map[string]json.RawMessage)
+	}
+
+	var meta struct {
+		ProgressToken json.RawMessage `json:"progressToken"`
+	}
+	if len(params["_meta"]) > 0 {
+		_ = json.Unmarshal(params["_meta"], &meta)
+	}
+
+	key, err := newCallKey()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	call := &toolCall{
+		key:           key,
+		ctx:           ctx,
+		cancel:        cancel,
+		progressToken: meta.ProgressToken,
+		transport:     t.Transport,
+	}
+
+	arguments[callArgument], _ = json.Marshal(key)
+	params["arguments"], _ = json.Marshal(arguments)
+	data, err := json.Marshal(params)
+	if err != nil {
+		cancel()
+		return
+	}
+	request.Params = data
+
+	t.mu.Lock()
+	t.calls[request.Id] = call
+	t.mu.Unlock()
+
+	t.server.callMutex.Lock()
+	t.server.calls[key] = call
+	t.server.callMutex.Unlock()
+}
+
+// finishCall forgets the tool call a response answered
+func (t *sessionTransport) finishCall(id transport.RequestId) {
+	t.mu.Lock()
+	call, ok := t.calls[id]
+	delete(t.calls, id)
+	t.mu.Unlock()
+
+	if ok {
+		t.server.finishCall(call)
+	}
+}
+
+// cancelCall cancels the tool call named by a notifications/cancelled notification
+func (t *sessionTransport) cancelCall(notification *transport.BaseJSONRPCNotification) {
+	var params struct {
+		RequestId transport.RequestId `json:"requestId"`
+		Reason    string              `json:"reason"`
+	}
+	if err := json.Unmarshal(notification.Params, &params); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	call, ok := t.calls[params.RequestId]
+	t.mu.Unlock()
+
+	if ok {
+		fmt.Fprintf(os.Stderr, "Tool call %d cancelled by the client: %s\n", params.RequestId, params.Reason)
+		call.cancel()
+	}
+}
+
+// cancelCalls cancels the running tool calls of a session whose client went away
+func (t *sessionTransport) cancelCalls() {
+	t.mu.Lock()
+	calls := t.calls
+	t.calls = make(map[transport.RequestId]*toolCall)
+	t.mu.Unlock()
+
+	for _, call := range calls {
+		t.server.finishCall(call)
+	}
+}
+
+// finishCall cancels the context of a call and forgets the call
+func (s *TabTransferServer) finishCall(call *toolCall) {
+	call.cancel()
+
+	s.callMutex.Lock()
+	delete(s.calls, call.key)
+	s.callMutex.Unlock()
+}
+
+// lookupCall returns the tool call with the key a tool got in its arguments.
+// Calls not made through a session, or already finished, get a call without
+// cancellation or progress notifications.
+func (s *TabTransferServer) lookupCall(key string) *toolCall {
+	s.callMutex.Lock()
+	call, ok := s.calls[key]
+	s.callMutex.Unlock()
+
+	if !ok {
+		return &toolCall{ctx: context.Background(), cancel: func() {}}
+	}
+	return call
+}
+
+// Context returns the context of the call, cancelled when the client cancels it
+func (c *toolCall) Context() context.Context {
+	return c.ctx
+}
+
+// Progress sends a progress notification if the client asked for them
+func (c *toolCall) Progress(done, total int, message string) {
+	if len(c.progressToken) == 0 || c.transport == nil {
+		return
+	}
+
+	params, err := json.Marshal(map[string]any{
+		"progressToken": c.progressToken,
+		"progress":      done,
+		"total":         total,
+		"message":       message,
+	})
+	if err != nil {
+		return
+	}
+
+	notification := &transport.BaseJSONRPCNotification{
+		Jsonrpc: "2.0",
+		Method:  "notifications/progress",
+		Params:  params,
+	}
+	if err := c.transport.Send(transport.NewBaseMessageNotification(notification)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to send progress notification: %v\n", err)
+	}
+}
+
+// newCallKey returns a random key for a tool call
+func newCallKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to create call key: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// batchStopped describes a batch operation that stopped partway through
+// because the client cancelled the call or it ran out of time
+func batchStopped(batchErr *loader.BatchError, timeout time.Duration) string {
+	reason := "cancelled by the client"
+	if errors.Is(batchErr.Err, context.DeadlineExceeded) {
+		reason = fmt.Sprintf("timed out after %s", timeout)
+	}
+	return fmt.Sprintf("⏹️ Stopped after %s %d of %d tabs (%s)", batchErr.Op, batchErr.Done, batchErr.Total, reason)
+}
+
+// batchSummary summarizes a finished batch operation in one line. done is what
+// happened to a tab, e.g. "closed", and target where, e.g. "on android".
+func batchSummary(result *loader.BatchResult, done, target string) string {
+	if result.Failed > 0 {
+		return fmt.Sprintf("⚠️ %d of %d tabs %s %s, %d failed", result.Succeeded, result.Total, done, target, result.Failed)
+	}
+	return fmt.Sprintf("✅ Successfully %s %d tabs %s", done, result.Succeeded, target)
+}
+
+// batchResponse returns the summary of a batch operation followed by the
+// result of every tab in the requested format, so that clients can retry the
+// tabs that failed or were skipped
+func (s *TabTransferServer) batchResponse(summary string, result *loader.BatchResult, formatStr string) (*mcp_golang.ToolResponse, error) {
+	formattedResult, err := s.formatBatchResult(result, formatStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(summary + "\n\n" + formattedResult)), nil
+}
+
+// formatBatchResult formats the result of a batch operation as JSON or YAML
+func (s *TabTransferServer) formatBatchResult(result *loader.BatchResult, formatStr string) (string, error) {
+	outputFormat := s.defaultFormat
+	if formatStr != "" {
+		if parsedFormat, err := format.ParseFormat(formatStr); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	formattedResult, err := format.NewTabFormatter(outputFormat).FormatData(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to format result: %w", err)
+	}
+	return formattedResult, nil
+}
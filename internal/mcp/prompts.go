@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// dryRunFirst is the closing policy every prompt that may close tabs ends with
+const dryRunFirst = `Never close a tab without my explicit approval. To propose closures, call close_tabs_bulk with the tab IDs and dryRun=true (one call per device with its platform and serial) and show me the preview. Only after I approve, call it again with the same tab IDs, dryRun=false and confirm=true.`
+
+// TriageTabsPromptArgs represents arguments for the tab triage prompt
+type TriageTabsPromptArgs struct {
+	Device string `json:"device" jsonschema:"description=Device to triage: ADB serial or iOS device name or android or ios (default: all devices)"`
+	Focus  string `json:"focus" jsonschema:"description=What to keep in mind while triaging (e.g. a project I am working on)"`
+}
+
+// SummarizeDomainPromptArgs represents arguments for the domain summary prompt
+type SummarizeDomainPromptArgs struct {
+	Domain  string `json:"domain" jsonschema:"required,description=Domain whose tabs to summarize (e.g. github.com)"`
+	Device  string `json:"device" jsonschema:"description=Only summarize the tabs of this device (default: all devices)"`
+	MaxTabs string `json:"maxTabs" jsonschema:"description=Maximum number of tabs to read (default: 10)"`
+}
+
+// FindDuplicatesPromptArgs represents arguments for the duplicate tabs prompt
+type FindDuplicatesPromptArgs struct {
+	Device string `json:"device" jsonschema:"description=Device to look for duplicates on: ADB serial or iOS device name or android or ios (default: all devices)"`
+}
+
+// ReadingListPromptArgs represents arguments for the reading list prompt
+type ReadingListPromptArgs struct {
+	Topic  string `json:"topic" jsonschema:"description=Only include tabs about this topic (default: all articles)"`
+	Device string `json:"device" jsonschema:"description=Only include the tabs of this device (default: all devices)"`
+}
+
+// registerPrompts registers MCP prompts for the common tab workflows
+func (s *TabTransferServer) registerPrompts(server *mcp_golang.Server) error {
+	// Prompt 1: Triage tabs
+	err := server.RegisterPrompt("triage_tabs", "Triage the open tabs of my phone: group them, find what is stale or duplicated and propose closures with a dry run", s.triageTabsPrompt)
+	if err != nil {
+		return fmt.Errorf("failed to register triage_tabs prompt: %w", err)
+	}
+
+	// Prompt 2: Summarize the tabs of one domain
+	err = server.RegisterPrompt("summarize_domain", "Read and summarize the open tabs of one domain", s.summarizeDomainPrompt)
+	if err != nil {
+		return fmt.Errorf("failed to register summarize_domain prompt: %w", err)
+	}
+
+	// Prompt 3: Find duplicates
+	err = server.RegisterPrompt("find_duplicates", "Find duplicate tabs and propose closing the extra copies with a dry run", s.findDuplicatesPrompt)
+	if err != nil {
+		return fmt.Errorf("failed to register find_duplicates prompt: %w", err)
+	}
+
+	// Prompt 4: Build a reading list
+	err = server.RegisterPrompt("reading_list", "Build a reading list from the articles open on my phone", s.readingListPrompt)
+	if err != nil {
+		return fmt.Errorf("failed to register reading_list prompt: %w", err)
+	}
+
+	return nil
+}
+
+// triageTabsPrompt implements the tab triage prompt
+func (s *TabTransferServer) triageTabsPrompt(args TriageTabsPromptArgs) (*mcp_golang.PromptResponse, error) {
+	var text strings.Builder
+	text.WriteString("Help me triage the tabs open on my phone.\n\n")
+	text.WriteString(s.promptCacheNote(args.Device))
+	text.WriteString(`Steps:
+1. Call refresh_tab_cache so that the tabs are current.
+2. Call ` + describeCall("search_tabs", deviceArgument(args.Device), "limit=100") + ` to list the tabs; call it again with the cursor it returns until you have all of them.
+3. Group the tabs by topic and domain, starting from the categories of ` + describeCall("categorize_tabs", deviceArgument(args.Device), "summary=true") + `. For each group say in one line what it is about.
+4. Point out tabs that look done or stale (search results, login pages, finished checkouts, old articles) and duplicates of other tabs.
+5. Propose which tabs to close and which to keep. If unsure what a tab is about, read it with get_tab_content (the tab's device as serial) instead of guessing.
+`)
+	if args.Focus != "" {
+		fmt.Fprintf(&text, "\nKeep this in mind: %s. Tabs related to it stay open.\n", args.Focus)
+	}
+	text.WriteString("\n" + dryRunFirst)
+
+	return promptResponse("Triage phone tabs", text.String()), nil
+}
+
+// summarizeDomainPrompt implements the domain summary prompt
+func (s *TabTransferServer) summarizeDomainPrompt(args SummarizeDomainPromptArgs) (*mcp_golang.PromptResponse, error) {
+	if args.Domain == "" {
+		return nil, fmt.Errorf("domain is required")
+	}
+	maxTabs := args.MaxTabs
+	if maxTabs == "" {
+		maxTabs = "10"
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Summarize the tabs I have open on %s.\n\n", args.Domain)
+	text.WriteString(s.promptCacheNote(args.Device))
+	fmt.Fprintf(&text, `Steps:
+1. Call %s to find the tabs.
+2. Read up to %s of them with get_tab_content (mode=article and the tab's device as serial). For iOS tabs use the title and URL only.
+3. Give a short summary per tab (title, URL, two or three sentences), then an overall summary of what I was researching on %s and any open questions.
+
+Do not close or change any tabs.`, describeCall("search_tabs", fmt.Sprintf("domain=%q", args.Domain), deviceArgument(args.Device), "limit=100"), maxTabs, args.Domain)
+
+	return promptResponse("Summarize tabs on "+args.Domain, text.String()), nil
+}
+
+// findDuplicatesPrompt implements the duplicate tabs prompt
+func (s *TabTransferServer) findDuplicatesPrompt(args FindDuplicatesPromptArgs) (*mcp_golang.PromptResponse, error) {
+	var text strings.Builder
+	text.WriteString("Find duplicate tabs on my phone and propose closing the extra copies.\n\n")
+	text.WriteString(s.promptCacheNote(args.Device))
+	text.WriteString(`Steps:
+1. Call refresh_tab_cache so that the tabs are current.
+2. Call ` + describeCall("find_duplicate_tabs", deviceArgument(args.Device)) + ` to group the tabs showing the same page. It ignores the scheme, a trailing slash, in-page anchors (but not app routes such as #inbox/abc), mobile and AMP variants and tracking parameters (utm_*, fbclid, gclid), and never groups tabs of different devices.
+3. For each group show the suggested tab to keep and the tabs to close with their tab IDs. Keep a different copy instead when its title shows it is clearly the better one.
+`)
+	text.WriteString("\n" + dryRunFirst)
+
+	return promptResponse("Find duplicate tabs", text.String()), nil
+}
+
+// readingListPrompt implements the reading list prompt
+func (s *TabTransferServer) readingListPrompt(args ReadingListPromptArgs) (*mcp_golang.PromptResponse, error) {
+	var text strings.Builder
+	if args.Topic != "" {
+		fmt.Fprintf(&text, "Build a reading list about %s from the tabs open on my phone.\n\n", args.Topic)
+	} else {
+		text.WriteString("Build a reading list from the articles open on my phone.\n\n")
+	}
+	text.WriteString(s.promptCacheNote(args.Device))
+
+	var query string
+	if args.Topic != "" {
+		query = fmt.Sprintf("query=%q", args.Topic)
+	}
+	text.WriteString(`Steps:
+1. Call ` + describeCall("search_tabs", query, deviceArgument(args.Device), "limit=100") + ` and call it again with the cursor it returns until you have all matching tabs.
+2. Skip tabs that are not articles (search results, dashboards, inboxes, apps).
+3. Read each article with get_tab_content (mode=article and the tab's device as serial) to see what it is about and how long it is.
+4. Return a Markdown list ordered by priority: title linked to its URL, one-sentence summary and estimated reading time (about 200 words per minute).
+
+Do not close any tabs. Afterwards you may ask me whether to close the tabs that are now on the list; if I agree: ` + dryRunFirst)
+
+	return promptResponse("Build a reading list", text.String()), nil
+}
+
+// promptCacheNote describes the cached tabs a prompt works on
+func (s *TabTransferServer) promptCacheNote(device string) string {
+	entries := s.cacheEntries(device)
+	if len(entries) == 0 {
+		return "The tab cache is empty; refresh it first.\n\n"
+	}
+
+	devices := make([]string, 0, len(entries))
+	total := 0
+	for _, entry := range entries {
+		devices = append(devices, fmt.Sprintf("%s %s (%d tabs)", entry.Platform, entry.Device, entry.TabCount))
+		total += entry.TabCount
+	}
+	return fmt.Sprintf("The tab cache holds %d tabs: %s.\n\n", total, strings.Join(devices, ", "))
+}
+
+// deviceArgument returns the device argument of a tool call, if any
+func deviceArgument(device string) string {
+	if device == "" {
+		return ""
+	}
+	return fmt.Sprintf("device=%q", device)
+}
+
+// describeCall describes a tool call with its arguments, skipping empty ones
+func describeCall(tool string, arguments ...string) string {
+	var set []string
+	for _, argument := range arguments {
+		if argument != "" {
+			set = append(set, argument)
+		}
+	}
+	if len(set) == 0 {
+		return tool
+	}
+	return tool + " with " + strings.Join(set, ", ")
+}
+
+// promptResponse returns a prompt of one user message
+func promptResponse(description, text string) *mcp_golang.PromptResponse {
+	return mcp_golang.NewPromptResponse(description, mcp_golang.NewPromptMessage(mcp_golang.NewTextContent(text), mcp_golang.RoleUser))
+}
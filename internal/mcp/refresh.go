@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Auto-refresh interval of the tab cache unless cache.refreshInterval or
+// TAB_CACHE_REFRESH_INTERVAL is set
+const (
+	defaultRefreshInterval = time.Minute
+	minRefreshInterval     = 5 * time.Second
+)
+
+// backgroundRefreshPort returns the local port forwarded by background
+// refreshes: cache.refreshPort, or a free port when it is not set
+func (s *TabTransferServer) backgroundRefreshPort() (int, error) {
+	if s.config.Cache.RefreshPort > 0 {
+		return s.config.Cache.RefreshPort, nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free local port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// parseRefreshInterval parses the refresh interval, given as a Go
+// duration ("90s", "5m") or a number of seconds. Zero or "off" disables the
+// auto-refresh.
+func parseRefreshInterval(value string) time.Duration {
+	switch value {
+	case "":
+		return defaultRefreshInterval
+	case "0", "off", "false":
+		return 0
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid tab cache refresh interval %q, using %s\n", value, defaultRefreshInterval)
+			return defaultRefreshInterval
+		}
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	if interval <= 0 {
+		return 0
+	}
+	if interval < minRefreshInterval {
+		return minRefreshInterval
+	}
+	return interval
+}
+
+// autoRefreshTabCache polls the devices and keeps the tab cache current.
+// Failures are logged once until a refresh succeeds again, so an unplugged
+// phone does not flood the log.
+func (s *TabTransferServer) autoRefreshTabCache() {
+	if s.refreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	failing := false
+	for range ticker.C {
+		refreshed, err := s.refreshAllTabCaches(true)
+		if err != nil {
+			if !failing {
+				fmt.Fprintf(os.Stderr, "Tab cache auto-refresh failed: %v\n", err)
+				failing = true
+			}
+		} else if failing {
+			fmt.Fprintf(os.Stderr, "Tab cache auto-refresh recovered\n")
+			failing = false
+		}
+
+		for _, refresh := range refreshed {
+			if !refresh.Diff.IsEmpty() {
+				fmt.Fprintf(os.Stderr, "Tab cache auto-refreshed: %s\n", refresh)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/cdp"
+)
+
+// ScreenshotTabArgs represents arguments for capturing a tab screenshot
+type ScreenshotTabArgs struct {
+	TabId      string  `json:"tabId" jsonschema:"required,description=Unique tab ID to capture"`
+	Serial     string  `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Format     string  `json:"format" jsonschema:"description=Image format: png or jpeg (default: png)"`
+	Quality    int     `json:"quality" jsonschema:"description=JPEG quality 0-100 (ignored for png)"`
+	FullPage   bool    `json:"fullPage" jsonschema:"description=Capture the whole scrollable page instead of the viewport"`
+	ClipX      float64 `json:"clipX" jsonschema:"description=Left edge of the region to capture in CSS pixels"`
+	ClipY      float64 `json:"clipY" jsonschema:"description=Top edge of the region to capture in CSS pixels"`
+	ClipWidth  float64 `json:"clipWidth" jsonschema:"description=Width of the region to capture (enables clipping)"`
+	ClipHeight float64 `json:"clipHeight" jsonschema:"description=Height of the region to capture (enables clipping)"`
+	Scale      float64 `json:"scale" jsonschema:"description=Scale factor for the clipped region (default: 1)"`
+	Call       string  `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// screenshotTab implements the tab screenshot tool
+func (s *TabTransferServer) screenshotTab(args ScreenshotTabArgs) (*mcp_golang.ToolResponse, error) {
+	if args.TabId == "" {
+		return nil, fmt.Errorf("tabId is required")
+	}
+
+	opts := cdp.ScreenshotOptions{
+		Format:   args.Format,
+		Quality:  args.Quality,
+		FullPage: args.FullPage,
+	}
+	if args.ClipWidth > 0 && args.ClipHeight > 0 {
+		opts.Clip = &cdp.Viewport{
+			X:      args.ClipX,
+			Y:      args.ClipY,
+			Width:  args.ClipWidth,
+			Height: args.ClipHeight,
+			Scale:  args.Scale,
+		}
+	}
+
+	data, err := s.captureScreenshot(s.lookupCall(args.Call).Context(), args.Serial, args.TabId, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("📸 Screenshot of Android tab %s (%s)", args.TabId, opts.MimeType())
+	return mcp_golang.NewToolResponse(
+		mcp_golang.NewTextContent(summary),
+		mcp_golang.NewImageContent(data, opts.MimeType()),
+	), nil
+}
+
+// captureScreenshot starts an Android driver and captures a tab screenshot as base64 data
+func (s *TabTransferServer) captureScreenshot(parent context.Context, serial, tabID string, opts cdp.ScreenshotOptions) (string, error) {
+	ctx, cancel, err := s.deviceContext(parent, serial, "android")
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	androidDriver, err := s.startAndroidDriver(ctx, serial)
+	if err != nil {
+		return "", err
+	}
+	defer androidDriver.Stop(context.WithoutCancel(ctx))
+
+	data, err := androidDriver.CaptureTabScreenshot(ctx, tabID, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+
+	return data, nil
+}
@@ -3,8 +3,11 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,82 +15,106 @@ import (
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/metoro-io/mcp-golang/transport/stdio"
 
+	"github.com/kazuph/mcp-android-chrome/internal/adb"
+	"github.com/kazuph/mcp-android-chrome/internal/category"
+	"github.com/kazuph/mcp-android-chrome/internal/config"
 	"github.com/kazuph/mcp-android-chrome/internal/driver"
 	"github.com/kazuph/mcp-android-chrome/internal/format"
 	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/match"
 	"github.com/kazuph/mcp-android-chrome/internal/platform"
+	"github.com/kazuph/mcp-android-chrome/internal/query"
+	"github.com/kazuph/mcp-android-chrome/internal/search"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+	"github.com/kazuph/mcp-android-chrome/internal/trash"
 )
 
 // TabTransferServer implements MCP server for tab transfer functionality
 type TabTransferServer struct {
-	server      *mcp_golang.Server
-	tabCache    []loader.Tab
 	cacheMutex  sync.RWMutex
-	cacheSize   int
-	lastUpdated time.Time
+
+	// Connected clients, each served by its own MCP server
+	sessions     map[*session]bool
+	sessionMutex sync.Mutex
+
+	// Settings from the config file, the environment and the mcp command flags
+	config *config.Config
+
+	// Output format of tools called without a format argument
+	defaultFormat format.Format
+
+	// Number of tabs per page of tabs://current and the copy tools
+	pageSize int
+
+	// Cached tabs per device and browser, keyed by cacheKey
+	tabCache map[string]*tabCacheEntry
+
+	// Search index over the cached tabs and the page text read from them
+	searchIndex *search.Index
+
+	// How often the tab cache is refreshed in the background, 0 when disabled
+	refreshInterval time.Duration
+
+	// On-disk history of fetched tabs
+	snapshots *snapshot.Store
+
+	// Tabs closed by the tools, kept for undo_close
+	closedTabs *trash.Store
+
+	// Per-tab resources currently registered, keyed by URI
+	tabResources  map[string]tabResource
+	resourceMutex sync.Mutex
+
+	// Running tool calls of all sessions, keyed by the call key
+	calls     map[string]*toolCall
+	callMutex sync.Mutex
 }
 
-// NewTabTransferServer creates a new MCP server for tab transfer
-func NewTabTransferServer() *TabTransferServer {
-	server := mcp_golang.NewServer(stdio.NewStdioServerTransport())
-	
-	// Default cache size is 30, can be overridden by environment variable
-	cacheSize := 30
-	if envSize := os.Getenv("TAB_CACHE_SIZE"); envSize != "" {
-		if size := parseInt(envSize); size > 0 {
-			cacheSize = size
-		}
-	}
+// NewTabTransferServer creates a new MCP server for tab transfer. Device
+// profiles, defaults, cache settings and safety policies come from cfg.
+func NewTabTransferServer(cfg *config.Config) *TabTransferServer {
+	// Validated when the config was loaded
+	defaultFormat, _ := cfg.OutputFormat("")
 	
+	// The cache holds all tabs; responses are paged
 	return &TabTransferServer{
-		server:    server,
-		tabCache:  make([]loader.Tab, 0),
-		cacheSize: cacheSize,
-	}
-}
-
-// parseInt safely converts string to int
-func parseInt(s string) int {
-	if s == "" {
-		return 0
-	}
-	result := 0
-	for _, r := range s {
-		if r >= '0' && r <= '9' {
-			result = result*10 + int(r-'0')
-		} else {
-			return 0
-		}
+		sessions:        make(map[*session]bool),
+		config:          cfg,
+		defaultFormat:   defaultFormat,
+		tabCache:        make(map[string]*tabCacheEntry),
+		searchIndex:     search.NewIndex(),
+		pageSize:        cfg.Cache.PageSize,
+		refreshInterval: parseRefreshInterval(cfg.Cache.RefreshInterval),
+		snapshots:       snapshot.NewStore(cfg.SnapshotOptions()),
+		closedTabs:      trash.NewStore(cfg.TrashOptions()),
+		tabResources:    make(map[string]tabResource),
+		calls:           make(map[string]*toolCall),
 	}
-	return result
 }
 
-// Start initializes and starts the MCP server
+// Start initializes and starts the MCP server on stdio
 func (s *TabTransferServer) Start() error {
-	// Register MCP tools
-	if err := s.registerTools(); err != nil {
-		return fmt.Errorf("failed to register tools: %w", err)
+	// Serve the client that spawned the server
+	if _, err := s.newSession(stdio.NewStdioServerTransport()); err != nil {
+		return err
 	}
 
-	// Register MCP resources
-	if err := s.registerResources(); err != nil {
-		return fmt.Errorf("failed to register resources: %w", err)
-	}
-
-	// Auto-populate tab cache on startup (non-blocking)
-	go s.populateTabCache()
-
-	// Start the server
-	err := s.server.Serve()
-	if err != nil {
-		return fmt.Errorf("failed to serve: %w", err)
-	}
+	// Auto-populate tab cache on startup and keep it current (non-blocking)
+	s.startTabCache()
 
 	// Keep the server running
 	select {}
 }
 
-// populateTabCache attempts to fetch and cache Android tabs on startup
+// startTabCache populates the tab cache in the background and keeps it current
+func (s *TabTransferServer) startTabCache() {
+	go func() {
+		s.populateTabCache()
+		s.autoRefreshTabCache()
+	}()
+}
+
+// populateTabCache attempts to fetch and cache the tabs of all devices on startup
 func (s *TabTransferServer) populateTabCache() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -95,62 +122,64 @@ func (s *TabTransferServer) populateTabCache() {
 		}
 	}()
 
-	// Try to populate cache with Android tabs
-	if err := s.fetchAndCacheAndroidTabs(); err != nil {
+	// Try to populate cache with Android and iOS tabs
+	if _, err := s.refreshAllTabCaches(false); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to populate tab cache: %v\n", err)
 		// Don't fail the server startup if cache population fails
-	} else {
-		fmt.Fprintf(os.Stderr, "Successfully populated tab cache with %d tabs\n", len(s.tabCache))
 	}
+	fmt.Fprintf(os.Stderr, "Populated tab cache with %d tabs from %d devices\n", len(s.cachedTabs("")), len(s.cacheEntries("")))
 }
 
-// fetchAndCacheAndroidTabs fetches tabs from Android device and updates cache
-func (s *TabTransferServer) fetchAndCacheAndroidTabs() error {
-	config := driver.AndroidConfig{
-		DriverConfig: driver.DriverConfig{
-			Port:    9222,
-			Timeout: 10 * time.Second,
-			Debug:   false, // Don't spam logs during auto-fetch
-		},
-		Socket: "chrome_devtools_remote",
-		Wait:   2 * time.Second,
+// fetchAndCacheAndroidTabs fetches tabs from Android device and updates its
+// cache entry. An empty serial selects the single USB-attached device. It
+// returns the changes since the previous cache contents; when the tabs
+// changed, clients are notified that tabs://current was updated.
+//
+// Background refreshes forward their own local port, so that cleaning up
+// never removes the forward of a tool call in progress, skip the wait before
+// connecting, as a failed poll is retried on the next tick, and only save a
+// snapshot when the tabs changed.
+func (s *TabTransferServer) fetchAndCacheAndroidTabs(serial string, background bool) (*loader.TabDiff, error) {
+	profile, err := s.config.Resolve(serial, "android")
+	if err != nil {
+		return nil, err
+	}
+
+	config := profile.AndroidConfig(false) // Don't spam logs during auto-fetch
+	if background {
+		if config.Port, err = s.backgroundRefreshPort(); err != nil {
+			return nil, err
+		}
+		config.Wait = 0
 	}
 
 	androidDriver := driver.NewAndroidDriver(config)
 	
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), profile.TimeoutDuration()+5*time.Second)
 	defer cancel()
 
 	// Start driver
 	if err := androidDriver.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start Android driver: %w", err)
+		return nil, fmt.Errorf("failed to start Android driver: %w", err)
 	}
 	defer androidDriver.Stop(ctx)
 
 	// Load tabs
 	tabs, err := androidDriver.LoadTabs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to load tabs: %w", err)
+		return nil, fmt.Errorf("failed to load tabs: %w", err)
 	}
 
-	// Update cache with latest tabs (limit to cacheSize)
-	s.cacheMutex.Lock()
-	defer s.cacheMutex.Unlock()
-	
-	if len(tabs) > s.cacheSize {
-		s.tabCache = tabs[:s.cacheSize]
-	} else {
-		s.tabCache = tabs
-	}
-	s.lastUpdated = time.Now()
+	diff, added := s.storeTabs("android", androidDriver.Device(), androidDriver.Browser(), tabs)
+	s.cacheChanged("android", androidDriver.Device(), tabs, diff, added, background)
 
-	return nil
+	return diff, nil
 }
 
-// registerTools registers all available MCP tools
-func (s *TabTransferServer) registerTools() error {
+// registerTools registers all available MCP tools on the MCP server of a session
+func (s *TabTransferServer) registerTools(server *mcp_golang.Server) error {
 	// Tool 1: Copy tabs from Android
-	err := s.server.RegisterTool("copy_tabs_android", `Copy Chrome tabs from Android device via ADB.
+	err := server.RegisterTool("copy_tabs_android", `Copy Chrome tabs from Android device via ADB.
 
 Prerequisites:
 1. Android device with USB debugging enabled (Settings > Developer Options > USB Debugging)
@@ -168,13 +197,19 @@ Common Issues & Solutions:
 - "no devices found": Ensure USB cable supports data transfer (not just charging)
 - "connection refused": Restart ADB with 'adb kill-server && adb start-server'
 
+When several devices are attached, pass the serial argument (see list_devices) to pick one.
+
+Large tab sets are returned in pages (50 tabs by default). When more tabs are available, the result ends with a cursor: call the tool again with cursor to get the next page from the same fetch.
+
+Pass query to return only the tabs matching a search query in the syntax of search_tabs (e.g. host:github.com -title:notifications); later pages need the same query.
+
 This tool will automatically check environment and provide specific error messages if prerequisites are not met.`, s.copyTabsAndroid)
 	if err != nil {
 		return fmt.Errorf("failed to register copy_tabs_android: %w", err)
 	}
 
 	// Tool 2: Copy tabs from iOS
-	err = s.server.RegisterTool("copy_tabs_ios", `Copy Chrome/Safari tabs from iOS device via WebKit Debug Proxy.
+	err = server.RegisterTool("copy_tabs_ios", `Copy Chrome/Safari tabs from iOS device via WebKit Debug Proxy.
 
 Prerequisites:
 1. iOS device with Web Inspector enabled (Settings > Safari > Advanced > Web Inspector)
@@ -193,27 +228,35 @@ Common Issues & Solutions:
 - "No targets found": Make sure Safari/Chrome is running and has open tabs
 - "Connection timeout": Try disconnecting and reconnecting USB cable
 
+Large tab sets are returned in pages (50 tabs by default). When more tabs are available, the result ends with a cursor: call the tool again with cursor to get the next page from the same fetch.
+
+Pass query to return only the tabs matching a search query in the syntax of search_tabs (e.g. host:github.com -title:notifications); later pages need the same query.
+
 This tool will automatically check environment and provide specific error messages if prerequisites are not met.`, s.copyTabsIOS)
 	if err != nil {
 		return fmt.Errorf("failed to register copy_tabs_ios: %w", err)
 	}
 
 	// Tool 3: Reopen tabs
-	err = s.server.RegisterTool("reopen_tabs", `Restore saved tabs to mobile device.
+	err = server.RegisterTool("reopen_tabs", `Restore saved tabs to mobile device.
 
-This tool takes previously exported tabs (from copy_tabs_android or copy_tabs_ios) and reopens them on the target device.
+This tool takes previously exported tabs (from copy_tabs_android or copy_tabs_ios) and reopens them on the target device. Instead of tabsJson, pass snapshotId to restore the tabs of a stored snapshot (see list_snapshots).
 
 Prerequisites (same as copy tools):
 - For Android: ADB installed, USB debugging enabled, device connected
 - For iOS: iOS WebKit Debug Proxy installed, Web Inspector enabled, device connected
 
-The tool automatically detects platform-specific requirements and provides detailed error messages for troubleshooting.`, s.reopenTabs)
+The tool automatically detects platform-specific requirements and provides detailed error messages for troubleshooting.
+
+The response ends with the result of every tab (id, url, status, error and the new tab ID) in the format given by the format argument (json or yaml), so that failed or skipped tabs can be retried. A tab that fails to open does not stop the others.
+
+Progress is reported per tab when the call carries a progress token. A cancelled call stops before the next tab and reports the remaining tabs as skipped.`, s.reopenTabs)
 	if err != nil {
 		return fmt.Errorf("failed to register reopen_tabs: %w", err)
 	}
 
 	// Tool 4: Check environment
-	err = s.server.RegisterTool("check_environment", `Check system dependencies and device connectivity.
+	err = server.RegisterTool("check_environment", `Check system dependencies and device connectivity.
 
 This diagnostic tool verifies:
 1. ADB (Android Debug Bridge) installation and functionality
@@ -221,15 +264,25 @@ This diagnostic tool verifies:
 3. Device connectivity status
 4. USB debugging permissions
 
+Arguments:
+- platform (optional): android, ios or all (default: all)
+- serial (optional): ADB device serial or profile name to check
+- adbBackend (optional): ADB backend: auto, exec or native (default: the profile's backend, $ADB_BACKEND or auto)
+
+With the native backend no adb binary is needed; the tool then checks that the adb server answers.
+
 Use this tool first to diagnose setup issues before attempting tab operations. It provides specific installation commands and troubleshooting steps for each platform.`, s.checkEnvironment)
 	if err != nil {
 		return fmt.Errorf("failed to register check_environment: %w", err)
 	}
 
 	// Tool 5: Refresh tab cache
-	err = s.server.RegisterTool("refresh_tab_cache", `Manually refresh the current tab cache from Android device.
+	err = server.RegisterTool("refresh_tab_cache", `Manually refresh the current tab cache from Android and iOS devices.
+
+This tool fetches the latest tabs from every attached Android device and, when ios_webkit_debug_proxy is installed, from the iOS device, and updates the internal cache. The cache keeps the tabs of each device and browser separately. Useful when you want to ensure the current_tabs resource reflects the most recent browser state.
 
-This tool fetches the latest tabs from the connected Android device and updates the internal cache. Useful when you want to ensure the current_tabs resource reflects the most recent browser state.
+Arguments:
+- serial (optional): Only refresh this Android device
 
 The cache is automatically populated on server startup, but this tool allows manual updates without restarting the server.`, s.refreshTabCache)
 	if err != nil {
@@ -237,13 +290,17 @@ The cache is automatically populated on server startup, but this tool allows man
 	}
 
 	// Tool 6: Cache status
-	err = s.server.RegisterTool("cache_status", `Check the current status of the tab cache.
+	err = server.RegisterTool("cache_status", `Check the current status of the tab cache.
 
 This diagnostic tool shows:
 - Number of cached tabs
-- Cache size limit
+- Page size of tab listings
 - Last update timestamp
 - Cache population status
+- Cached tabs per device and browser
+
+Arguments:
+- device (optional): Only show this device (ADB serial, iOS device name, android or ios)
 
 Useful for debugging cache-related issues and understanding the current state of cached data.`, s.cacheStatus)
 	if err != nil {
@@ -251,72 +308,341 @@ Useful for debugging cache-related issues and understanding the current state of
 	}
 
 	// Tool 7: Close single tab
-	err = s.server.RegisterTool("close_tab", `Close a single tab on Android device by tab ID.
+	err = server.RegisterTool("close_tab", `Close a single tab on Android device by tab ID.
 
 This tool closes a specific tab using its unique Chrome DevTools Protocol ID. The tab ID can be obtained from copy_tabs_android tool or current_tabs resource.
 
-⚠️ Warning: This action cannot be undone. The tab will be permanently closed.
+The tab is recorded in the trash before it is closed; list_closed_tabs shows it and undo_close reopens it.
 
 Arguments:
 - tabId (required): The unique ID of the tab to close
 - platform (optional): Target platform (default: android)
+- serial (optional): ADB device serial when several Android devices are attached
 - confirm (optional): Set to true to skip confirmation (default: false)
 
-Safety: Use cache_status or copy_tabs_android first to get current tab IDs.`, s.closeTab)
+Safety: Use cache_status or copy_tabs_android first to get current tab IDs. Tabs whose URL matches safety.protectedUrls of the config file are never closed.`, s.closeTab)
 	if err != nil {
 		return fmt.Errorf("failed to register close_tab: %w", err)
 	}
 
 	// Tool 8: Close multiple tabs
-	err = s.server.RegisterTool("close_tabs_bulk", `Close multiple tabs at once on Android device.
+	err = server.RegisterTool("close_tabs_bulk", `Close multiple tabs at once on Android device.
 
 This tool allows bulk closing of tabs by their IDs or by filtering criteria. Useful for cleaning up many tabs simultaneously.
 
-⚠️ Warning: This action cannot be undone. All matching tabs will be permanently closed.
+⚠️ Warning: All matching tabs will be closed. They are recorded in the trash first; list_closed_tabs shows them and undo_close reopens them.
 
 Arguments:
 - tabIds (optional): Array of specific tab IDs to close
 - platform (optional): Target platform (default: android)
-- filterUrl (optional): Close tabs matching URL pattern (supports wildcards)
-- filterTitle (optional): Close tabs matching title pattern (supports wildcards)
+- serial (optional): ADB device serial when several Android devices are attached
+- query (optional): Close tabs matching this query in the syntax of search_tabs (e.g. host:youtube.com -title:music or "release notes" OR changelog)
+- category (optional): Close tabs in this category of categorize_tabs (e.g. Shopping)
+- filterUrl (optional): Close tabs whose whole URL matches this pattern (e.g. *.google.com/*)
+- filterTitle (optional): Close tabs whose whole title matches this pattern (e.g. *docs*)
+- filterHost (optional): Close tabs whose host name matches this pattern (e.g. *.example.com or news.ycombinator.com)
+- pathPrefix (optional): Close tabs whose URL path starts with this (e.g. /watch)
+- queryParam (optional): Close tabs whose URL has this query parameter: key or key=value
+- matchMode (optional): glob (default) or regex
+- excludeUrl, excludeTitle (optional): Never close tabs whose URL or title matches one of these patterns, even when given by tabIds
 - confirm (optional): Set to true to skip confirmation (default: false)
 - dryRun (optional): Preview which tabs would be closed without actually closing them
+- format (optional): Format of the per-tab result: json or yaml (default: the configured format or json)
+
+The query, the category and the filters apply when no tabIds are given, and a tab must match all of them. Without tabIds at least one of them must select tabs: exclusions, negated query terms and OR branches of negations only are refused. Globs match the whole text case-insensitively: * matches any characters, ? one character and [abc] one of a set; a pattern without wildcards matches only that exact text. Regexes (matchMode=regex, RE2 syntax) match anywhere in the text, case-insensitively; anchor them with ^ and $.
+
+The response ends with the result of every tab (id, url, status and error), so that failed or skipped tabs can be retried.
 
-Safety: Use dryRun=true first to preview the operation.`, s.closeTabsBulk)
+Safety: Use dryRun=true first to preview the operation. The safety policies of the config file apply: tabs matching safety.protectedUrls are skipped and at most safety.maxBulkClose tabs are closed per call.
+
+Progress is reported per tab when the call carries a progress token. A cancelled call stops before the next tab and reports the remaining tabs as skipped.`, s.closeTabsBulk)
 	if err != nil {
 		return fmt.Errorf("failed to register close_tabs_bulk: %w", err)
 	}
 
 	// Tool 9: Search tabs
-	err = s.server.RegisterTool("search_tabs", `Search through currently cached tabs with advanced filtering and ranking.
+	err = server.RegisterTool("search_tabs", `Search through currently cached tabs with advanced filtering and ranking.
 
 This tool provides powerful search capabilities across cached tabs, including:
-- Full-text search across URLs and titles
-- Fuzzy matching for partial queries
+- Full-text search across URLs, titles and the text of pages read with get_tab_content
+- Fuzzy matching: words also match longer words they start (kube finds kubernetes) and words with a typo or two (kubernets)
+- Japanese and other CJK titles: full-width and half-width forms and hiragana and katakana match each other
 - Domain-based filtering
-- Relevance scoring and ranking
-- Multiple search criteria combination
+- Relevance ranking by BM25, weighting title matches above URL and page text matches
+- Boolean queries with field prefixes, phrases, OR, negation, globs and regexes
+
+Query syntax:
+- Words are ANDed: github issues
+- OR (upper case) joins alternatives and binds looser than AND: youtube OR vimeo
+- A leading - or NOT negates a term or a parenthesized group: -youtube, NOT (shorts OR live)
+- "Quoted phrases" match as a whole
+- Prefixes restrict a term to a field: title:, url:, host: (the host and its subdomains), device:
+- Terms with * or ? are globs matched against the whole field: title:*notes*, url:github.com/*/issues/*
+- /re/ is a regular expression: title:/^re:/
+- Matching ignores case
 
 Arguments:
-- query (optional): Search query to match against URLs and titles
+- query (optional): Search query in the query syntax above, matched against URLs and titles
 - domain (optional): Filter by specific domain (e.g., "github.com")
 - title (optional): Search specifically in tab titles
 - url (optional): Search specifically in URLs
-- limit (optional): Maximum number of results to return (default: 10)
-- format (optional): Output format: json or yaml (default: json)
+- device (optional): Only search this device (ADB serial, iOS device name, android or ios); all cached devices by default
+- category (optional): Only tabs in this category of categorize_tabs (e.g. Docs, Shopping)
+- limit (optional): Maximum number of results per page (default: 10)
+- cursor (optional): Cursor from a previous search to get the next page
+- format (optional): Output format: json or yaml (default: the configured format or json)
 
-Returns ranked results with relevance scores for better search experience.`, s.searchTabs)
+Returns ranked results with relevance scores (higher is better; 0.1 for every tab when there is no query). Every tab carries the device and browser it is open in.`, s.searchTabs)
 	if err != nil {
 		return fmt.Errorf("failed to register search_tabs: %w", err)
 	}
 
+	// Tool 10: List Android devices
+	err = server.RegisterTool("list_devices", `List Android devices attached via ADB.
+
+Returns one record per device as reported by 'adb devices -l':
+- serial: Device serial, usable as the serial argument of the other Android tools
+- state: device, unauthorized, offline, ...
+- model, product, device: Hardware identifiers reported by the device
+- transportId: ADB transport ID
+
+Arguments:
+- adbBackend (optional): auto, exec (adb binary) or native (adb server protocol, no adb binary needed)
+- format (optional): Output format: json or yaml (default: the configured format or json)
+
+Use this tool when several phones or emulators are connected to pick the one to work with.`, s.listDevices)
+	if err != nil {
+		return fmt.Errorf("failed to register list_devices: %w", err)
+	}
+
+	// Tool 11: Activate tab
+	err = server.RegisterTool("activate_tab", `Bring a tab to the front on Android device by tab ID.
+
+This tool shows a specific tab on the phone's screen using its Chrome DevTools Protocol ID. The tab ID can be obtained from copy_tabs_android or search_tabs.
+
+Arguments:
+- tabId (required): The unique ID of the tab to activate
+- serial (optional): ADB device serial when several Android devices are attached
+
+Typical workflow: find the tab with search_tabs, then activate it to show it on the phone.`, s.activateTab)
+	if err != nil {
+		return fmt.Errorf("failed to register activate_tab: %w", err)
+	}
+
+	// Tool 12: Navigate tab
+	err = server.RegisterTool("navigate_tab", `Load a new URL in an existing tab on Android device.
+
+Unlike closing the tab and reopening it, this keeps the tab's position and its back/forward history.
+
+Arguments:
+- tabId (required): The unique ID of the tab to navigate
+- url (required): The URL to load
+- serial (optional): ADB device serial when several Android devices are attached`, s.navigateTab)
+	if err != nil {
+		return fmt.Errorf("failed to register navigate_tab: %w", err)
+	}
+
+	// Tool 13: Reload tab
+	err = server.RegisterTool("reload_tab", `Reload an existing tab on Android device.
+
+Arguments:
+- tabId (required): The unique ID of the tab to reload
+- ignoreCache (optional): Bypass the browser cache, like a hard reload (default: false)
+- serial (optional): ADB device serial when several Android devices are attached`, s.reloadTab)
+	if err != nil {
+		return fmt.Errorf("failed to register reload_tab: %w", err)
+	}
+
+	// Tool 14: Tab history
+	err = server.RegisterTool("tab_history", `Inspect or step through the navigation history of a tab on Android device.
+
+Arguments:
+- tabId (required): The unique ID of the tab
+- action (optional): list (default), back, forward or goto
+- entryId (optional): History entry ID to jump to with action=goto (from action=list)
+- serial (optional): ADB device serial when several Android devices are attached
+- format (optional): Output format for action=list: json or yaml (default: the configured format or json)`, s.tabHistory)
+	if err != nil {
+		return fmt.Errorf("failed to register tab_history: %w", err)
+	}
+
+	// Tool 15: Screenshot tab
+	err = server.RegisterTool("screenshot_tab", `Capture a screenshot of a tab on Android device and return it as an image.
+
+Useful for reviewing how a page renders on the phone.
+
+Arguments:
+- tabId (required): The unique ID of the tab to capture
+- serial (optional): ADB device serial when several Android devices are attached
+- format (optional): png or jpeg (default: png)
+- quality (optional): JPEG quality 0-100
+- fullPage (optional): Capture the whole scrollable page instead of the visible viewport
+- clipX, clipY, clipWidth, clipHeight (optional): Capture only this region, in CSS pixels
+- scale (optional): Scale factor for the clipped region (default: 1)
+
+Screenshots of Android tabs are also available as tabs://android/{serial}/{tabId}/screenshot resources.`, s.screenshotTab)
+	if err != nil {
+		return fmt.Errorf("failed to register screenshot_tab: %w", err)
+	}
+
+	// Tool 16: Get tab content
+	err = server.RegisterTool("get_tab_content", `Read the content of a tab on Android device.
+
+Use this to summarize or answer questions about what is open on the phone. Tab IDs come from search_tabs or copy_tabs_android.
+
+Arguments:
+- tabId (required): The unique ID of the tab to read
+- mode (optional): article (default) extracts the main article as Markdown, text returns the rendered page text, html returns the raw document HTML
+- maxLength (optional): Maximum number of characters to return (default: 20000, -1 for no limit)
+- serial (optional): ADB device serial when several Android devices are attached
+- format (optional): text (default), json or yaml
+
+The article of an Android tab is also available as the tabs://android/{serial}/{tabId}/content resource.`, s.getTabContent)
+	if err != nil {
+		return fmt.Errorf("failed to register get_tab_content: %w", err)
+	}
+
+	// Tool 17: List snapshots
+	err = server.RegisterTool("list_snapshots", `List stored tab snapshots, newest first.
+
+Every tab fetch (copy_tabs_android, copy_tabs_ios, refresh_tab_cache and the CLI commands) saves a timestamped snapshot of the tabs to disk, so earlier states can be looked up later, e.g. "what did I have open last Tuesday?".
+
+Arguments:
+- platform (optional): Only snapshots from android or ios
+- device (optional): Only snapshots from this device (ADB serial or iOS device name)
+- since, until (optional): Time range as RFC 3339 or YYYY-MM-DD
+- limit (optional): Maximum number of snapshots to return (default: 20)
+- format (optional): Output format: json or yaml (default: the configured format or json)`, s.listSnapshots)
+	if err != nil {
+		return fmt.Errorf("failed to register list_snapshots: %w", err)
+	}
+
+	// Tool 18: Load snapshot
+	err = server.RegisterTool("load_snapshot", `Load the tabs of a stored snapshot.
+
+Arguments:
+- snapshotId (required): Snapshot ID from list_snapshots, or latest for the newest snapshot
+- format (optional): Output format: json or yaml (default: the configured format or json)
+
+To restore the tabs of a snapshot on a device, pass the snapshot ID to reopen_tabs.`, s.loadSnapshot)
+	if err != nil {
+		return fmt.Errorf("failed to register load_snapshot: %w", err)
+	}
+
+	// Tool 19: Delete snapshot
+	err = server.RegisterTool("delete_snapshot", `Delete a stored tab snapshot.
+
+Arguments:
+- snapshotId (required): Snapshot ID from list_snapshots
+- confirm (optional): Set to true to skip confirmation (default: false)
+
+Old snapshots are also pruned automatically: by default at most 500 are kept, for at most 90 days (snapshots.maxCount and snapshots.maxAgeDays in the config file).`, s.deleteSnapshot)
+	if err != nil {
+		return fmt.Errorf("failed to register delete_snapshot: %w", err)
+	}
+
+	// Tool 20: Diff tabs
+	err = server.RegisterTool("diff_tabs", `Compare two sets of tabs and report what changed.
+
+Each side can be the tab cache, the live device, a stored snapshot or a tabs file, so you can see what was opened or closed since the last look or since any earlier snapshot.
+
+Arguments:
+- from (optional): cache (default), live, latest, a snapshot ID from list_snapshots or a tabs file path
+- to (optional): live (default), cache, latest, a snapshot ID or a tabs file path
+- platform (optional): Platform for live tabs: android (default) or ios
+- serial (optional): ADB device serial when several Android devices are attached
+- format (optional): Output format: json or yaml (default: the configured format or json)
+
+Tabs are matched by ID. The result lists added and removed tabs, navigated tabs (same ID with a new URL) and retitled tabs (same URL with a new title).`, s.diffTabs)
+	if err != nil {
+		return fmt.Errorf("failed to register diff_tabs: %w", err)
+	}
+
+	// Tool 21: List closed tabs
+	err = server.RegisterTool("list_closed_tabs", `List the tabs closed by close_tab and close_tabs_bulk, most recently closed first.
+
+Every tab is recorded in the trash (URL, title, device, time and the tool that closed it) before it is closed, so that undo_close can reopen it. Tabs closed together share an operation ID.
+
+Arguments:
+- platform (optional): Only tabs closed on android or ios
+- device (optional): Only tabs closed on this device (ADB serial or iOS device name)
+- operation (optional): Only the tabs closed by this operation, or latest for the most recent one
+- since, until (optional): Time range as RFC 3339 or YYYY-MM-DD
+- limit (optional): Maximum number of closed tabs to return (default: 20)
+- format (optional): Output format: json or yaml (default: the configured format or json)
+
+Closed tabs are kept for 30 days, at most 1000 (trash.maxAgeDays and trash.maxCount of the config file).`, s.listClosedTabs)
+	if err != nil {
+		return fmt.Errorf("failed to register list_closed_tabs: %w", err)
+	}
+
+	// Tool 22: Undo close
+	err = server.RegisterTool("undo_close", `Reopen closed tabs from the trash.
+
+The tabs are restored on the device they were closed on, like reopen_tabs, and leave the trash once they are open again. Tabs that fail to reopen stay in the trash.
+
+Arguments:
+- entryIds (optional): IDs of closed tabs from list_closed_tabs
+- operation (optional): Reopen every tab closed by this operation, or latest for the most recent close
+- serial (optional): Reopen on this ADB device serial or profile name instead
+- format (optional): Format of the per-tab result: json or yaml (default: the configured format or json)
+
+Pass either entryIds or operation. The response ends with the result of every tab per device.`, s.undoClose)
+	if err != nil {
+		return fmt.Errorf("failed to register undo_close: %w", err)
+	}
+
+	// Tool 23: Find duplicate tabs
+	err = server.RegisterTool("find_duplicate_tabs", `Find cached tabs that show the same page and suggest which copy to keep.
+
+Tabs of one device are grouped by normalized URL: the scheme, www., m. and amp. hosts, AMP variants, the fragment, tracking parameters (utm_*, fbclid, gclid and the like) and a trailing slash are ignored. Tabs on different devices are never duplicates of each other.
+
+Arguments:
+- device (optional): Only tabs of this device: ADB serial, iOS device name, android or ios (default: all cached devices)
+- byTitle (optional): Also group tabs of one site whose titles are similar (default: false)
+- titleSimilarity (optional): Share of common title words from 0 to 1 for byTitle (default: 0.8)
+- close (optional): Close every tab of a group except the suggested one through close_tabs_bulk (default: false)
+- confirm (optional): With close, actually close the tabs; otherwise close only previews them (default: false)
+- format (optional): Output format: json or yaml (default: the configured format or json)
+
+Every group lists the tab to keep (the one with the tidiest URL, e.g. https without tracking parameters) and the tabs to close. Closed tabs can be reopened with undo_close.`, s.findDuplicateTabs)
+	if err != nil {
+		return fmt.Errorf("failed to register find_duplicate_tabs: %w", err)
+	}
+
+	// Tool 24: Categorize tabs
+	err = server.RegisterTool("categorize_tabs", `Group the cached tabs by category, e.g. Docs, Code, Video, Social, Shopping or News.
+
+Categories come from a user-editable rules file (categories.rulesFile in the config file, TAB_CATEGORIES_FILE or categories.yaml in the user config directory) that maps host patterns, optionally with path patterns, to categories. The first matching rule wins:
+
+rules:
+  - category: Work
+    hosts: [github.com]
+    paths: [/my-org]
+  - category: Docs
+    hosts: ["docs.*", "*.readthedocs.io"]
+
+Tabs no rule matches fall back to a built-in heuristic by host and path (well-known sites, hosts such as docs.* or shop.* and paths such as /docs or /cart). Local pages are Local, browser pages such as chrome://newtab are Browser and the rest is Other. The file is read on every call, so edits apply right away.
+
+Arguments:
+- device (optional): Only tabs of this device: ADB serial, iOS device name, android or ios (default: all cached devices)
+- query (optional): Only tabs matching this query in the syntax of search_tabs
+- category (optional): Only list this category
+- summary (optional): Only count the tabs per category (default: false)
+- format (optional): Output format: json, yaml or markdown (default: the configured format or json)
+
+Larger categories come first and Other comes last. The categories can be used as the category filter of search_tabs and close_tabs_bulk.`, s.categorizeTabs)
+	if err != nil {
+		return fmt.Errorf("failed to register categorize_tabs: %w", err)
+	}
+
 	return nil
 }
 
 // registerResources registers MCP resources
-func (s *TabTransferServer) registerResources() error {
+func (s *TabTransferServer) registerResources(server *mcp_golang.Server) error {
 	// Resource: Current tabs in YAML format only
-	err := s.server.RegisterResource("tabs://current", "current_tabs", "Currently loaded tabs (YAML format)", "application/x-yaml", s.getCurrentTabsYAML)
+	err := server.RegisterResource("tabs://current", "current_tabs", "Currently loaded tabs of all devices (YAML format)", "application/x-yaml", s.getCurrentTabsYAML)
 	if err != nil {
 		return fmt.Errorf("failed to register current_tabs resource: %w", err)
 	}
@@ -326,68 +652,97 @@ func (s *TabTransferServer) registerResources() error {
 
 // AndroidTabsArgs represents arguments for Android tab copying
 type AndroidTabsArgs struct {
-	Port        int    `json:"port" jsonschema:"description=Port for ADB forwarding (default: 9222)"`
-	Socket      string `json:"socket" jsonschema:"description=ADB socket name (default: chrome_devtools_remote)"`
-	Timeout     int    `json:"timeout" jsonschema:"description=Network timeout in seconds (default: 10)"`
-	Wait        int    `json:"wait" jsonschema:"description=Wait time before starting in seconds (default: 2)"`
+	Serial      string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Port        int    `json:"port" jsonschema:"description=Port for ADB forwarding (default: from the profile or 9222)"`
+	Socket      string `json:"socket" jsonschema:"description=ADB socket name (default: from the profile or chrome_devtools_remote)"`
+	Timeout     int    `json:"timeout" jsonschema:"description=Network timeout in seconds (default: from the profile or 10)"`
+	Wait        int    `json:"wait" jsonschema:"description=Wait time before starting in seconds (default: from the profile or 2)"`
 	SkipCleanup bool   `json:"skipCleanup" jsonschema:"description=Skip ADB cleanup after operation"`
 	Debug       bool   `json:"debug" jsonschema:"description=Enable debug output"`
-	Format      string `json:"format" jsonschema:"description=Output format: json or yaml (default: json)"`
+	Format      string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+	Query       string `json:"query" jsonschema:"description=Only return tabs matching this search query in the syntax of search_tabs"`
+	Limit       int    `json:"limit" jsonschema:"description=Maximum number of tabs per page (default: 50 or $TAB_PAGE_SIZE)"`
+	Cursor      string `json:"cursor" jsonschema:"description=Cursor from a previous call to get the next page"`
 }
 
 // IOSTabsArgs represents arguments for iOS tab copying
 type IOSTabsArgs struct {
-	Port    int    `json:"port" jsonschema:"description=Port for iOS WebKit Debug Proxy (default: 9222)"`
-	Timeout int    `json:"timeout" jsonschema:"description=Network timeout in seconds (default: 10)"`
-	Wait    int    `json:"wait" jsonschema:"description=Wait time before starting in seconds (default: 2)"`
+	Port    int    `json:"port" jsonschema:"description=Port for iOS WebKit Debug Proxy (default: from the profile or 9222)"`
+	Timeout int    `json:"timeout" jsonschema:"description=Network timeout in seconds (default: from the profile or 10)"`
+	Wait    int    `json:"wait" jsonschema:"description=Wait time before starting in seconds (default: from the profile or 2)"`
 	Debug   bool   `json:"debug" jsonschema:"description=Enable debug output"`
-	Format  string `json:"format" jsonschema:"description=Output format: json or yaml (default: json)"`
+	Format  string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+	Query   string `json:"query" jsonschema:"description=Only return tabs matching this search query in the syntax of search_tabs"`
+	Limit   int    `json:"limit" jsonschema:"description=Maximum number of tabs per page (default: 50 or $TAB_PAGE_SIZE)"`
+	Cursor  string `json:"cursor" jsonschema:"description=Cursor from a previous call to get the next page"`
 }
 
 // ReopenTabsArgs represents arguments for tab restoration
 type ReopenTabsArgs struct {
-	TabsJSON    string `json:"tabsJson" jsonschema:"required,description=JSON string containing tabs to restore"`
+	TabsJSON    string `json:"tabsJson" jsonschema:"description=JSON string containing tabs to restore"`
+	SnapshotId  string `json:"snapshotId" jsonschema:"description=Restore the tabs of this stored snapshot instead of tabsJson (or latest)"`
 	Platform    string `json:"platform" jsonschema:"required,description=Target platform (android or ios)"`
-	Port        int    `json:"port" jsonschema:"description=Port for device communication (default: 9222)"`
-	Timeout     int    `json:"timeout" jsonschema:"description=Network timeout in seconds (default: 10)"`
+	Serial      string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Port        int    `json:"port" jsonschema:"description=Port for device communication (default: from the profile or 9222)"`
+	Timeout     int    `json:"timeout" jsonschema:"description=Network timeout in seconds (default: from the profile or 10)"`
 	Debug       bool   `json:"debug" jsonschema:"description=Enable debug output"`
+	Format      string `json:"format" jsonschema:"description=Format of the per-tab result: json or yaml (default: the configured format or json)"`
+	Call        string `json:"_call,omitempty" jsonschema:"-"`
 }
 
 // CheckEnvironmentArgs represents arguments for environment checking
 type CheckEnvironmentArgs struct {
-	Platform string `json:"platform" jsonschema:"description=Platform: android, ios, or all"`
+	Platform   string `json:"platform" jsonschema:"description=Platform: android, ios, or all"`
+	Serial     string `json:"serial" jsonschema:"description=ADB device serial or profile name to check (default: any attached device)"`
+	ADBBackend string `json:"adbBackend" jsonschema:"description=ADB backend: auto or exec or native (default: the profile's backend or $ADB_BACKEND or auto)"`
+}
+
+// ListDevicesArgs represents arguments for listing Android devices
+type ListDevicesArgs struct {
+	ADBBackend string `json:"adbBackend" jsonschema:"description=ADB backend: auto or exec or native (default: $ADB_BACKEND or auto)"`
+	Format     string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
 }
 
 // copyTabsAndroid implements the Android tab copying tool
 func (s *TabTransferServer) copyTabsAndroid(args AndroidTabsArgs) (*mcp_golang.ToolResponse, error) {
-	// Set defaults
-	if args.Port == 0 {
-		args.Port = 9222
+	cursor, err := format.ParseCursor(args.Cursor)
+	if err != nil {
+		return nil, err
 	}
-	if args.Socket == "" {
-		args.Socket = "chrome_devtools_remote"
+
+	// Later pages are read from the snapshot saved by the first call
+	if cursor.Snapshot != "" {
+		snap, err := s.snapshots.Load(cursor.Snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tabs of cursor: %w", err)
+		}
+		return s.copiedTabsResponse("Android", snap.Tabs, args.Query, cursor, args.Limit, args.Format, nil)
+	}
+
+	// Arguments override the device profile and the defaults
+	profile, err := s.config.Resolve(args.Serial, "android")
+	if err != nil {
+		return nil, err
 	}
-	if args.Timeout == 0 {
-		args.Timeout = 10
+	if args.Port != 0 {
+		profile.Port = args.Port
 	}
-	if args.Wait == 0 {
-		args.Wait = 2
+	if args.Socket != "" {
+		profile.Socket = args.Socket
 	}
-
-	config := driver.AndroidConfig{
-		DriverConfig: driver.DriverConfig{
-			Port:    args.Port,
-			Timeout: time.Duration(args.Timeout) * time.Second,
-			Debug:   args.Debug,
-		},
-		Socket:      args.Socket,
-		Wait:        time.Duration(args.Wait) * time.Second,
-		SkipCleanup: args.SkipCleanup,
+	if args.Timeout != 0 {
+		profile.Timeout = args.Timeout
+	}
+	if args.Wait != 0 {
+		profile.Wait = args.Wait
 	}
 
+	config := profile.AndroidConfig(args.Debug)
+	config.SkipCleanup = args.SkipCleanup
+
 	androidDriver := driver.NewAndroidDriver(config)
 	
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(args.Timeout+10)*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(profile.Timeout+10)*time.Second)
 	defer cancel()
 
 	// Start driver
@@ -402,50 +757,50 @@ func (s *TabTransferServer) copyTabsAndroid(args AndroidTabsArgs) (*mcp_golang.T
 		return nil, fmt.Errorf("failed to load tabs: %w", err)
 	}
 
-	// Determine output format
-	outputFormat := format.FormatJSON
-	if args.Format != "" {
-		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
-			outputFormat = parsedFormat
-		}
-	}
-
-	// Format tabs according to specified format
-	formatter := format.NewTabFormatter(outputFormat)
-	formattedTabs, err := formatter.FormatTabs(tabs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to format tabs: %w", err)
+	// Keep the cache and the snapshot history up to date
+	diff, added := s.storeTabs("android", androidDriver.Device(), androidDriver.Browser(), tabs)
+	meta := s.cacheChanged("android", androidDriver.Device(), tabs, diff, added, false)
+	if meta != nil {
+		cursor.Snapshot = meta.ID
 	}
 
-	result := fmt.Sprintf("Successfully copied %d tabs from Android device (format: %s):\n\n%s", len(tabs), outputFormat, formattedTabs)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+	return s.copiedTabsResponse("Android", tabs, args.Query, cursor, args.Limit, args.Format, meta)
 }
 
 // copyTabsIOS implements the iOS tab copying tool
 func (s *TabTransferServer) copyTabsIOS(args IOSTabsArgs) (*mcp_golang.ToolResponse, error) {
-	// Set defaults
-	if args.Port == 0 {
-		args.Port = 9222
-	}
-	if args.Timeout == 0 {
-		args.Timeout = 10
+	cursor, err := format.ParseCursor(args.Cursor)
+	if err != nil {
+		return nil, err
 	}
-	if args.Wait == 0 {
-		args.Wait = 2
+
+	// Later pages are read from the snapshot saved by the first call
+	if cursor.Snapshot != "" {
+		snap, err := s.snapshots.Load(cursor.Snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tabs of cursor: %w", err)
+		}
+		return s.copiedTabsResponse("iOS", snap.Tabs, args.Query, cursor, args.Limit, args.Format, nil)
 	}
 
-	config := driver.IOSConfig{
-		DriverConfig: driver.DriverConfig{
-			Port:    args.Port,
-			Timeout: time.Duration(args.Timeout) * time.Second,
-			Debug:   args.Debug,
-		},
-		Wait: time.Duration(args.Wait) * time.Second,
+	// Arguments override the default iOS profile and the defaults
+	profile, err := s.config.Resolve("", "ios")
+	if err != nil {
+		return nil, err
+	}
+	if args.Port != 0 {
+		profile.Port = args.Port
+	}
+	if args.Timeout != 0 {
+		profile.Timeout = args.Timeout
+	}
+	if args.Wait != 0 {
+		profile.Wait = args.Wait
 	}
 
-	iosDriver := driver.NewIOSDriver(config)
+	iosDriver := driver.NewIOSDriver(profile.IOSConfig(args.Debug))
 	
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(args.Timeout+10)*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(profile.Timeout+10)*time.Second)
 	defer cancel()
 
 	// Start driver
@@ -460,99 +815,108 @@ func (s *TabTransferServer) copyTabsIOS(args IOSTabsArgs) (*mcp_golang.ToolRespo
 		return nil, fmt.Errorf("failed to load tabs: %w", err)
 	}
 
-	// Determine output format
-	outputFormat := format.FormatJSON
-	if args.Format != "" {
-		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
-			outputFormat = parsedFormat
-		}
+	// Keep the cache and the snapshot history up to date
+	diff, added := s.storeTabs("ios", iosDriver.Device(), iosDriver.Browser(), tabs)
+	meta := s.cacheChanged("ios", iosDriver.Device(), tabs, diff, added, false)
+	if meta != nil {
+		cursor.Snapshot = meta.ID
 	}
 
-	// Format tabs according to specified format
-	formatter := format.NewTabFormatter(outputFormat)
-	formattedTabs, err := formatter.FormatTabs(tabs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to format tabs: %w", err)
-	}
-
-	result := fmt.Sprintf("Successfully copied %d tabs from iOS device (format: %s):\n\n%s", len(tabs), outputFormat, formattedTabs)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+	return s.copiedTabsResponse("iOS", tabs, args.Query, cursor, args.Limit, args.Format, meta)
 }
 
 // reopenTabs implements the tab restoration tool
 func (s *TabTransferServer) reopenTabs(args ReopenTabsArgs) (*mcp_golang.ToolResponse, error) {
-	// Parse tabs JSON
+	// Parse tabs JSON or load them from a snapshot
 	var tabs []loader.Tab
-	if err := json.Unmarshal([]byte(args.TabsJSON), &tabs); err != nil {
-		return nil, fmt.Errorf("failed to parse tabs JSON: %w", err)
+	switch {
+	case args.SnapshotId != "":
+		snap, err := s.snapshots.Load(args.SnapshotId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot: %w", err)
+		}
+		tabs = snap.Tabs
+	case args.TabsJSON != "":
+		if err := json.Unmarshal([]byte(args.TabsJSON), &tabs); err != nil {
+			return nil, fmt.Errorf("failed to parse tabs JSON: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("either tabsJson or snapshotId is required")
 	}
 
-	// Set defaults
-	if args.Port == 0 {
-		args.Port = 9222
+	if args.Platform != "android" && args.Platform != "ios" {
+		return nil, fmt.Errorf("unsupported platform: %s (use 'android' or 'ios')", args.Platform)
 	}
-	if args.Timeout == 0 {
-		args.Timeout = 10
+
+	// Arguments override the device profile and the defaults
+	profile, err := s.config.Resolve(args.Serial, args.Platform)
+	if err != nil {
+		return nil, err
+	}
+	if args.Port != 0 {
+		profile.Port = args.Port
+	}
+	if args.Timeout != 0 {
+		profile.Timeout = args.Timeout
 	}
 
-	timeout := time.Duration(args.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout+10*time.Second)
+	// Restoring reports progress and stops when the client cancels the call;
+	// the timeout grows with the number of tabs
+	call := s.lookupCall(args.Call)
+	timeout := profile.BatchTimeout(len(tabs))
+	ctx, cancel := context.WithTimeout(call.Context(), timeout)
 	defer cancel()
 
-	var err error
-	var result string
+	result, summary, err := s.restoreTabs(ctx, call, args.Platform, profile, tabs, args.Debug, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.batchResponse(summary, result, args.Format)
+}
 
-	switch args.Platform {
+// restoreTabs opens tabs on the device of a profile, reporting progress to the
+// tool call, and returns the per-tab result with a one-line summary. timeout
+// is the one of ctx, for the summary of a batch that ran out of time.
+func (s *TabTransferServer) restoreTabs(ctx context.Context, call *toolCall, platform string, profile config.Profile, tabs []loader.Tab, debug bool, timeout time.Duration) (*loader.BatchResult, string, error) {
+	switch platform {
 	case "android":
-		config := driver.AndroidConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    args.Port,
-				Timeout: timeout,
-				Debug:   args.Debug,
-			},
-			Socket: "chrome_devtools_remote",
-			Wait:   2 * time.Second,
-		}
-		
-		androidDriver := driver.NewAndroidDriver(config)
-		if err = androidDriver.Start(ctx); err != nil {
-			return nil, fmt.Errorf("failed to start Android driver: %w", err)
+		androidConfig := profile.AndroidConfig(debug)
+		androidConfig.Progress = call.Progress
+		androidDriver := driver.NewAndroidDriver(androidConfig)
+		if err := androidDriver.Start(ctx); err != nil {
+			return nil, "", fmt.Errorf("failed to start Android driver: %w", err)
 		}
-		defer androidDriver.Stop(ctx)
+		defer androidDriver.Stop(context.WithoutCancel(ctx))
 		
-		if err = androidDriver.RestoreTabs(ctx, tabs); err != nil {
-			return nil, fmt.Errorf("failed to restore tabs: %w", err)
+		result, err := androidDriver.RestoreTabs(ctx, tabs)
+		var batchErr *loader.BatchError
+		switch {
+		case errors.As(err, &batchErr):
+			return result, batchStopped(batchErr, timeout) + " on Android device", nil
+		case err != nil:
+			return nil, "", fmt.Errorf("failed to restore tabs: %w", err)
 		}
-		
-		result = fmt.Sprintf("Successfully restored %d tabs to Android device", len(tabs))
+		return result, batchSummary(result, "restored", "to Android device"), nil
 
 	case "ios":
-		config := driver.IOSConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    args.Port,
-				Timeout: timeout,
-				Debug:   args.Debug,
-			},
-			Wait: 2 * time.Second,
+		iosConfig := profile.IOSConfig(debug)
+		iosConfig.Progress = call.Progress
+		iosDriver := driver.NewIOSDriver(iosConfig)
+		if err := iosDriver.Start(ctx); err != nil {
+			return nil, "", fmt.Errorf("failed to start iOS driver: %w", err)
 		}
+		defer iosDriver.Stop(context.WithoutCancel(ctx))
 		
-		iosDriver := driver.NewIOSDriver(config)
-		if err = iosDriver.Start(ctx); err != nil {
-			return nil, fmt.Errorf("failed to start iOS driver: %w", err)
-		}
-		defer iosDriver.Stop(ctx)
-		
-		if err = iosDriver.RestoreTabs(ctx, tabs); err != nil {
-			return nil, fmt.Errorf("failed to restore tabs: %w", err)
+		result, err := iosDriver.RestoreTabs(ctx, tabs)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to restore tabs: %w", err)
 		}
-		
-		result = fmt.Sprintf("Successfully initiated restoration of %d tabs to iOS device via WebSocket client", len(tabs))
+		return result, fmt.Sprintf("Successfully initiated restoration of %d tabs to iOS device via WebSocket client", len(tabs)), nil
 
 	default:
-		return nil, fmt.Errorf("unsupported platform: %s (use 'android' or 'ios')", args.Platform)
+		return nil, "", fmt.Errorf("unsupported platform: %s (use 'android' or 'ios')", platform)
 	}
-
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
 }
 
 // checkEnvironment implements the environment checking tool
@@ -565,15 +929,37 @@ func (s *TabTransferServer) checkEnvironment(args CheckEnvironmentArgs) (*mcp_go
 	}
 
 	if checkPlatform == "all" || checkPlatform == "android" {
-		// Check ADB installation
-		if err := platform.CheckADBAvailable(); err != nil {
+		// Check the ADB backend the tools use: the adb binary, or the adb
+		// server for the native backend
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		
+		profile, err := s.config.Resolve(args.Serial, "android")
+		if args.ADBBackend != "" {
+			profile.ADBBackend = args.ADBBackend
+		}
+		var backend adb.Backend
+		if err == nil {
+			backend, err = adb.NewBackend(profile.ADBBackend, profile.TimeoutDuration(), false)
+		}
+		if err == nil {
+			err = backend.CheckAvailable(ctx)
+		}
+		if err != nil {
 			results["android_adb"] = fmt.Sprintf("❌ ADB: %v", err)
 		} else {
-			results["android_adb"] = "✅ ADB: Available and working"
+			results["android_adb"] = fmt.Sprintf("✅ ADB (%s backend): Available and working", backend.Name())
 		}
 		
 		// Check Android device connection
-		if err := platform.CheckADBDeviceConnected(); err != nil {
+		if err == nil {
+			var devices []platform.ADBDevice
+			devices, err = backend.Devices(ctx)
+			if err == nil {
+				err = platform.CheckADBDevices(devices, profile.Serial)
+			}
+		}
+		if err != nil {
 			results["android_device"] = fmt.Sprintf("❌ Android Device: %v", err)
 		} else {
 			results["android_device"] = "✅ Android Device: Connected and authorized"
@@ -625,77 +1011,164 @@ func (s *TabTransferServer) checkEnvironment(args CheckEnvironmentArgs) (*mcp_go
 	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(resultText)), nil
 }
 
+// listDevices implements the Android device listing tool
+func (s *TabTransferServer) listDevices(args ListDevicesArgs) (*mcp_golang.ToolResponse, error) {
+	backend, err := adb.NewBackend(args.ADBBackend, 10*time.Second, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	devices, err := backend.Devices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if args.Format != "" {
+		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	formattedDevices, err := format.NewTabFormatter(outputFormat).FormatData(devices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format devices: %w", err)
+	}
+
+	result := fmt.Sprintf("Found %d Android devices (format: %s):\n\n%s", len(devices), outputFormat, formattedDevices)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
+
 // RefreshTabCacheArgs represents arguments for cache refresh
 type RefreshTabCacheArgs struct {
-	// No arguments needed for cache refresh
+	Serial string `json:"serial" jsonschema:"description=Only refresh this Android device (default: all attached Android and iOS devices)"`
 }
 
 // refreshTabCache implements the tab cache refresh tool
 func (s *TabTransferServer) refreshTabCache(args RefreshTabCacheArgs) (*mcp_golang.ToolResponse, error) {
-	if err := s.fetchAndCacheAndroidTabs(); err != nil {
-		return nil, fmt.Errorf("failed to refresh tab cache: %w", err)
+	var refreshed []deviceRefresh
+	var refreshErr error
+
+	if args.Serial != "" {
+		diff, err := s.fetchAndCacheAndroidTabs(args.Serial, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh tab cache: %w", err)
+		}
+		refreshed = append(refreshed, deviceRefresh{Platform: "android", Device: args.Serial, Diff: diff})
+	} else {
+		refreshed, refreshErr = s.refreshAllTabCaches(false)
+		if len(refreshed) == 0 {
+			if refreshErr == nil {
+				refreshErr = fmt.Errorf("no Android or iOS devices found")
+			}
+			return nil, fmt.Errorf("failed to refresh tab cache: %w", refreshErr)
+		}
 	}
 	
-	s.cacheMutex.RLock()
-	cacheCount := len(s.tabCache)
-	lastUpdate := s.lastUpdated.Format("2006-01-02 15:04:05")
-	s.cacheMutex.RUnlock()
-	
-	result := fmt.Sprintf("✅ Tab cache refreshed successfully!\n\nCached %d tabs\nLast updated: %s", cacheCount, lastUpdate)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+	var result strings.Builder
+	result.WriteString("✅ Tab cache refreshed successfully!\n\n")
+	for _, entry := range s.cacheEntries("") {
+		result.WriteString(fmt.Sprintf("%s %s (%s): %d tabs, updated %s\n",
+			entry.Platform, entry.Device, entry.Browser, entry.TabCount, entry.LastUpdated.Format("2006-01-02 15:04:05")))
+	}
+	result.WriteString("\nChanges since last refresh:\n")
+	for _, refresh := range refreshed {
+		result.WriteString("- " + refresh.String() + "\n")
+	}
+	if refreshErr != nil {
+		result.WriteString(fmt.Sprintf("\n⚠️ Some devices could not be refreshed:\n%v\n", refreshErr))
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result.String())), nil
 }
 
 // CacheStatusArgs represents arguments for cache status checking
 type CacheStatusArgs struct {
-	// No arguments needed for cache status
+	Device string `json:"device" jsonschema:"description=Only show this device: ADB serial or iOS device name or android or ios (default: all devices)"`
 }
 
 // CloseTabArgs represents arguments for single tab closing
 type CloseTabArgs struct {
 	TabId    string `json:"tabId" jsonschema:"required,description=Unique tab ID to close"`
 	Platform string `json:"platform" jsonschema:"description=Target platform: android or ios (default: android)"`
+	Serial   string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
 	Confirm  bool   `json:"confirm" jsonschema:"description=Skip confirmation prompt (default: false)"`
+	Call     string `json:"_call,omitempty" jsonschema:"-"`
 }
 
 // CloseTabsBulkArgs represents arguments for bulk tab closing
 type CloseTabsBulkArgs struct {
-	TabIds      []string `json:"tabIds" jsonschema:"description=Array of specific tab IDs to close"`
-	Platform    string   `json:"platform" jsonschema:"description=Target platform: android or ios (default: android)"`
-	FilterUrl   string   `json:"filterUrl" jsonschema:"description=Close tabs matching URL pattern (supports wildcards)"`
-	FilterTitle string   `json:"filterTitle" jsonschema:"description=Close tabs matching title pattern (supports wildcards)"`
-	Confirm     bool     `json:"confirm" jsonschema:"description=Skip confirmation prompt (default: false)"`
-	DryRun      bool     `json:"dryRun" jsonschema:"description=Preview operation without actually closing tabs (default: false)"`
+	TabIds       []string `json:"tabIds" jsonschema:"description=Array of specific tab IDs to close"`
+	Platform     string   `json:"platform" jsonschema:"description=Target platform: android or ios (default: android)"`
+	Serial       string   `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Query        string   `json:"query" jsonschema:"description=Close tabs matching this search query in the syntax of search_tabs (e.g. host:youtube.com -title:music)"`
+	Category     string   `json:"category" jsonschema:"description=Close tabs in this category of categorize_tabs (e.g. Shopping)"`
+	FilterUrl    string   `json:"filterUrl" jsonschema:"description=Close tabs whose whole URL matches this glob (e.g. *.google.com/*) or regex"`
+	FilterTitle  string   `json:"filterTitle" jsonschema:"description=Close tabs whose whole title matches this glob (e.g. *docs*) or regex"`
+	FilterHost   string   `json:"filterHost" jsonschema:"description=Close tabs whose host name matches this glob (e.g. *.example.com) or regex"`
+	PathPrefix   string   `json:"pathPrefix" jsonschema:"description=Close tabs whose URL path starts with this (e.g. /watch)"`
+	QueryParam   string   `json:"queryParam" jsonschema:"description=Close tabs whose URL has this query parameter: key or key=value"`
+	MatchMode    string   `json:"matchMode" jsonschema:"description=How the filter and exclude patterns are read: glob (default) or regex"`
+	ExcludeUrl   []string `json:"excludeUrl" jsonschema:"description=Never close tabs whose URL matches one of these patterns"`
+	ExcludeTitle []string `json:"excludeTitle" jsonschema:"description=Never close tabs whose title matches one of these patterns"`
+	Confirm      bool     `json:"confirm" jsonschema:"description=Skip confirmation prompt (default: false)"`
+	DryRun       bool     `json:"dryRun" jsonschema:"description=Preview operation without actually closing tabs (default: false)"`
+	Format       string   `json:"format" jsonschema:"description=Format of the per-tab result: json or yaml (default: the configured format or json)"`
+	Call         string   `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// ActivateTabArgs represents arguments for bringing a tab to the front
+type ActivateTabArgs struct {
+	TabId  string `json:"tabId" jsonschema:"required,description=Unique tab ID to activate"`
+	Serial string `json:"serial" jsonschema:"description=ADB device serial or profile name (default: the default profile or the single USB-attached device)"`
+	Call   string `json:"_call,omitempty" jsonschema:"-"`
 }
 
 // SearchTabsArgs represents arguments for tab searching
 type SearchTabsArgs struct {
-	Query  string `json:"query" jsonschema:"description=Search query to match against URLs and titles"`
-	Domain string `json:"domain" jsonschema:"description=Filter by specific domain (e.g. github.com)"`
-	Title  string `json:"title" jsonschema:"description=Search specifically in tab titles"`
-	URL    string `json:"url" jsonschema:"description=Search specifically in URLs"`
-	Limit  int    `json:"limit" jsonschema:"description=Maximum number of results to return (default: 10)"`
-	Format string `json:"format" jsonschema:"description=Output format: json or yaml (default: json)"`
+	Query    string `json:"query" jsonschema:"description=Search query: words and quoted phrases with title: url: host: and device: prefixes and OR and -negation and globs and /regexes/"`
+	Domain   string `json:"domain" jsonschema:"description=Filter by specific domain (e.g. github.com)"`
+	Title    string `json:"title" jsonschema:"description=Search specifically in tab titles"`
+	URL      string `json:"url" jsonschema:"description=Search specifically in URLs"`
+	Device   string `json:"device" jsonschema:"description=Only search this device: ADB serial or iOS device name or android or ios (default: all devices)"`
+	Category string `json:"category" jsonschema:"description=Only tabs in this category of categorize_tabs (e.g. Docs)"`
+	Limit    int    `json:"limit" jsonschema:"description=Maximum number of results per page (default: 10)"`
+	Cursor   string `json:"cursor" jsonschema:"description=Cursor from a previous search to get the next page"`
+	Format   string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
 }
 
 // cacheStatus implements the cache status tool
 func (s *TabTransferServer) cacheStatus(args CacheStatusArgs) (*mcp_golang.ToolResponse, error) {
-	s.cacheMutex.RLock()
-	cacheCount := len(s.tabCache)
-	cacheSize := s.cacheSize
-	lastUpdate := s.lastUpdated
-	s.cacheMutex.RUnlock()
+	entries := s.cacheEntries(args.Device)
+	cacheCount := 0
+	var lastUpdate time.Time
+	for _, entry := range entries {
+		cacheCount += entry.TabCount
+		if entry.LastUpdated.After(lastUpdate) {
+			lastUpdate = entry.LastUpdated
+		}
+	}
+	pageSize := s.pageSize
 	
 	var statusText strings.Builder
 	statusText.WriteString("📊 Tab Cache Status\n\n")
 	statusText.WriteString(fmt.Sprintf("📱 Cached Tabs: %d\n", cacheCount))
-	statusText.WriteString(fmt.Sprintf("🎯 Cache Limit: %d\n", cacheSize))
+	statusText.WriteString(fmt.Sprintf("📄 Page Size: %d\n", pageSize))
+	if s.refreshInterval > 0 {
+		statusText.WriteString(fmt.Sprintf("🔄 Auto-refresh: every %s\n", s.refreshInterval))
+	} else {
+		statusText.WriteString("🔄 Auto-refresh: disabled\n")
+	}
 	
 	if lastUpdate.IsZero() {
 		statusText.WriteString("⏰ Last Updated: Never (cache not populated)\n")
 		statusText.WriteString("📊 Status: Empty - use refresh_tab_cache tool to populate\n")
 	} else {
 		statusText.WriteString(fmt.Sprintf("⏰ Last Updated: %s\n", lastUpdate.Format("2006-01-02 15:04:05")))
-		statusText.WriteString(fmt.Sprintf("📊 Status: Active (%d/%d tabs)\n", cacheCount, cacheSize))
+		statusText.WriteString(fmt.Sprintf("📊 Status: Active (%d tabs from %d devices)\n", cacheCount, len(entries)))
 		
 		// Show age of cache
 		age := time.Since(lastUpdate)
@@ -706,6 +1179,12 @@ func (s *TabTransferServer) cacheStatus(args CacheStatusArgs) (*mcp_golang.ToolR
 		} else {
 			statusText.WriteString(fmt.Sprintf("🔴 Cache Age: %d hours\n", int(age.Hours())))
 		}
+
+		statusText.WriteString("\nDevices:\n")
+		for _, entry := range entries {
+			statusText.WriteString(fmt.Sprintf("- %s %s (%s): %d tabs, updated %s\n",
+				entry.Platform, entry.Device, entry.Browser, entry.TabCount, entry.LastUpdated.Format("2006-01-02 15:04:05")))
+		}
 	}
 	
 	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(statusText.String())), nil
@@ -713,25 +1192,9 @@ func (s *TabTransferServer) cacheStatus(args CacheStatusArgs) (*mcp_golang.ToolR
 
 // getCurrentTabsYAML implements the current tabs resource (YAML format only)
 func (s *TabTransferServer) getCurrentTabsYAML() (*mcp_golang.ResourceResponse, error) {
-	// Return cached tabs with thread safety
-	s.cacheMutex.RLock()
-	cachedTabs := make([]loader.Tab, len(s.tabCache))
-	copy(cachedTabs, s.tabCache)
-	s.cacheMutex.RUnlock()
-	
-	formatter := format.YAMLFormatter()
-	tabsData, err := formatter.FormatTabs(cachedTabs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to format cached tabs as YAML: %w", err)
-	}
-	
-	resource := mcp_golang.NewTextEmbeddedResource(
-		"tabs://current",
-		tabsData,
-		formatter.GetMimeType(),
-	)
-	
-	return mcp_golang.NewResourceResponse(resource), nil
+	// Return the first page of the merged cached tabs of all devices; the
+	// other pages are registered as tabs://current/page/{n}
+	return s.currentTabsPage(1)()
 }
 
 // closeTab implements the single tab closing tool
@@ -747,9 +1210,9 @@ func (s *TabTransferServer) closeTab(args CloseTabArgs) (*mcp_golang.ToolRespons
 		return nil, fmt.Errorf("tabId is required")
 	}
 	
-	// Safety confirmation (unless explicitly confirmed)
-	if !args.Confirm {
-		confirmText := fmt.Sprintf("⚠️ WARNING: You are about to permanently close tab:\nID: %s\nPlatform: %s\n\nThis action cannot be undone. To proceed, call this tool again with confirm=true.", args.TabId, platform)
+	// Safety confirmation (unless explicitly confirmed or not required by the safety policy)
+	if !args.Confirm && s.config.Safety.ConfirmRequired() {
+		confirmText := fmt.Sprintf("⚠️ WARNING: You are about to close tab:\nID: %s\nPlatform: %s\n\nThe tab can be reopened with undo_close. To proceed, call this tool again with confirm=true.", args.TabId, platform)
 		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(confirmText)), nil
 	}
 	
@@ -758,65 +1221,83 @@ func (s *TabTransferServer) closeTab(args CloseTabArgs) (*mcp_golang.ToolRespons
 		return nil, fmt.Errorf("tab closing is supported for Android and iOS platforms only")
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	profile, err := s.config.Resolve(args.Serial, platform)
+	if err != nil {
+		return nil, err
+	}
+	
+	ctx, cancel, err := s.deviceContext(s.lookupCall(args.Call).Context(), args.Serial, platform)
+	if err != nil {
+		return nil, err
+	}
 	defer cancel()
 	
-	var err error
 	var result string
 	
 	switch platform {
 	case "android":
 		// Setup Android driver
-		config := driver.AndroidConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    9222,
-				Timeout: 10 * time.Second,
-				Debug:   true,
-			},
-			Socket: "chrome_devtools_remote",
-			Wait:   2 * time.Second,
-		}
-		
-		androidDriver := driver.NewAndroidDriver(config)
+		androidDriver := driver.NewAndroidDriver(profile.AndroidConfig(true))
 		
 		// Start driver
 		if err = androidDriver.Start(ctx); err != nil {
 			return nil, fmt.Errorf("failed to start Android driver: %w", err)
 		}
-		defer androidDriver.Stop(ctx)
+		defer androidDriver.Stop(context.WithoutCancel(ctx))
+		
+		tabs, err := androidDriver.LoadTabs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load current Android tabs: %w", err)
+		}
+		if err = s.checkProtected(tabs, args.TabId); err != nil {
+			return nil, err
+		}
+		
+		// Record the tab in the trash first so that undo_close can reopen it
+		entries, err := s.closedTabs.Record(platform, "close_tab", tabs, []string{args.TabId})
+		if err != nil {
+			return nil, err
+		}
 		
 		// Close the tab
 		if err = androidDriver.CloseTab(ctx, args.TabId); err != nil {
+			s.closedTabs.ForgetUnclosed(entries, nil)
 			return nil, fmt.Errorf("failed to close Android tab: %w", err)
 		}
 		
-		result = fmt.Sprintf("✅ Successfully closed Android tab: %s", args.TabId)
+		result = fmt.Sprintf("✅ Successfully closed Android tab: %s%s", args.TabId, undoNote(entries))
 		
 	case "ios":
 		// Setup iOS driver
-		config := driver.IOSConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    9222,
-				Timeout: 10 * time.Second,
-				Debug:   true,
-			},
-			Wait: 2 * time.Second,
-		}
-		
-		iosDriver := driver.NewIOSDriver(config)
+		iosDriver := driver.NewIOSDriver(profile.IOSConfig(true))
 		
 		// Start driver
 		if err = iosDriver.Start(ctx); err != nil {
 			return nil, fmt.Errorf("failed to start iOS driver: %w", err)
 		}
-		defer iosDriver.Stop(ctx)
+		defer iosDriver.Stop(context.WithoutCancel(ctx))
+		
+		tabs, err := iosDriver.LoadTabs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load current iOS tabs: %w", err)
+		}
+		if err = s.checkProtected(tabs, args.TabId); err != nil {
+			return nil, err
+		}
+		
+		// Record the tab in the trash first so that undo_close can reopen it
+		entries, err := s.closedTabs.Record(platform, "close_tab", tabs, []string{args.TabId})
+		if err != nil {
+			return nil, err
+		}
 		
 		// Close the tab
 		if err = iosDriver.CloseTab(ctx, args.TabId); err != nil {
+			s.closedTabs.ForgetUnclosed(entries, nil)
 			return nil, fmt.Errorf("failed to close iOS tab: %w", err)
 		}
 		
-		result = fmt.Sprintf("✅ Successfully closed iOS tab: %s", args.TabId)
+		result = fmt.Sprintf("✅ Successfully closed iOS tab: %s%s", args.TabId, undoNote(entries))
 	}
 	
 	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
@@ -835,33 +1316,63 @@ func (s *TabTransferServer) closeTabsBulk(args CloseTabsBulkArgs) (*mcp_golang.T
 		return nil, fmt.Errorf("bulk tab closing is supported for Android and iOS platforms only")
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	filter, err := match.NewTabFilter(match.FilterOptions{
+		Mode:         args.MatchMode,
+		URL:          args.FilterUrl,
+		Title:        args.FilterTitle,
+		Host:         args.FilterHost,
+		PathPrefix:   args.PathPrefix,
+		Query:        args.QueryParam,
+		ExcludeURL:   args.ExcludeUrl,
+		ExcludeTitle: args.ExcludeTitle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	q, err := query.Parse(args.Query)
+	if err != nil {
+		return nil, err
+	}
+	var categorizer *category.Categorizer
+	if args.Category != "" {
+		if categorizer, err = s.categorizer(); err != nil {
+			return nil, err
+		}
+	}
+	
+	// Exclusions and negated query terms alone would select every other tab,
+	// and so would a query with an OR branch of negations only
+	if len(args.TabIds) == 0 && !filter.HasCriteria() && !q.Selective() && args.Category == "" {
+		return nil, fmt.Errorf("no tabs selected: pass tabIds, a filter (filterUrl, filterTitle, filterHost, pathPrefix or queryParam), a category or a query whose every OR branch has a term that is not negated")
+	}
+	
+	profile, err := s.config.Resolve(args.Serial, platform)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Closing reports progress and stops when the client cancels the call.
+	// Starting the driver and loading the tabs take the operation timeout of
+	// the profile, which includes its wait; closing gets its own timeout.
+	call := s.lookupCall(args.Call)
+	ctx, cancel := context.WithTimeout(call.Context(), profile.OperationTimeout())
 	defer cancel()
 	
 	var currentTabs []loader.Tab
-	var err error
-	var closeFunc func(context.Context, []string) error
+	var closeFunc func(context.Context, []string) (*loader.BatchResult, error)
 	
 	switch platform {
 	case "android":
-		// Setup Android driver
-		config := driver.AndroidConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    9222,
-				Timeout: 10 * time.Second,
-				Debug:   args.DryRun, // Enable debug for dry run to see what would happen
-			},
-			Socket: "chrome_devtools_remote",
-			Wait:   2 * time.Second,
-		}
-		
-		androidDriver := driver.NewAndroidDriver(config)
+		// Setup Android driver, with debug output for dry runs to see what would happen
+		androidConfig := profile.AndroidConfig(args.DryRun)
+		androidConfig.Progress = call.Progress
+		androidDriver := driver.NewAndroidDriver(androidConfig)
 		
 		// Start driver
 		if err = androidDriver.Start(ctx); err != nil {
 			return nil, fmt.Errorf("failed to start Android driver: %w", err)
 		}
-		defer androidDriver.Stop(ctx)
+		defer androidDriver.Stop(context.WithoutCancel(ctx))
 		
 		// Load current tabs to apply filters
 		currentTabs, err = androidDriver.LoadTabs(ctx)
@@ -873,22 +1384,15 @@ func (s *TabTransferServer) closeTabsBulk(args CloseTabsBulkArgs) (*mcp_golang.T
 		
 	case "ios":
 		// Setup iOS driver
-		config := driver.IOSConfig{
-			DriverConfig: driver.DriverConfig{
-				Port:    9222,
-				Timeout: 10 * time.Second,
-				Debug:   args.DryRun,
-			},
-			Wait: 2 * time.Second,
-		}
-		
-		iosDriver := driver.NewIOSDriver(config)
+		iosConfig := profile.IOSConfig(args.DryRun)
+		iosConfig.Progress = call.Progress
+		iosDriver := driver.NewIOSDriver(iosConfig)
 		
 		// Start driver
 		if err = iosDriver.Start(ctx); err != nil {
 			return nil, fmt.Errorf("failed to start iOS driver: %w", err)
 		}
-		defer iosDriver.Stop(ctx)
+		defer iosDriver.Stop(context.WithoutCancel(ctx))
 		
 		// Load current tabs to apply filters
 		currentTabs, err = iosDriver.LoadTabs(ctx)
@@ -901,37 +1405,58 @@ func (s *TabTransferServer) closeTabsBulk(args CloseTabsBulkArgs) (*mcp_golang.T
 	
 	// Determine which tabs to close
 	var tabsToClose []string
+	var unknown []string
+	excluded := 0
 	
 	if len(args.TabIds) > 0 {
-		// Use provided tab IDs
-		tabsToClose = args.TabIds
+		// Use provided tab IDs that are open, except those matching the exclusions.
+		// Unknown IDs are reported rather than recorded in the trash without a URL.
+		known := make(map[string]loader.Tab, len(currentTabs))
+		for _, tab := range currentTabs {
+			known[tab.ID] = tab
+		}
+		for _, tabID := range args.TabIds {
+			tab, ok := known[tabID]
+			if !ok {
+				unknown = append(unknown, tabID)
+				continue
+			}
+			if filter.Excluded(tab) {
+				excluded++
+				continue
+			}
+			tabsToClose = append(tabsToClose, tabID)
+		}
 	} else {
 		// Apply filters to find tabs to close
 		for _, tab := range currentTabs {
-			shouldClose := true
-			
-			// Apply URL filter if provided
-			if args.FilterUrl != "" {
-				if !matchesPattern(tab.URL, args.FilterUrl) {
-					shouldClose = false
-				}
+			if !filter.MatchCriteria(tab) || !q.Match(tab) || (categorizer != nil && !categorizer.Is(tab, args.Category)) {
+				continue
 			}
-			
-			// Apply title filter if provided
-			if args.FilterTitle != "" {
-				if !matchesPattern(tab.Title, args.FilterTitle) {
-					shouldClose = false
-				}
-			}
-			
-			if shouldClose {
-				tabsToClose = append(tabsToClose, tab.ID)
+			if filter.Excluded(tab) {
+				excluded++
+				continue
 			}
+			tabsToClose = append(tabsToClose, tab.ID)
 		}
 	}
 	
+	// Never close tabs protected by the safety policy
+	var protected []string
+	tabsToClose, protected = s.withoutProtected(currentTabs, tabsToClose)
+	protectedNote := ""
+	if len(unknown) > 0 {
+		protectedNote = fmt.Sprintf("\n\n❓ Skipped %d tab IDs that are not open on the device: %s", len(unknown), strings.Join(unknown, ", "))
+	}
+	if excluded > 0 {
+		protectedNote += fmt.Sprintf("\n\n🚫 Skipped %d tabs matching excludeUrl or excludeTitle", excluded)
+	}
+	if len(protected) > 0 {
+		protectedNote += fmt.Sprintf("\n\n🛡️ Skipped %d protected tabs (safety.protectedUrls): %s", len(protected), strings.Join(protected, ", "))
+	}
+	
 	if len(tabsToClose) == 0 {
-		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("No tabs match the specified criteria.")), nil
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("No tabs match the specified criteria." + protectedNote)), nil
 	}
 	
 	// Dry run: just show what would be closed
@@ -950,33 +1475,136 @@ func (s *TabTransferServer) closeTabsBulk(args CloseTabsBulkArgs) (*mcp_golang.T
 		}
 		
 		preview.WriteString("To actually close these tabs, call this tool again with dryRun=false and confirm=true.")
+		preview.WriteString(protectedNote)
 		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(preview.String())), nil
 	}
 	
-	// Safety confirmation (unless explicitly confirmed)
-	if !args.Confirm {
-		confirmText := fmt.Sprintf("⚠️ WARNING: You are about to permanently close %d tabs on %s.\n\nThis action cannot be undone. To proceed, call this tool again with confirm=true.\n\nTip: Use dryRun=true first to preview which tabs will be closed.", len(tabsToClose), platform)
+	// Bulk limit of the safety policy
+	if limit := s.config.Safety.MaxBulkClose; limit > 0 && len(tabsToClose) > limit {
+		return nil, fmt.Errorf("refusing to close %d tabs at once: the safety policy allows at most %d (safety.maxBulkClose)", len(tabsToClose), limit)
+	}
+	
+	// Safety confirmation (unless explicitly confirmed or not required by the safety policy)
+	if !args.Confirm && s.config.Safety.ConfirmRequired() {
+		confirmText := fmt.Sprintf("⚠️ WARNING: You are about to close %d tabs on %s.\n\nThe tabs can be reopened with undo_close. To proceed, call this tool again with confirm=true.\n\nTip: Use dryRun=true first to preview which tabs will be closed.", len(tabsToClose), platform)
 		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(confirmText)), nil
 	}
 	
-	// Actually close the tabs
-	if err := closeFunc(ctx, tabsToClose); err != nil {
+	// Actually close the tabs, allowing time for every tab
+	timeout := profile.BatchTimeout(len(tabsToClose))
+	closeCtx, cancelClose := context.WithTimeout(call.Context(), timeout)
+	defer cancelClose()
+	
+	// Record the tabs in the trash first so that undo_close can reopen them
+	entries, err := s.closedTabs.Record(platform, "close_tabs_bulk", currentTabs, tabsToClose)
+	if err != nil {
+		return nil, err
+	}
+	
+	result, err := closeFunc(closeCtx, tabsToClose)
+	entries = s.closedTabs.ForgetUnclosed(entries, result)
+	var batchErr *loader.BatchError
+	var summary string
+	switch {
+	case errors.As(err, &batchErr):
+		summary = batchStopped(batchErr, timeout)
+	case err != nil:
 		return nil, fmt.Errorf("failed to close tabs: %w", err)
+	default:
+		summary = batchSummary(result, "closed", "on "+platform)
+	}
+	
+	return s.batchResponse(summary+protectedNote+undoNote(entries), result, args.Format)
+}
+
+// activateTab implements the tab activation tool
+func (s *TabTransferServer) activateTab(args ActivateTabArgs) (*mcp_golang.ToolResponse, error) {
+	if args.TabId == "" {
+		return nil, fmt.Errorf("tabId is required")
+	}
+	
+	ctx, cancel, err := s.deviceContext(s.lookupCall(args.Call).Context(), args.Serial, "android")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	
+	androidDriver, err := s.startAndroidDriver(ctx, args.Serial)
+	if err != nil {
+		return nil, err
+	}
+	defer androidDriver.Stop(context.WithoutCancel(ctx))
+	
+	if err := androidDriver.ActivateTab(ctx, args.TabId); err != nil {
+		return nil, fmt.Errorf("failed to activate Android tab: %w", err)
 	}
 	
-	result := fmt.Sprintf("✅ Successfully closed %d tabs", len(tabsToClose))
+	result := fmt.Sprintf("✅ Successfully activated Android tab: %s", args.TabId)
 	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
 }
 
-// matchesPattern checks if a string matches a pattern (supports wildcards)
-func matchesPattern(text, pattern string) bool {
-	// Simple wildcard matching - supports * as wildcard
-	if pattern == "*" {
-		return true
+// deviceContext returns the context of an operation on the device of a
+// profile (a profile name or serial): cancelled with parent, e.g. when the
+// client cancels the tool call, and bounded by the profile's operation timeout
+func (s *TabTransferServer) deviceContext(parent context.Context, serial, platform string) (context.Context, context.CancelFunc, error) {
+	profile, err := s.config.Resolve(serial, platform)
+	if err != nil {
+		return nil, nil, err
 	}
 	
-	// For now, simple contains check - can be enhanced later
-	return strings.Contains(strings.ToLower(text), strings.ToLower(pattern))
+	ctx, cancel := context.WithTimeout(parent, profile.OperationTimeout())
+	return ctx, cancel, nil
+}
+
+// startAndroidDriver starts an Android driver with the port, socket and
+// timeouts of the device profile (a profile name or serial) for single-tab
+// operations. The caller must stop the driver.
+func (s *TabTransferServer) startAndroidDriver(ctx context.Context, serial string) (*driver.AndroidDriver, error) {
+	profile, err := s.config.Resolve(serial, "android")
+	if err != nil {
+		return nil, err
+	}
+	
+	androidDriver := driver.NewAndroidDriver(profile.AndroidConfig(false))
+	if err := androidDriver.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start Android driver: %w", err)
+	}
+	
+	return androidDriver, nil
+}
+
+// checkProtected refuses to close a tab whose URL is protected by the safety policy
+func (s *TabTransferServer) checkProtected(tabs []loader.Tab, tabID string) error {
+	for _, tab := range tabs {
+		if tab.ID == tabID && s.config.Safety.Protected(tab.URL) {
+			return fmt.Errorf("tab %s (%s) is protected by the safety policy (safety.protectedUrls)", tabID, tab.URL)
+		}
+	}
+	return nil
+}
+
+// withoutProtected removes the tabs protected by the safety policy from the
+// tab IDs to close and returns the URLs of the removed tabs
+func (s *TabTransferServer) withoutProtected(tabs []loader.Tab, tabIDs []string) ([]string, []string) {
+	protectedIDs := make(map[string]string)
+	for _, tab := range tabs {
+		if s.config.Safety.Protected(tab.URL) {
+			protectedIDs[tab.ID] = tab.URL
+		}
+	}
+	if len(protectedIDs) == 0 {
+		return tabIDs, nil
+	}
+	
+	var kept, protected []string
+	for _, tabID := range tabIDs {
+		if url, ok := protectedIDs[tabID]; ok {
+			protected = append(protected, url)
+			continue
+		}
+		kept = append(kept, tabID)
+	}
+	return kept, protected
 }
 
 
@@ -986,109 +1614,68 @@ func (s *TabTransferServer) searchTabs(args SearchTabsArgs) (*mcp_golang.ToolRes
 	if args.Limit == 0 {
 		args.Limit = 10
 	}
+	cursor, err := format.ParseCursor(args.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	q, err := query.Parse(args.Query)
+	if err != nil {
+		return nil, err
+	}
+	var categorizer *category.Categorizer
+	if args.Category != "" {
+		if categorizer, err = s.categorizer(); err != nil {
+			return nil, err
+		}
+	}
 	
-	// Get cached tabs
-	s.cacheMutex.RLock()
-	cachedTabs := make([]loader.Tab, len(s.tabCache))
-	copy(cachedTabs, s.tabCache)
-	s.cacheMutex.RUnlock()
+	// Get cached tabs of the selected devices
+	cachedTabs := s.cachedTabs(args.Device)
 	
 	if len(cachedTabs) == 0 {
 		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("No tabs are currently cached. Use refresh_tab_cache tool to populate cache first.")), nil
 	}
 	
-	// Apply filters and calculate relevance scores
+	// The domain, title and url arguments narrow the query
+	q = q.With(query.FieldURL, args.Domain).With(query.FieldTitle, args.Title).With(query.FieldURL, args.URL)
+	
+	// Match the query, letting words match in page text and with typos
+	// through the search index, and rank the matches by BM25
+	ranker := s.searchIndex.Ranker(q)
 	var results []format.SearchResult
 	
 	for _, tab := range cachedTabs {
-		score := 0.0
-		matches := true
-		
-		// Apply domain filter
-		if args.Domain != "" {
-			if !strings.Contains(strings.ToLower(tab.URL), strings.ToLower(args.Domain)) {
-				matches = false
-			} else {
-				score += 2.0 // Domain match gets high score
-			}
+		if !q.MatchWith(tab, ranker.Match) {
+			continue
 		}
-		
-		// Apply title filter
-		if args.Title != "" {
-			if !strings.Contains(strings.ToLower(tab.Title), strings.ToLower(args.Title)) {
-				matches = false
-			} else {
-				score += 1.5 // Title match gets medium-high score
-			}
+		if categorizer != nil && !categorizer.Is(tab, args.Category) {
+			continue
 		}
 		
-		// Apply URL filter
-		if args.URL != "" {
-			if !strings.Contains(strings.ToLower(tab.URL), strings.ToLower(args.URL)) {
-				matches = false
-			} else {
-				score += 1.0 // URL match gets medium score
-			}
-		}
-		
-		// Apply general query filter
-		if args.Query != "" {
-			queryLower := strings.ToLower(args.Query)
-			titleMatch := strings.Contains(strings.ToLower(tab.Title), queryLower)
-			urlMatch := strings.Contains(strings.ToLower(tab.URL), queryLower)
-			
-			if !titleMatch && !urlMatch {
-				matches = false
-			} else {
-				if titleMatch {
-					score += 1.0
-				}
-				if urlMatch {
-					score += 0.5
-				}
-				
-				// Bonus for exact matches
-				if strings.EqualFold(tab.Title, args.Query) {
-					score += 2.0
-				}
-				
-				// Bonus for query appearing at the start
-				if strings.HasPrefix(strings.ToLower(tab.Title), queryLower) {
-					score += 1.0
-				}
-			}
-		}
-		
-		// If no filters provided, include all tabs with minimal score
-		if args.Query == "" && args.Domain == "" && args.Title == "" && args.URL == "" {
-			matches = true
-			score = 0.1
-		}
-		
-		if matches {
-			results = append(results, format.SearchResult{
-				Tab:   tab,
-				Score: score,
-			})
+		// Without a query every tab is listed with a minimal score
+		score := 0.1
+		if !q.Empty() {
+			score = math.Round(ranker.Score(tab)*1000) / 1000
 		}
+		results = append(results, format.SearchResult{
+			Tab:   tab,
+			Score: score,
+		})
 	}
 	
-	// Sort by relevance score (descending)
-	for i := 0; i < len(results)-1; i++ {
-		for j := i + 1; j < len(results); j++ {
-			if results[i].Score < results[j].Score {
-				results[i], results[j] = results[j], results[i]
-			}
-		}
-	}
+	// Sort by relevance score (descending). The sort is stable so that pages
+	// of the same search line up.
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
 	
-	// Limit results
-	if len(results) > args.Limit {
-		results = results[:args.Limit]
-	}
+	// Return one page of the results
+	page := format.Paginate(len(results), cursor, args.Limit)
+	total := len(results)
+	results = results[page.Start:page.End]
 	
 	// Determine output format
-	outputFormat := format.FormatJSON
+	outputFormat := s.defaultFormat
 	if args.Format != "" {
 		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
 			outputFormat = parsedFormat
@@ -1102,13 +1689,13 @@ func (s *TabTransferServer) searchTabs(args SearchTabsArgs) (*mcp_golang.ToolRes
 		if err != nil {
 			return nil, fmt.Errorf("failed to format search results as YAML: %w", err)
 		}
-		resultText = fmt.Sprintf("🔍 Found %d tabs matching search criteria (format: yaml):\n\n%s", len(results), yamlData)
+		resultText = fmt.Sprintf("🔍 Found %d tabs matching search criteria (%s, format: yaml):\n\n%s%s", total, page.Describe("results"), yamlData, page.Footer())
 	} else {
 		jsonData, err := format.JSONFormatter().FormatSearchResults(results)
 		if err != nil {
 			return nil, fmt.Errorf("failed to format search results as JSON: %w", err)
 		}
-		resultText = fmt.Sprintf("🔍 Found %d tabs matching search criteria (format: json):\n\n%s", len(results), jsonData)
+		resultText = fmt.Sprintf("🔍 Found %d tabs matching search criteria (%s, format: json):\n\n%s%s", total, page.Describe("results"), jsonData, page.Footer())
 	}
 	
 	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(resultText)), nil
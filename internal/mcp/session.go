@@ -0,0 +1,254 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// session is one connected client. Every session has its own MCP server on
+// its own transport; the tab cache, snapshots and settings are shared.
+type session struct {
+	server    *mcp_golang.Server
+	transport *sessionTransport
+}
+
+// tabResource is a resource registered while the server runs, such as the
+// tabs of a cached device or the screenshot of a cached tab
+type tabResource struct {
+	name        string
+	description string
+	mimeType    string
+	handler     func() (*mcp_golang.ResourceResponse, error)
+}
+
+// newSession serves a client on the transport with all tools and resources,
+// including the tab resources of the current cache contents
+func (s *TabTransferServer) newSession(tr transport.Transport) (*session, error) {
+	sessionTr := newSessionTransport(s, tr)
+	server := mcp_golang.NewServer(sessionTr)
+
+	// Register MCP tools
+	if err := s.registerTools(server); err != nil {
+		return nil, fmt.Errorf("failed to register tools: %w", err)
+	}
+
+	// Register MCP resources
+	if err := s.registerResources(server); err != nil {
+		return nil, fmt.Errorf("failed to register resources: %w", err)
+	}
+
+	// Register MCP prompts
+	if err := s.registerPrompts(server); err != nil {
+		return nil, fmt.Errorf("failed to register prompts: %w", err)
+	}
+
+	// Hold the resource lock so that no tab resource change is missed
+	// between registering the current ones and joining the sessions
+	s.resourceMutex.Lock()
+	defer s.resourceMutex.Unlock()
+
+	// The server does not run yet, so registering sends no notifications
+	for uri, resource := range s.tabResources {
+		if err := server.RegisterResource(uri, resource.name, resource.description, resource.mimeType, resource.handler); err != nil {
+			return nil, fmt.Errorf("failed to register resource %s: %w", uri, err)
+		}
+	}
+
+	// Start the server
+	if err := server.Serve(); err != nil {
+		return nil, fmt.Errorf("failed to serve: %w", err)
+	}
+
+	sess := &session{server: server, transport: sessionTr}
+	s.sessionMutex.Lock()
+	s.sessions[sess] = true
+	s.sessionMutex.Unlock()
+
+	return sess, nil
+}
+
+// closeSession forgets a session whose client went away and cancels its
+// running tool calls
+func (s *TabTransferServer) closeSession(sess *session) {
+	sess.transport.cancelCalls()
+
+	s.sessionMutex.Lock()
+	delete(s.sessions, sess)
+	s.sessionMutex.Unlock()
+}
+
+// allSessions returns the connected sessions
+func (s *TabTransferServer) allSessions() []*session {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	sessions := make([]*session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
+
+// notifyResourceUpdated tells every client that the content of a resource
+// changed. mcp-golang only sends list_changed notifications, so the
+// notification is written to the transports directly.
+func (s *TabTransferServer) notifyResourceUpdated(uri string) {
+	params, err := json.Marshal(map[string]string{"uri": uri})
+	if err != nil {
+		return
+	}
+
+	notification := &transport.BaseJSONRPCNotification{
+		Jsonrpc: "2.0",
+		Method:  "notifications/resources/updated",
+		Params:  params,
+	}
+	for _, sess := range s.allSessions() {
+		if err := sess.transport.Send(transport.NewBaseMessageNotification(notification)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send resource update notification for %s: %v\n", uri, err)
+		}
+	}
+}
+
+// sessionTransport wraps the transport of a session to handle what
+// mcp-golang does not: resource templates, progress notifications and
+// cancellation of tool calls
+type sessionTransport struct {
+	transport.Transport
+	server *TabTransferServer
+
+	mu    sync.Mutex
+	calls map[transport.RequestId]*toolCall
+	// While batching, resource list changes are only recorded, to be sent
+	// as one notification when the batch ends
+	batching    bool
+	listChanged bool
+}
+
+// newSessionTransport wraps the transport of a session
+func newSessionTransport(s *TabTransferServer, tr transport.Transport) *sessionTransport {
+	return &sessionTransport{
+		Transport: tr,
+		server:    s,
+		calls:     make(map[transport.RequestId]*toolCall),
+	}
+}
+
+// SetMessageHandler passes the messages the wrapper does not answer on to handler
+func (t *sessionTransport) SetMessageHandler(handler func(message *transport.BaseJsonRpcMessage)) {
+	t.Transport.SetMessageHandler(func(message *transport.BaseJsonRpcMessage) {
+		switch message.Type {
+		case transport.BaseMessageTypeJSONRPCRequestType:
+			request := message.JsonRpcRequest
+			switch request.Method {
+			case "resources/templates/list", "resources/read":
+				if t.handleResourceRequest(request) {
+					return
+				}
+			case "tools/call":
+				t.startCall(request)
+			}
+
+		case transport.BaseMessageTypeJSONRPCNotificationType:
+			if message.JsonRpcNotification.Method == "notifications/cancelled" {
+				t.cancelCall(message.JsonRpcNotification)
+			}
+		}
+		handler(message)
+	})
+}
+
+// Send finishes the tool call a response answers and sends the message.
+// Resource list changes are held back during a batch.
+func (t *sessionTransport) Send(message *transport.BaseJsonRpcMessage) error {
+	if id, ok := replyID(message); ok {
+		t.finishCall(id)
+	}
+	if message.Type == transport.BaseMessageTypeJSONRPCNotificationType && message.JsonRpcNotification.Method == resourceListChanged {
+		t.mu.Lock()
+		batching := t.batching
+		if batching {
+			t.listChanged = true
+		}
+		t.mu.Unlock()
+		if batching {
+			return nil
+		}
+	}
+	return t.Transport.Send(message)
+}
+
+// resourceListChanged is the notification mcp-golang sends for every
+// registered or deregistered resource
+const resourceListChanged = "notifications/resources/list_changed"
+
+// batchResourceChanges runs update, which registers and deregisters
+// resources, and sends a single resource list change notification for all
+// of its changes
+func (t *sessionTransport) batchResourceChanges(update func()) error {
+	t.mu.Lock()
+	t.batching = true
+	t.listChanged = false
+	t.mu.Unlock()
+
+	update()
+
+	t.mu.Lock()
+	t.batching = false
+	changed := t.listChanged
+	t.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return t.Transport.Send(transport.NewBaseMessageNotification(&transport.BaseJSONRPCNotification{
+		Jsonrpc: "2.0",
+		Method:  resourceListChanged,
+	}))
+}
+
+// replyID returns the request ID a response or error answers
+func replyID(message *transport.BaseJsonRpcMessage) (transport.RequestId, bool) {
+	switch message.Type {
+	case transport.BaseMessageTypeJSONRPCResponseType:
+		return message.JsonRpcResponse.Id, true
+	case transport.BaseMessageTypeJSONRPCErrorType:
+		return message.JsonRpcError.Id, true
+	}
+	return 0, false
+}
+
+// reply sends the result of a request, or its error
+func (t *sessionTransport) reply(id transport.RequestId, result any, err error) {
+	var message *transport.BaseJsonRpcMessage
+	if err == nil {
+		var data []byte
+		data, err = json.Marshal(result)
+		if err == nil {
+			message = transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{
+				Jsonrpc: "2.0",
+				Id:      id,
+				Result:  data,
+			})
+		}
+	}
+	if err != nil {
+		message = transport.NewBaseMessageError(&transport.BaseJSONRPCError{
+			Jsonrpc: "2.0",
+			Id:      id,
+			Error: transport.BaseJSONRPCErrorInner{
+				Code:    -32000, // Internal error, as mcp-golang reports handler errors
+				Message: err.Error(),
+			},
+		})
+	}
+
+	if err := t.Transport.Send(message); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to send response: %v\n", err)
+	}
+}
@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+)
+
+// ListSnapshotsArgs represents arguments for listing stored tab snapshots
+type ListSnapshotsArgs struct {
+	Platform string `json:"platform" jsonschema:"description=Only snapshots from this platform: android or ios"`
+	Device   string `json:"device" jsonschema:"description=Only snapshots from this device (ADB serial or iOS device name)"`
+	Since    string `json:"since" jsonschema:"description=Only snapshots captured at or after this time (RFC 3339 or YYYY-MM-DD)"`
+	Until    string `json:"until" jsonschema:"description=Only snapshots captured at or before this time (RFC 3339 or YYYY-MM-DD)"`
+	Limit    int    `json:"limit" jsonschema:"description=Maximum number of snapshots to return (default: 20)"`
+	Format   string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+}
+
+// LoadSnapshotArgs represents arguments for loading a stored tab snapshot
+type LoadSnapshotArgs struct {
+	SnapshotId string `json:"snapshotId" jsonschema:"required,description=Snapshot ID from list_snapshots or latest"`
+	Format     string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+}
+
+// DeleteSnapshotArgs represents arguments for deleting a stored tab snapshot
+type DeleteSnapshotArgs struct {
+	SnapshotId string `json:"snapshotId" jsonschema:"required,description=Snapshot ID to delete"`
+	Confirm    bool   `json:"confirm" jsonschema:"description=Skip confirmation prompt (default: false)"`
+}
+
+// saveSnapshot records fetched tabs in the snapshot store. Failures are only
+// logged so that a full disk never breaks a tab fetch.
+func (s *TabTransferServer) saveSnapshot(platform string, tabs []loader.Tab) *snapshot.Meta {
+	meta, err := s.snapshots.Save(platform, "mcp", tabs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save tab snapshot: %v\n", err)
+	}
+	return meta
+}
+
+// snapshotNote describes a saved snapshot for tool results
+func snapshotNote(meta *snapshot.Meta) string {
+	if meta == nil {
+		return ""
+	}
+	return fmt.Sprintf("\n\nSaved snapshot: %s", meta.ID)
+}
+
+// listSnapshots implements the snapshot listing tool
+func (s *TabTransferServer) listSnapshots(args ListSnapshotsArgs) (*mcp_golang.ToolResponse, error) {
+	since, until, err := snapshot.ParseRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	metas, err := s.snapshots.List(snapshot.Filter{
+		Platform: args.Platform,
+		Device:   args.Device,
+		Since:    since,
+		Until:    until,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if len(metas) == 0 {
+		result := fmt.Sprintf("📭 No snapshots found in %s", s.snapshots.Dir())
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+	}
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if args.Format != "" {
+		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	formattedSnapshots, err := format.NewTabFormatter(outputFormat).FormatData(metas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format snapshots: %w", err)
+	}
+
+	result := fmt.Sprintf("🗂️ Found %d snapshots (newest first, format: %s):\n\n%s", len(metas), outputFormat, formattedSnapshots)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
+
+// loadSnapshot implements the snapshot loading tool
+func (s *TabTransferServer) loadSnapshot(args LoadSnapshotArgs) (*mcp_golang.ToolResponse, error) {
+	if args.SnapshotId == "" {
+		return nil, fmt.Errorf("snapshotId is required")
+	}
+
+	snap, err := s.snapshots.Load(args.SnapshotId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if args.Format != "" {
+		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	formattedTabs, err := format.NewTabFormatter(outputFormat).FormatTabs(snap.Tabs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format tabs: %w", err)
+	}
+
+	result := fmt.Sprintf("🗂️ Snapshot %s: %d tabs from %s %s captured %s (format: %s):\n\n%s",
+		snap.ID, len(snap.Tabs), snap.Platform, snap.Device, snap.CapturedAt.Local().Format("2006-01-02 15:04:05"), outputFormat, formattedTabs)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
+
+// deleteSnapshot implements the snapshot deletion tool
+func (s *TabTransferServer) deleteSnapshot(args DeleteSnapshotArgs) (*mcp_golang.ToolResponse, error) {
+	if args.SnapshotId == "" {
+		return nil, fmt.Errorf("snapshotId is required")
+	}
+
+	if !args.Confirm {
+		confirmText := fmt.Sprintf("⚠️ WARNING: You are about to permanently delete snapshot:\nID: %s\n\nThis action cannot be undone. To proceed, call this tool again with confirm=true.", args.SnapshotId)
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(confirmText)), nil
+	}
+
+	if err := s.snapshots.Delete(args.SnapshotId); err != nil {
+		return nil, err
+	}
+
+	result := fmt.Sprintf("✅ Deleted snapshot: %s", args.SnapshotId)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
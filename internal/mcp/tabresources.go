@@ -0,0 +1,332 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/kazuph/mcp-android-chrome/internal/cdp"
+	"github.com/kazuph/mcp-android-chrome/internal/content"
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// resourceTemplate describes a family of resources by a URI template
+// (RFC 6570), as listed by resources/templates/list
+type resourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// resourceTemplates are the templates of the per-device and per-tab resources.
+// Any URI matching one of them can be read, whether it is listed or not.
+var resourceTemplates = []resourceTemplate{
+	{
+		URITemplate: "tabs://device/{device}",
+		Name:        "device_tabs",
+		Description: "Cached tabs of one device, by ADB serial or iOS device name (YAML format)",
+		MimeType:    "application/x-yaml",
+	},
+	{
+		URITemplate: "tabs://android/{serial}/{tabId}",
+		Name:        "android_tab",
+		Description: "Metadata of a cached Android tab (YAML format)",
+		MimeType:    "application/x-yaml",
+	},
+	{
+		URITemplate: "tabs://android/{serial}/{tabId}/content",
+		Name:        "android_tab_content",
+		Description: "Main content of an Android tab as Markdown, extracted when read",
+		MimeType:    "text/markdown",
+	},
+	{
+		URITemplate: "tabs://android/{serial}/{tabId}/screenshot",
+		Name:        "android_tab_screenshot",
+		Description: "PNG screenshot of an Android tab, captured when read",
+		MimeType:    "image/png",
+	},
+	{
+		URITemplate: "tabs://ios/{device}/{tabId}",
+		Name:        "ios_tab",
+		Description: "Metadata of a cached iOS tab (YAML format)",
+		MimeType:    "application/x-yaml",
+	},
+}
+
+// tabURI returns the resource URI of a tab, or of one of its sub-resources
+// (content or screenshot) when sub is set
+func tabURI(platform, device, tabID, sub string) string {
+	uri := fmt.Sprintf("tabs://%s/%s/%s", platform, url.PathEscape(device), url.PathEscape(tabID))
+	if sub != "" {
+		uri += "/" + sub
+	}
+	return uri
+}
+
+// parseTabURI splits a tab resource URI into its platform, device, tab ID and
+// sub-resource. Content and screenshot sub-resources exist for Android tabs only.
+func parseTabURI(uri string) (platform, device, tabID, sub string, ok bool) {
+	rest, found := strings.CutPrefix(uri, "tabs://")
+	if !found {
+		return "", "", "", "", false
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 && len(parts) != 4 {
+		return "", "", "", "", false
+	}
+
+	platform = parts[0]
+	if platform != "android" && platform != "ios" {
+		return "", "", "", "", false
+	}
+	if len(parts) == 4 {
+		sub = parts[3]
+		if platform != "android" || (sub != "content" && sub != "screenshot") {
+			return "", "", "", "", false
+		}
+	}
+
+	device, err := url.PathUnescape(parts[1])
+	if err != nil || device == "" {
+		return "", "", "", "", false
+	}
+	tabID, err = url.PathUnescape(parts[2])
+	if err != nil || tabID == "" {
+		return "", "", "", "", false
+	}
+	return platform, device, tabID, sub, true
+}
+
+// templateResource returns the handler of a resource matching one of the
+// resource templates, or false when the URI matches none
+func (s *TabTransferServer) templateResource(uri string) (func() (*mcp_golang.ResourceResponse, error), bool) {
+	if escaped, found := strings.CutPrefix(uri, "tabs://device/"); found {
+		device, err := url.PathUnescape(escaped)
+		if err != nil || device == "" || strings.Contains(escaped, "/") {
+			return nil, false
+		}
+		return s.deviceTabsResource(device), true
+	}
+
+	platform, device, tabID, sub, ok := parseTabURI(uri)
+	if !ok {
+		return nil, false
+	}
+	switch sub {
+	case "content":
+		return s.tabContentResource(device, tabID), true
+	case "screenshot":
+		return s.screenshotResource(device, tabID), true
+	default:
+		return s.tabMetadataResource(platform, device, tabID), true
+	}
+}
+
+// syncTabResources registers the pages of tabs://current, a tabs resource for
+// every cached device and a metadata resource for every cached tab, and
+// removes the ones registered for pages, devices and tabs that are gone. The
+// content and screenshot sub-resources of tabs are only published as templates
+// to keep the listing short.
+func (s *TabTransferServer) syncTabResources() {
+	wanted := make(map[string]tabResource)
+
+	// Pages of tabs://current after the first
+	for number := 2; number <= s.currentTabsPageCount(); number++ {
+		wanted[currentTabsPageURI(number)] = tabResource{
+			name:        fmt.Sprintf("current_tabs_page_%d", number),
+			description: fmt.Sprintf("Currently loaded tabs of all devices, page %d (YAML format)", number),
+			mimeType:    "application/x-yaml",
+			handler:     s.currentTabsPage(number),
+		}
+	}
+
+	for _, entry := range s.cacheEntries("") {
+		deviceURI := deviceResourceURI(entry.Device)
+		if _, ok := wanted[deviceURI]; !ok {
+			wanted[deviceURI] = tabResource{
+				name:        "device_tabs_" + entry.Device,
+				description: fmt.Sprintf("Cached tabs of %s device %s (YAML format)", entry.Platform, entry.Device),
+				mimeType:    "application/x-yaml",
+				handler:     s.deviceTabsResource(entry.Device),
+			}
+		}
+
+		for _, tab := range entry.Tabs {
+			name := tab.Title
+			if name == "" {
+				name = tab.URL
+			}
+			wanted[tabURI(entry.Platform, entry.Device, tab.ID, "")] = tabResource{
+				name:        name,
+				description: fmt.Sprintf("%s (%s device %s)", tab.URL, entry.Platform, entry.Device),
+				mimeType:    "application/x-yaml",
+				handler:     s.tabMetadataResource(entry.Platform, entry.Device, tab.ID),
+			}
+		}
+	}
+
+	s.resourceMutex.Lock()
+	defer s.resourceMutex.Unlock()
+
+	var removed, added []string
+	for uri := range s.tabResources {
+		if _, ok := wanted[uri]; !ok {
+			removed = append(removed, uri)
+		}
+	}
+	for uri := range wanted {
+		if _, ok := s.tabResources[uri]; !ok {
+			added = append(added, uri)
+		}
+	}
+	if len(removed) == 0 && len(added) == 0 {
+		return
+	}
+
+	// mcp-golang notifies the client of every single change; a refresh can
+	// change hundreds of tabs, so the changes go out as one notification
+	for _, sess := range s.allSessions() {
+		err := sess.transport.batchResourceChanges(func() {
+			for _, uri := range removed {
+				if err := sess.server.DeregisterResource(uri); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to deregister resource %s: %v\n", uri, err)
+				}
+			}
+			for _, uri := range added {
+				resource := wanted[uri]
+				if err := sess.server.RegisterResource(uri, resource.name, resource.description, resource.mimeType, resource.handler); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to register resource %s: %v\n", uri, err)
+				}
+			}
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send resource list change notification: %v\n", err)
+		}
+	}
+
+	for _, uri := range removed {
+		delete(s.tabResources, uri)
+	}
+	for _, uri := range added {
+		s.tabResources[uri] = wanted[uri]
+	}
+}
+
+// cachedTab looks up a tab in the cache of one device
+func (s *TabTransferServer) cachedTab(platform, device, tabID string) (loader.Tab, bool) {
+	for _, entry := range s.cacheEntries(device) {
+		if entry.Platform != platform || entry.Device != device {
+			continue
+		}
+		for _, tab := range entry.Tabs {
+			if tab.ID == tabID {
+				return tab, true
+			}
+		}
+	}
+	return loader.Tab{}, false
+}
+
+// tabMetadata is the content of a tab's metadata resource
+type tabMetadata struct {
+	Platform   string     `json:"platform" yaml:"platform"`
+	Tab        loader.Tab `json:"tab" yaml:"tab"`
+	Content    string     `json:"content,omitempty" yaml:"content,omitempty"`
+	Screenshot string     `json:"screenshot,omitempty" yaml:"screenshot,omitempty"`
+}
+
+// tabMetadataResource returns the handler of a tab's metadata resource. The
+// metadata comes from the cache and names the tab's sub-resources.
+func (s *TabTransferServer) tabMetadataResource(platform, device, tabID string) func() (*mcp_golang.ResourceResponse, error) {
+	return func() (*mcp_golang.ResourceResponse, error) {
+		tab, ok := s.cachedTab(platform, device, tabID)
+		if !ok {
+			return nil, fmt.Errorf("tab %s of %s device %s is not cached (refresh the tab cache first)", tabID, platform, device)
+		}
+
+		metadata := tabMetadata{Platform: platform, Tab: tab}
+		if platform == "android" {
+			metadata.Content = tabURI(platform, device, tabID, "content")
+			metadata.Screenshot = tabURI(platform, device, tabID, "screenshot")
+		}
+
+		formatter := format.YAMLFormatter()
+		data, err := formatter.FormatData(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format tab as YAML: %w", err)
+		}
+
+		resource := mcp_golang.NewTextEmbeddedResource(tabURI(platform, device, tabID, ""), data, formatter.GetMimeType())
+		return mcp_golang.NewResourceResponse(resource), nil
+	}
+}
+
+// tabContentResource returns the handler of an Android tab's content
+// resource: the main content as Markdown, extracted when read
+func (s *TabTransferServer) tabContentResource(serial, tabID string) func() (*mcp_golang.ResourceResponse, error) {
+	return func() (*mcp_golang.ResourceResponse, error) {
+		page, err := s.readTabContent(context.Background(), serial, tabID, content.ModeArticle, defaultContentLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		resource := mcp_golang.NewTextEmbeddedResource(tabURI("android", serial, tabID, "content"), contentText(page, defaultContentLimit), "text/markdown")
+		return mcp_golang.NewResourceResponse(resource), nil
+	}
+}
+
+// screenshotResource returns the handler of an Android tab's screenshot resource
+func (s *TabTransferServer) screenshotResource(serial, tabID string) func() (*mcp_golang.ResourceResponse, error) {
+	return func() (*mcp_golang.ResourceResponse, error) {
+		opts := cdp.ScreenshotOptions{Format: "png"}
+		data, err := s.captureScreenshot(context.Background(), serial, tabID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		resource := mcp_golang.NewBlobEmbeddedResource(tabURI("android", serial, tabID, "screenshot"), data, opts.MimeType())
+		return mcp_golang.NewResourceResponse(resource), nil
+	}
+}
+
+// handleResourceRequest answers the resource requests mcp-golang does not
+// know: resources/templates/list, and reads of resources that match a
+// template, which need not be registered, such as the content and screenshot
+// of a tab. It reports whether it answered the request.
+func (t *sessionTransport) handleResourceRequest(request *transport.BaseJSONRPCRequest) bool {
+	switch request.Method {
+	case "resources/templates/list":
+		t.reply(request.Id, map[string]any{"resourceTemplates": resourceTemplates}, nil)
+		return true
+
+	case "resources/read":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return false
+		}
+
+		handler, ok := t.server.templateResource(params.URI)
+		if !ok {
+			return false
+		}
+
+		// Reading a tab can take a while; don't hold up other requests
+		go func() {
+			response, err := handler()
+			t.reply(request.Id, response, err)
+		}()
+		return true
+	}
+	return false
+}
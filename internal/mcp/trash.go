@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+
+	"github.com/kazuph/mcp-android-chrome/internal/format"
+	"github.com/kazuph/mcp-android-chrome/internal/jsonstore"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/snapshot"
+	"github.com/kazuph/mcp-android-chrome/internal/trash"
+)
+
+// ListClosedTabsArgs represents arguments for listing the tabs in the trash
+type ListClosedTabsArgs struct {
+	Platform  string `json:"platform" jsonschema:"description=Only tabs closed on this platform: android or ios"`
+	Device    string `json:"device" jsonschema:"description=Only tabs closed on this device (ADB serial or iOS device name)"`
+	Operation string `json:"operation" jsonschema:"description=Only the tabs closed by this operation (or latest)"`
+	Since     string `json:"since" jsonschema:"description=Only tabs closed at or after this time (RFC 3339 or YYYY-MM-DD)"`
+	Until     string `json:"until" jsonschema:"description=Only tabs closed at or before this time (RFC 3339 or YYYY-MM-DD)"`
+	Limit     int    `json:"limit" jsonschema:"description=Maximum number of closed tabs to return (default: 20)"`
+	Format    string `json:"format" jsonschema:"description=Output format: json or yaml (default: the configured format or json)"`
+}
+
+// UndoCloseArgs represents arguments for reopening tabs from the trash
+type UndoCloseArgs struct {
+	EntryIds  []string `json:"entryIds" jsonschema:"description=IDs of closed tabs from list_closed_tabs"`
+	Operation string   `json:"operation" jsonschema:"description=Reopen every tab closed by this operation from list_closed_tabs (or latest)"`
+	Serial    string   `json:"serial" jsonschema:"description=Reopen on this ADB device serial or profile name instead of the device the tabs were closed on"`
+	Format    string   `json:"format" jsonschema:"description=Format of the per-tab result: json or yaml (default: the configured format or json)"`
+	Call      string   `json:"_call,omitempty" jsonschema:"-"`
+}
+
+// undoNote tells how to reopen the closed tabs recorded in the trash
+func undoNote(entries []trash.Entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n🗑️ Recorded in the trash: reopen with undo_close operation=%s", entries[0].Operation)
+}
+
+// listClosedTabs implements the trash listing tool
+func (s *TabTransferServer) listClosedTabs(args ListClosedTabsArgs) (*mcp_golang.ToolResponse, error) {
+	since, until, err := snapshot.ParseRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	operation := args.Operation
+	if operation == "latest" {
+		latest, err := s.closedTabs.List(trash.Filter{Filter: jsonstore.Filter{Limit: 1}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list closed tabs: %w", err)
+		}
+		if len(latest) > 0 {
+			operation = latest[0].Operation
+		}
+	}
+
+	entries, err := s.closedTabs.List(trash.Filter{
+		Filter: jsonstore.Filter{
+			Platform: args.Platform,
+			Device:   args.Device,
+			Since:    since,
+			Until:    until,
+			Limit:    limit,
+		},
+		Operation: operation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list closed tabs: %w", err)
+	}
+
+	if len(entries) == 0 {
+		result := fmt.Sprintf("📭 No closed tabs found in %s", s.closedTabs.Dir())
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+	}
+
+	// Determine output format
+	outputFormat := s.defaultFormat
+	if args.Format != "" {
+		if parsedFormat, err := format.ParseFormat(args.Format); err == nil {
+			outputFormat = parsedFormat
+		}
+	}
+
+	formattedEntries, err := format.NewTabFormatter(outputFormat).FormatData(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format closed tabs: %w", err)
+	}
+
+	result := fmt.Sprintf("🗑️ Found %d closed tabs (most recently closed first, format: %s):\n\n%s", len(entries), outputFormat, formattedEntries)
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(result)), nil
+}
+
+// undoClose implements the tool reopening tabs from the trash. The tabs are
+// restored on the devices they were closed on, one batch per device, and
+// leave the trash once they are open again.
+func (s *TabTransferServer) undoClose(args UndoCloseArgs) (*mcp_golang.ToolResponse, error) {
+	var entries []trash.Entry
+	var err error
+	switch {
+	case len(args.EntryIds) > 0 && args.Operation != "":
+		return nil, fmt.Errorf("pass either entryIds or operation, not both")
+	case len(args.EntryIds) > 0:
+		entries, err = s.closedTabs.Get(args.EntryIds)
+	case args.Operation != "":
+		entries, err = s.closedTabs.Operation(args.Operation)
+	default:
+		return nil, fmt.Errorf("either entryIds or operation is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// One batch per device, in the order the tabs were closed
+	type batch struct {
+		platform string
+		device   string
+		entries  []trash.Entry
+	}
+	var batches []*batch
+	byDevice := make(map[string]*batch)
+	for _, entry := range entries {
+		key := entry.Platform + "/" + entry.Device
+		if byDevice[key] == nil {
+			byDevice[key] = &batch{platform: entry.Platform, device: entry.Device}
+			batches = append(batches, byDevice[key])
+		}
+		byDevice[key].entries = append(byDevice[key].entries, entry)
+	}
+
+	call := s.lookupCall(args.Call)
+	var report strings.Builder
+	for _, b := range batches {
+		if report.Len() > 0 {
+			report.WriteString("\n\n")
+		}
+
+		device := args.Serial
+		if device == "" && b.platform == "android" {
+			device = b.device
+		}
+		if call.Context().Err() != nil {
+			fmt.Fprintf(&report, "⏹️ Not reopened on %s %s (cancelled by the client)", b.platform, b.device)
+			continue
+		}
+
+		text, err := s.undoBatch(call, b.platform, device, b.entries, args.Format)
+		if err != nil {
+			fmt.Fprintf(&report, "❌ Failed to reopen %d tabs on %s %s: %v", len(b.entries), b.platform, b.device, err)
+			continue
+		}
+		report.WriteString(text)
+	}
+
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(report.String())), nil
+}
+
+// undoBatch reopens the closed tabs of one device and removes the reopened
+// ones from the trash. It returns the summary and per-tab result.
+func (s *TabTransferServer) undoBatch(call *toolCall, platform, device string, entries []trash.Entry, formatStr string) (string, error) {
+	profile, err := s.config.Resolve(device, platform)
+	if err != nil {
+		return "", err
+	}
+
+	tabs := make([]loader.Tab, 0, len(entries))
+	for _, entry := range entries {
+		tabs = append(tabs, entry.Tab())
+	}
+
+	timeout := profile.BatchTimeout(len(tabs))
+	ctx, cancel := context.WithTimeout(call.Context(), timeout)
+	defer cancel()
+
+	result, summary, err := s.restoreTabs(ctx, call, platform, profile, tabs, false, timeout)
+	if err != nil {
+		return "", err
+	}
+
+	// The result lists the tabs in the order they were restored
+	var reopened []string
+	for i, tab := range result.Tabs {
+		if i < len(entries) && (tab.Status == loader.StatusRestored || tab.Status == loader.StatusRequested) {
+			reopened = append(reopened, entries[i].ID)
+		}
+	}
+	if err := s.closedTabs.Remove(reopened); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove reopened tabs from the trash: %v\n", err)
+	}
+
+	formattedResult, err := s.formatBatchResult(result, formatStr)
+	if err != nil {
+		return "", err
+	}
+	return summary + "\n\n" + formattedResult, nil
+}
@@ -132,28 +132,125 @@ func CheckADBAvailable() error {
 	return nil
 }
 
-// CheckADBDeviceConnected checks if any Android devices are connected
-func CheckADBDeviceConnected() error {
+// ADBDevice describes a single entry reported by `adb devices -l`
+type ADBDevice struct {
+	Serial      string `json:"serial" yaml:"serial"`
+	State       string `json:"state" yaml:"state"`
+	Model       string `json:"model,omitempty" yaml:"model,omitempty"`
+	Product     string `json:"product,omitempty" yaml:"product,omitempty"`
+	Device      string `json:"device,omitempty" yaml:"device,omitempty"`
+	TransportID string `json:"transportId,omitempty" yaml:"transportId,omitempty"`
+	USB         string `json:"usb,omitempty" yaml:"usb,omitempty"`
+}
+
+// ListADBDevices runs `adb devices -l` and returns the parsed device records
+func ListADBDevices() ([]ADBDevice, error) {
 	adbPath := FindADBPath()
-	cmd := exec.Command(adbPath, "devices")
+	cmd := exec.Command(adbPath, "devices", "-l")
 	output, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("failed to list ADB devices: %v", err)
+		return nil, fmt.Errorf("failed to list ADB devices: %v", err)
 	}
-	
-	lines := strings.Split(string(output), "\n")
-	deviceCount := 0
-	unauthorizedCount := 0
-	
-	for _, line := range lines[1:] { // Skip "List of devices attached" header
+
+	return ParseADBDevices(string(output)), nil
+}
+
+// ParseADBDevices parses the text format shared by `adb devices -l` and the
+// host:devices-l service. The "List of devices attached" header is optional.
+func ParseADBDevices(output string) []ADBDevice {
+	devices := make([]ADBDevice, 0)
+
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		if line == "" || strings.HasPrefix(line, "List of devices") || strings.HasPrefix(line, "*") {
 			continue
 		}
-		
-		if strings.Contains(line, "unauthorized") {
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		device := ADBDevice{Serial: fields[0]}
+		var state []string
+		for _, field := range fields[1:] {
+			key, value, found := strings.Cut(field, ":")
+			if !found {
+				state = append(state, field)
+				continue
+			}
+
+			switch key {
+			case "product":
+				device.Product = value
+			case "model":
+				device.Model = value
+			case "device":
+				device.Device = value
+			case "transport_id":
+				device.TransportID = value
+			case "usb":
+				device.USB = value
+			default:
+				// Free-form state text such as "no permissions (...; see [http://...])"
+				state = append(state, field)
+			}
+		}
+		device.State = strings.Join(state, " ")
+
+		devices = append(devices, device)
+	}
+
+	return devices
+}
+
+// CheckADBDeviceConnected checks if an Android device is connected and authorized.
+// When serial is empty any authorized device is accepted, otherwise the device
+// with the given serial must be present.
+func CheckADBDeviceConnected(serial string) error {
+	devices, err := ListADBDevices()
+	if err != nil {
+		return err
+	}
+
+	return CheckADBDevices(devices, serial)
+}
+
+// CheckADBDevices validates a device listing against an optional serial
+func CheckADBDevices(devices []ADBDevice, serial string) error {
+	if serial != "" {
+		for _, device := range devices {
+			if device.Serial != serial {
+				continue
+			}
+			switch device.State {
+			case "device":
+				return nil
+			case "unauthorized":
+				return fmt.Errorf("Android device %s found but unauthorized. Please:\n1. Check device screen for USB debugging prompt\n2. Tap 'Allow' to authorize this computer\n3. Ensure device is unlocked", serial)
+			default:
+				return fmt.Errorf("Android device %s is not ready (state: %s)", serial, device.State)
+			}
+		}
+
+		available := make([]string, 0, len(devices))
+		for _, device := range devices {
+			available = append(available, device.Serial)
+		}
+		if len(available) == 0 {
+			return fmt.Errorf("Android device %s not found: no devices attached", serial)
+		}
+		return fmt.Errorf("Android device %s not found. Attached devices: %s", serial, strings.Join(available, ", "))
+	}
+
+	deviceCount := 0
+	unauthorizedCount := 0
+
+	for _, device := range devices {
+		switch device.State {
+		case "unauthorized":
 			unauthorizedCount++
-		} else if strings.Contains(line, "device") {
+		case "device":
 			deviceCount++
 		}
 	}
@@ -0,0 +1,307 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/kazuph/mcp-android-chrome/internal/match"
+)
+
+// fields are the field prefixes of terms; domain is an alias of host
+var fields = map[string]string{
+	"title":  FieldTitle,
+	"url":    FieldURL,
+	"host":   FieldHost,
+	"domain": FieldHost,
+	"device": FieldDevice,
+}
+
+// token kinds
+const (
+	tokenTerm = iota
+	tokenOr
+	tokenAnd
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind int
+	term *Term
+	pos  int
+}
+
+// Parse parses a query. An empty or blank query matches every tab.
+func Parse(input string) (*Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return &Query{}, nil
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid query: unexpected %s at position %d", p.describe(p.tokens[p.pos]), p.tokens[p.pos].pos+1)
+	}
+	return &Query{Root: root}, nil
+}
+
+// parser is a recursive descent parser over the tokens of a query:
+//
+//	or    = and { "OR" and }
+//	and   = unary { [ "AND" ] unary }
+//	unary = ( "-" | "NOT" ) unary | "(" or ")" | term
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []Node{first}
+	for {
+		next, ok := p.peek()
+		if !ok || next.kind != tokenOr {
+			break
+		}
+		p.pos++
+		child, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &Or{Children: children}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var children []Node
+	for {
+		next, ok := p.peek()
+		if !ok || next.kind == tokenOr || next.kind == tokenClose {
+			break
+		}
+		if next.kind == tokenAnd {
+			p.pos++
+			continue
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	switch len(children) {
+	case 0:
+		if next, ok := p.peek(); ok {
+			return nil, fmt.Errorf("invalid query: expected a term before %s at position %d", p.describe(next), next.pos+1)
+		}
+		return nil, fmt.Errorf("invalid query: expected a term at the end")
+	case 1:
+		return children[0], nil
+	default:
+		return &And{Children: children}, nil
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	next, _ := p.peek()
+	p.pos++
+	switch next.kind {
+	case tokenNot:
+		if _, ok := p.peek(); !ok {
+			return nil, fmt.Errorf("invalid query: nothing to negate at the end")
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Child: child}, nil
+	case tokenOpen:
+		child, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokenClose {
+			return nil, fmt.Errorf("invalid query: missing ) for ( at position %d", next.pos+1)
+		}
+		p.pos++
+		return child, nil
+	case tokenTerm:
+		return next.term, nil
+	default:
+		return nil, fmt.Errorf("invalid query: unexpected %s at position %d", p.describe(next), next.pos+1)
+	}
+}
+
+// describe names a token in error messages
+func (p *parser) describe(t token) string {
+	switch t.kind {
+	case tokenOr:
+		return "OR"
+	case tokenAnd:
+		return "AND"
+	case tokenNot:
+		return "NOT"
+	case tokenOpen:
+		return "("
+	case tokenClose:
+		return ")"
+	default:
+		return fmt.Sprintf("%q", t.term.String())
+	}
+}
+
+// tokenize splits a query into tokens
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, pos: i})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, token{kind: tokenNot, pos: i})
+			i++
+		case r == '-':
+			// A lone - is a negation without a term; quote it to search for "-"
+			return nil, fmt.Errorf("invalid query: nothing to negate after - at position %d", i+1)
+		default:
+			term, end, err := readTerm(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case term == nil:
+				// Operators are only operators when written unquoted
+				word := string(runes[i:end])
+				kind := map[string]int{"OR": tokenOr, "AND": tokenAnd, "NOT": tokenNot}[word]
+				tokens = append(tokens, token{kind: kind, pos: i})
+			default:
+				tokens = append(tokens, token{kind: tokenTerm, term: term, pos: i})
+			}
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// readTerm reads the term starting at runes[start] and returns it with the
+// position after it. It returns a nil term for the operators OR, AND and NOT.
+func readTerm(runes []rune, start int) (*Term, int, error) {
+	i := start
+	field := FieldAny
+
+	// A field prefix is a known name followed by a colon
+	for j := i; j < len(runes) && (unicode.IsLetter(runes[j])); j++ {
+		if j+1 < len(runes) && runes[j+1] == ':' {
+			if name, ok := fields[strings.ToLower(string(runes[i:j+1]))]; ok {
+				field = name
+				i = j + 2
+			}
+			break
+		}
+	}
+
+	var value string
+	kind := KindText
+	switch {
+	case i < len(runes) && runes[i] == '"':
+		end := i + 1
+		var phrase strings.Builder
+		for ; end < len(runes) && runes[end] != '"'; end++ {
+			if runes[end] == '\\' && end+1 < len(runes) {
+				end++
+			}
+			phrase.WriteRune(runes[end])
+		}
+		if end == len(runes) {
+			return nil, 0, fmt.Errorf("invalid query: missing closing quote for the phrase at position %d", i+1)
+		}
+		value = phrase.String()
+		i = end + 1
+
+	case i < len(runes) && runes[i] == '/':
+		end := i + 1
+		var expr strings.Builder
+		for ; end < len(runes) && runes[end] != '/'; end++ {
+			if runes[end] == '\\' && end+1 < len(runes) && runes[end+1] == '/' {
+				end++
+			}
+			expr.WriteRune(runes[end])
+		}
+		if end == len(runes) {
+			return nil, 0, fmt.Errorf("invalid query: missing closing / for the regular expression at position %d", i+1)
+		}
+		value = expr.String()
+		kind = KindRegex
+		i = end + 1
+
+	default:
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' {
+			end++
+		}
+		value = string(runes[i:end])
+		if field == FieldAny {
+			switch value {
+			case "OR", "AND", "NOT":
+				return nil, end, nil
+			}
+		}
+		if strings.ContainsAny(value, "*?") {
+			kind = KindGlob
+		}
+		i = end
+	}
+
+	if value == "" {
+		return nil, 0, fmt.Errorf("invalid query: empty term at position %d", start+1)
+	}
+
+	term := &Term{Field: field, Kind: kind, Value: value}
+	switch kind {
+	case KindGlob:
+		pattern, err := match.Compile(value, match.ModeGlob)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid query: %w", err)
+		}
+		term.pattern = pattern
+	case KindRegex:
+		pattern, err := match.Compile(value, match.ModeRegex)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid query: %w", err)
+		}
+		term.pattern = pattern
+	}
+	return term, i, nil
+}
@@ -0,0 +1,278 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// tree renders the structure of a node, so that tests see how a query was
+// grouped: and(...), or(...), not(...) and field:kind:value for terms
+func tree(node Node) string {
+	switch n := node.(type) {
+	case *And:
+		return "and(" + trees(n.Children) + ")"
+	case *Or:
+		return "or(" + trees(n.Children) + ")"
+	case *Not:
+		return "not(" + tree(n.Child) + ")"
+	case *Term:
+		return n.Field + ":" + n.Kind + ":" + n.Value
+	default:
+		return "?"
+	}
+}
+
+func trees(nodes []Node) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		parts = append(parts, tree(node))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "youtube", want: ":text:youtube"},
+		{query: "youtube music", want: "and(:text:youtube :text:music)"},
+		{query: "a AND b", want: "and(:text:a :text:b)"},
+		{query: "a OR b c", want: "or(:text:a and(:text:b :text:c))"},
+		{query: "a b OR c", want: "or(and(:text:a :text:b) :text:c)"},
+		{query: "(a OR b) c", want: "and(or(:text:a :text:b) :text:c)"},
+		{query: "-a b", want: "and(not(:text:a) :text:b)"},
+		{query: "NOT a", want: "not(:text:a)"},
+		{query: "--a", want: "not(not(:text:a))"},
+		{query: "-(a OR b)", want: "not(or(:text:a :text:b))"},
+		{query: "a -b OR -c", want: "or(and(:text:a not(:text:b)) not(:text:c))"},
+		{query: "re-run", want: ":text:re-run"},
+		{query: `"hello world"`, want: ":text:hello world"},
+		{query: `"say \"hi\""`, want: `:text:say "hi"`},
+		{query: `"OR"`, want: ":text:OR"},
+		{query: `"-x"`, want: ":text:-x"},
+		{query: "title:news", want: "title:text:news"},
+		{query: "Title:news", want: "title:text:news"},
+		{query: `title:"breaking news"`, want: "title:text:breaking news"},
+		{query: "domain:github.com", want: "host:text:github.com"},
+		{query: "host:OR", want: "host:text:OR"},
+		{query: "device:R58M123ABC", want: "device:text:R58M123ABC"},
+		{query: "foo:bar", want: ":text:foo:bar"},
+		{query: "url:*.google.com/*", want: "url:glob:*.google.com/*"},
+		{query: "v?.txt", want: ":glob:v?.txt"},
+		{query: `/foo\/bar/`, want: ":regex:foo/bar"},
+		{query: `title:/^Re: \d+/`, want: `title:regex:^Re: \d+`},
+		{query: `/a\.b/`, want: `:regex:a\.b`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			if got := tree(q.Root); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	for _, input := range []string{"", "   "} {
+		q, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", input, err)
+		}
+		if !q.Empty() || !q.Match(loader.Tab{Title: "anything"}) {
+			t.Errorf("Parse(%q) = %q, want an empty query matching every tab", input, q.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{query: `"abc`, wantErr: "missing closing quote"},
+		{query: "/abc", wantErr: "missing closing /"},
+		{query: "(a", wantErr: "missing ) for ( at position 1"},
+		{query: "a)", wantErr: "unexpected ) at position 2"},
+		{query: "()", wantErr: "expected a term before ) at position 2"},
+		{query: "OR a", wantErr: "expected a term before OR at position 1"},
+		{query: "a OR", wantErr: "expected a term at the end"},
+		{query: "-", wantErr: "nothing to negate after - at position 1"},
+		{query: "a - b", wantErr: "nothing to negate after - at position 3"},
+		{query: "a -", wantErr: "nothing to negate after - at position 3"},
+		{query: "(a -)", wantErr: "nothing to negate after - at position 4"},
+		{query: "NOT", wantErr: "nothing to negate at the end"},
+		{query: "title:", wantErr: "empty term at position 1"},
+		{query: `""`, wantErr: "empty term"},
+		{query: "/(/", wantErr: "invalid regex"},
+		{query: "a[*", wantErr: "unterminated character class"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryString(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "a  AND   b", want: "a b"},
+		{query: "(a OR b) c", want: "(a OR b) c"},
+		{query: "a OR (b c)", want: "a OR b c"},
+		{query: "NOT (a b)", want: "-(a b)"},
+		{query: "-a", want: "-a"},
+		{query: `"hello world"`, want: `"hello world"`},
+		{query: `"say \"hi\""`, want: `"say \"hi\""`},
+		{query: `"OR"`, want: `"OR"`},
+		{query: `"-x"`, want: `"-x"`},
+		{query: `"/x"`, want: `"/x"`},
+		{query: "domain:github.com", want: "host:github.com"},
+		{query: `title:"a (b)"`, want: `title:"a (b)"`},
+		{query: `/foo\/bar/`, want: `/foo\/bar/`},
+		{query: "url:*.google.com/*", want: "url:*.google.com/*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			got := q.String()
+			if got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.query, got, tt.want)
+			}
+
+			// The rendered query parses back to the same query
+			again, err := Parse(got)
+			if err != nil {
+				t.Fatalf("Parse(%q) of the rendered query error = %v", got, err)
+			}
+			if tree(again.Root) != tree(q.Root) {
+				t.Errorf("Parse(%q) = %s, want %s", got, tree(again.Root), tree(q.Root))
+			}
+		})
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	tab := loader.Tab{
+		Title:  "GitHub - Issues",
+		URL:    "https://gist.github.com/org/repo/issues/1",
+		Device: "Pixel_7",
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "github", want: true},
+		{query: "GITHUB issues", want: true},
+		{query: "github -issues", want: false},
+		{query: "gitlab OR issues", want: true},
+		{query: "gitlab OR -issues", want: false},
+		{query: `"github - issues"`, want: true},
+		{query: "title:gist", want: false},
+		{query: "url:gist", want: true},
+		{query: "host:github.com", want: true},
+		{query: "host:hub.com", want: false},
+		{query: "host:*.github.com", want: true},
+		{query: "url:gist.github.com/*/issues/*", want: true},
+		{query: "title:GitHub*", want: true},
+		{query: "title:Issues*", want: false},
+		{query: `title:/^git/`, want: true},
+		{query: `url:/issues\/\d+$/`, want: true},
+		{query: "device:pixel_7", want: true},
+		{query: "device:pixel", want: false},
+		{query: "device:pixel*", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			if got := q.Match(tab); got != tt.want {
+				t.Errorf("Parse(%q).Match() = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryMatchWith(t *testing.T) {
+	tab := loader.Tab{Title: "YouTube Music", URL: "https://music.youtube.com/"}
+	// Treat the misspelling youtbe as youtube, as a fuzzy index would
+	also := func(term *Term, tab loader.Tab) bool {
+		return term.Value == "youtbe"
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "youtbe", want: true},
+		{query: "youtbe music", want: true},
+		{query: "youtbe -music", want: false},
+		{query: "-youtbe", want: true},
+		{query: "-(youtbe OR vimeo)", want: true},
+		{query: "vimeo OR youtbe", want: true},
+		{query: "vimeo", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			if got := q.MatchWith(tab, also); got != tt.want {
+				t.Errorf("Parse(%q).MatchWith() = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuerySelective(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "", want: false},
+		{query: "youtube", want: true},
+		{query: "youtube -music", want: true},
+		{query: "-music", want: false},
+		{query: "-music -video", want: false},
+		{query: "youtube OR -music", want: false},
+		{query: "youtube OR vimeo", want: true},
+		{query: "(youtube -music) OR vimeo", want: true},
+		{query: "-(youtube OR vimeo)", want: false},
+		{query: "--youtube", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			if got := q.Selective(); got != tt.want {
+				t.Errorf("Parse(%q).Selective() = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
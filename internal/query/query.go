@@ -0,0 +1,334 @@
+package query
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/match"
+)
+
+// Fields a term can be restricted to with a prefix such as title:
+const (
+	// No prefix: the title or the URL
+	FieldAny    = ""
+	FieldTitle  = "title"
+	FieldURL    = "url"
+	FieldHost   = "host"
+	FieldDevice = "device"
+)
+
+// Kinds of terms
+const (
+	// Case-insensitive substring, a bare word or a quoted phrase
+	KindText = "text"
+	// Glob matched against the whole field, a term with * or ?
+	KindGlob = "glob"
+	// Regular expression searched in the field, a term written as /re/
+	KindRegex = "regex"
+)
+
+// Node is a node of a parsed query
+type Node interface {
+	// Match reports whether a tab satisfies the node
+	Match(tab loader.Tab) bool
+	// String renders the node in query syntax
+	String() string
+}
+
+// And matches tabs that match all of its children
+type And struct {
+	Children []Node
+}
+
+// Or matches tabs that match any of its children
+type Or struct {
+	Children []Node
+}
+
+// Not matches tabs that do not match its child
+type Not struct {
+	Child Node
+}
+
+// Term matches one field of a tab against a value
+type Term struct {
+	Field string
+	Kind  string
+	Value string
+
+	pattern *match.Pattern
+}
+
+func (n *And) Match(tab loader.Tab) bool {
+	for _, child := range n.Children {
+		if !child.Match(tab) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *And) String() string {
+	parts := make([]string, 0, len(n.Children))
+	for _, child := range n.Children {
+		if _, ok := child.(*Or); ok {
+			parts = append(parts, "("+child.String()+")")
+		} else {
+			parts = append(parts, child.String())
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (n *Or) Match(tab loader.Tab) bool {
+	for _, child := range n.Children {
+		if child.Match(tab) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Or) String() string {
+	parts := make([]string, 0, len(n.Children))
+	for _, child := range n.Children {
+		parts = append(parts, child.String())
+	}
+	return strings.Join(parts, " OR ")
+}
+
+func (n *Not) Match(tab loader.Tab) bool {
+	return !n.Child.Match(tab)
+}
+
+func (n *Not) String() string {
+	if _, ok := n.Child.(*Term); ok {
+		return "-" + n.Child.String()
+	}
+	return "-(" + n.Child.String() + ")"
+}
+
+func (n *Term) Match(tab loader.Tab) bool {
+	switch n.Field {
+	case FieldTitle:
+		return n.matchText(tab.Title)
+	case FieldURL:
+		return n.matchURL(tab.URL)
+	case FieldHost:
+		return n.matchHost(tab.URL)
+	case FieldDevice:
+		if n.Kind == KindText {
+			return strings.EqualFold(tab.Device, n.Value)
+		}
+		return n.matchText(tab.Device)
+	default:
+		return n.matchText(tab.Title) || n.matchURL(tab.URL)
+	}
+}
+
+func (n *Term) String() string {
+	value := n.Value
+	switch {
+	case n.Kind == KindRegex:
+		value = "/" + strings.ReplaceAll(value, "/", `\/`) + "/"
+	case strings.ContainsAny(value, " \t()\"") || strings.HasPrefix(value, "-") || strings.HasPrefix(value, "/"),
+		n.Field == FieldAny && (value == "OR" || value == "AND" || value == "NOT"):
+		value = `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	if n.Field != FieldAny {
+		return n.Field + ":" + value
+	}
+	return value
+}
+
+// matchText matches a title, a device or another plain field
+func (n *Term) matchText(text string) bool {
+	if n.pattern != nil {
+		return n.pattern.Match(text)
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(n.Value))
+}
+
+// matchURL matches a URL. Globs may leave out the scheme, so that
+// github.com/*/issues/* matches https://github.com/org/repo/issues/1.
+func (n *Term) matchURL(rawURL string) bool {
+	if n.matchText(rawURL) {
+		return true
+	}
+	if n.Kind != KindGlob {
+		return false
+	}
+	if _, rest, found := strings.Cut(rawURL, "://"); found {
+		return n.pattern.Match(rest)
+	}
+	return false
+}
+
+// matchHost matches the host name of a URL. A plain host matches itself and
+// its subdomains: host:github.com matches gist.github.com too.
+func (n *Term) matchHost(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if n.Kind != KindText {
+		return n.pattern.Match(host)
+	}
+	value := strings.ToLower(n.Value)
+	return host == value || strings.HasSuffix(host, "."+value)
+}
+
+// Query is a parsed query. The zero Query matches every tab.
+type Query struct {
+	Root Node
+}
+
+// Empty reports whether the query has no terms
+func (q *Query) Empty() bool {
+	return q == nil || q.Root == nil
+}
+
+// Match reports whether a tab satisfies the query
+func (q *Query) Match(tab loader.Tab) bool {
+	if q.Empty() {
+		return true
+	}
+	return q.Root.Match(tab)
+}
+
+// String renders the query in normalized query syntax
+func (q *Query) String() string {
+	if q.Empty() {
+		return ""
+	}
+	return q.Root.String()
+}
+
+// Selective reports whether every tab the query matches must match a term
+// that is not negated. A query such as -music or youtube OR -music also
+// matches every tab that merely lacks a word, so it is not selective.
+func (q *Query) Selective() bool {
+	if q.Empty() {
+		return false
+	}
+	var positive func(node Node) bool
+	positive = func(node Node) bool {
+		switch n := node.(type) {
+		case *Term:
+			return true
+		case *And:
+			for _, child := range n.Children {
+				if positive(child) {
+					return true
+				}
+			}
+			return false
+		case *Or:
+			for _, child := range n.Children {
+				if !positive(child) {
+					return false
+				}
+			}
+			return true
+		default:
+			return false
+		}
+	}
+	return positive(q.Root)
+}
+
+// Terms returns the terms of the query that a matching tab may satisfy,
+// leaving out negated ones, for ranking
+func (q *Query) Terms() []*Term {
+	if q.Empty() {
+		return nil
+	}
+	var terms []*Term
+	var walk func(node Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case *And:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *Or:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *Term:
+			terms = append(terms, n)
+		}
+	}
+	walk(q.Root)
+	return terms
+}
+
+// Filter returns the tabs that satisfy the query, in their order
+func (q *Query) Filter(tabs []loader.Tab) []loader.Tab {
+	if q.Empty() {
+		return tabs
+	}
+	matching := []loader.Tab{}
+	for _, tab := range tabs {
+		if q.Match(tab) {
+			matching = append(matching, tab)
+		}
+	}
+	return matching
+}
+
+// MatchWith reports whether a tab satisfies the query when terms that are
+// not negated also match where also reports a match, e.g. a typo-tolerant
+// lookup in a search index. Negated terms match as in Match.
+func (q *Query) MatchWith(tab loader.Tab, also func(term *Term, tab loader.Tab) bool) bool {
+	if q.Empty() {
+		return true
+	}
+	var eval func(node Node, negated bool) bool
+	eval = func(node Node, negated bool) bool {
+		switch n := node.(type) {
+		case *And:
+			for _, child := range n.Children {
+				if !eval(child, negated) {
+					return false
+				}
+			}
+			return true
+		case *Or:
+			for _, child := range n.Children {
+				if eval(child, negated) {
+					return true
+				}
+			}
+			return false
+		case *Not:
+			return !eval(n.Child, !negated)
+		case *Term:
+			return n.Match(tab) || (!negated && also(n, tab))
+		default:
+			return node.Match(tab)
+		}
+	}
+	return eval(q.Root, false)
+}
+
+// With returns the query ANDed with a text term restricted to a field. An
+// empty value leaves the query as it is.
+func (q *Query) With(field, value string) *Query {
+	if value == "" {
+		return q
+	}
+	term := &Term{Field: field, Kind: KindText, Value: value}
+	switch {
+	case q.Empty():
+		return &Query{Root: term}
+	default:
+		if and, ok := q.Root.(*And); ok {
+			children := append(append([]Node{}, and.Children...), term)
+			return &Query{Root: &And{Children: children}}
+		}
+		return &Query{Root: &And{Children: []Node{q.Root, term}}}
+	}
+}
@@ -0,0 +1,249 @@
+package search
+
+import (
+	"math"
+	"sync"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// Fields of an indexed tab
+const (
+	FieldTitle = "title"
+	FieldURL   = "url"
+	// Page text read with get_tab_content, when it was read
+	FieldText = "text"
+)
+
+var fields = []string{FieldTitle, FieldURL, FieldText}
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// document is an indexed tab
+type document struct {
+	source string
+	title  string
+	url    string
+	// Term frequencies and token counts per field
+	terms   map[string]map[string]int
+	lengths map[string]int
+}
+
+// Index is an in-memory inverted index over the titles, URLs and page text
+// of the cached tabs. It is updated incrementally: only tabs whose title or
+// URL changed are indexed again. It is safe for concurrent use.
+type Index struct {
+	mu   sync.RWMutex
+	docs map[string]*document
+
+	// Documents containing a term, per field
+	postings map[string]map[string]map[string]int
+	// Number of documents with a non-empty field and their total length
+	fieldDocs    map[string]int
+	fieldLengths map[string]int
+
+	// Every indexed term with the number of postings using it, and the
+	// terms per trigram for typo-tolerant lookups
+	vocabulary map[string]int
+	trigrams   map[string]map[string]bool
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	ix := &Index{
+		docs:         make(map[string]*document),
+		postings:     make(map[string]map[string]map[string]int),
+		fieldDocs:    make(map[string]int),
+		fieldLengths: make(map[string]int),
+		vocabulary:   make(map[string]int),
+		trigrams:     make(map[string]map[string]bool),
+	}
+	for _, field := range fields {
+		ix.postings[field] = make(map[string]map[string]int)
+	}
+	return ix
+}
+
+// docKey identifies the document of a tab
+func docKey(device, tabID string) string {
+	return device + "\x00" + tabID
+}
+
+// Update replaces the tabs of one source, e.g. one browser on one device.
+// Tabs of the source that are gone are removed and new ones are added; tabs
+// with an unchanged title and URL keep their entries and their page text.
+func (ix *Index) Update(source string, tabs []loader.Tab) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	current := make(map[string]bool, len(tabs))
+	for _, tab := range tabs {
+		key := docKey(tab.Device, tab.ID)
+		current[key] = true
+
+		doc, ok := ix.docs[key]
+		if ok && doc.title == tab.Title && doc.url == tab.URL {
+			doc.source = source
+			continue
+		}
+
+		// A retitled tab keeps its page text, a navigated one loses it
+		var text map[string]int
+		if ok {
+			if doc.url == tab.URL {
+				text = doc.terms[FieldText]
+			}
+			ix.remove(key)
+		}
+		ix.add(key, &document{
+			source: source,
+			title:  tab.Title,
+			url:    tab.URL,
+			terms: map[string]map[string]int{
+				FieldTitle: termFrequencies(Tokenize(tab.Title)),
+				FieldURL:   termFrequencies(TokenizeURL(tab.URL)),
+				FieldText:  text,
+			},
+		})
+	}
+
+	for key, doc := range ix.docs {
+		if doc.source == source && !current[key] {
+			ix.remove(key)
+		}
+	}
+}
+
+// SetText indexes the page text of a tab. It is ignored for tabs that are
+// not indexed.
+func (ix *Index) SetText(device, tabID, text string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	key := docKey(device, tabID)
+	doc, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	ix.remove(key)
+	doc.terms[FieldText] = termFrequencies(Tokenize(text))
+	ix.add(key, doc)
+}
+
+// Len returns the number of indexed tabs
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// termFrequencies counts tokens
+func termFrequencies(tokens []string) map[string]int {
+	if len(tokens) == 0 {
+		return nil
+	}
+	frequencies := make(map[string]int, len(tokens))
+	for _, token := range tokens {
+		frequencies[token]++
+	}
+	return frequencies
+}
+
+// add indexes a document. The caller holds the write lock.
+func (ix *Index) add(key string, doc *document) {
+	doc.lengths = make(map[string]int, len(fields))
+	for _, field := range fields {
+		frequencies := doc.terms[field]
+		if len(frequencies) == 0 {
+			continue
+		}
+		length := 0
+		for term, tf := range frequencies {
+			length += tf
+			if ix.postings[field][term] == nil {
+				ix.postings[field][term] = make(map[string]int)
+			}
+			ix.postings[field][term][key] = tf
+			ix.addTerm(term)
+		}
+		doc.lengths[field] = length
+		ix.fieldDocs[field]++
+		ix.fieldLengths[field] += length
+	}
+	ix.docs[key] = doc
+}
+
+// remove drops a document from the index. The caller holds the write lock.
+func (ix *Index) remove(key string) {
+	doc, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	for _, field := range fields {
+		frequencies := doc.terms[field]
+		if len(frequencies) == 0 {
+			continue
+		}
+		for term := range frequencies {
+			delete(ix.postings[field][term], key)
+			if len(ix.postings[field][term]) == 0 {
+				delete(ix.postings[field], term)
+			}
+			ix.removeTerm(term)
+		}
+		ix.fieldDocs[field]--
+		ix.fieldLengths[field] -= doc.lengths[field]
+	}
+	delete(ix.docs, key)
+}
+
+// addTerm counts a posting of a term in the vocabulary
+func (ix *Index) addTerm(term string) {
+	ix.vocabulary[term]++
+	if ix.vocabulary[term] > 1 {
+		return
+	}
+	for _, gram := range trigramsOf(term) {
+		if ix.trigrams[gram] == nil {
+			ix.trigrams[gram] = make(map[string]bool)
+		}
+		ix.trigrams[gram][term] = true
+	}
+}
+
+// removeTerm uncounts a posting of a term in the vocabulary
+func (ix *Index) removeTerm(term string) {
+	ix.vocabulary[term]--
+	if ix.vocabulary[term] > 0 {
+		return
+	}
+	delete(ix.vocabulary, term)
+	for _, gram := range trigramsOf(term) {
+		delete(ix.trigrams[gram], term)
+		if len(ix.trigrams[gram]) == 0 {
+			delete(ix.trigrams, gram)
+		}
+	}
+}
+
+// bm25 scores one term in one field of a document. The caller holds the
+// read lock.
+func (ix *Index) bm25(term, field, key string) float64 {
+	postings := ix.postings[field][term]
+	tf, ok := postings[key]
+	if !ok {
+		return 0
+	}
+
+	n := float64(ix.fieldDocs[field])
+	df := float64(len(postings))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	avgLength := float64(ix.fieldLengths[field]) / n
+	length := float64(ix.docs[key].lengths[field])
+	return idf * float64(tf) * (k1 + 1) / (float64(tf) + k1*(1-b+b*length/avgLength))
+}
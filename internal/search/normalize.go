@@ -0,0 +1,144 @@
+package search
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// maxTokenLength caps tokens so that long URL segments do not bloat the index
+const maxTokenLength = 64
+
+// halfwidthKana maps the halfwidth forms U+FF61 to U+FF9F to their fullwidth
+// counterparts. The last two are the voiced and semi-voiced sound marks,
+// which are composed with the kana before them.
+var halfwidthKana = [...]rune{
+	'。', '「', '」', '、', '・', 'ヲ', 'ァ', 'ィ', 'ゥ', 'ェ', 'ォ', 'ャ', 'ュ', 'ョ', 'ッ',
+	'ー', 'ア', 'イ', 'ウ', 'エ', 'オ', 'カ', 'キ', 'ク', 'ケ', 'コ', 'サ', 'シ', 'ス', 'セ', 'ソ',
+	'タ', 'チ', 'ツ', 'テ', 'ト', 'ナ', 'ニ', 'ヌ', 'ネ', 'ノ', 'ハ', 'ヒ', 'フ', 'ヘ', 'ホ', 'マ',
+	'ミ', 'ム', 'メ', 'モ', 'ヤ', 'ユ', 'ヨ', 'ラ', 'リ', 'ル', 'レ', 'ロ', 'ワ', 'ン', 0x3099, 0x309A,
+}
+
+// Normalize folds text for matching: fullwidth ASCII and the ideographic
+// space become ASCII, halfwidth katakana become fullwidth, sound marks are
+// composed with their kana, hiragana become katakana and letters are lower
+// cased. "ﾃﾞｰﾀ", "データ" and "でーた" all normalize to "データ".
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	var last rune = -1
+	flush := func() {
+		if last >= 0 {
+			b.WriteRune(last)
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			r -= 0xFEE0
+		case r == 0x3000:
+			r = ' '
+		case r >= 0xFF61 && r <= 0xFF9F:
+			r = halfwidthKana[r-0xFF61]
+		case r == 0x309B:
+			r = 0x3099
+		case r == 0x309C:
+			r = 0x309A
+		}
+		if r >= 0x3041 && r <= 0x3096 {
+			// Hiragana to katakana
+			r += 0x60
+		}
+
+		if r == 0x3099 || r == 0x309A {
+			if composed, ok := composeKana(last, r); ok {
+				last = composed
+				continue
+			}
+		}
+
+		flush()
+		last = unicode.ToLower(r)
+	}
+	flush()
+	return b.String()
+}
+
+// composeKana combines a katakana with a following voiced (U+3099) or
+// semi-voiced (U+309A) sound mark
+func composeKana(kana, mark rune) (rune, bool) {
+	switch {
+	case mark == 0x3099 && kana == 'ウ':
+		return 'ヴ', true
+	case mark == 0x3099 && kana >= 'カ' && kana <= 'チ' && (kana-'カ')%2 == 0:
+		return kana + 1, true
+	case mark == 0x3099 && (kana == 'ツ' || kana == 'テ' || kana == 'ト'):
+		return kana + 1, true
+	case kana >= 'ハ' && kana <= 'ホ' && (kana-'ハ')%3 == 0:
+		if mark == 0x3099 {
+			return kana + 1, true
+		}
+		return kana + 2, true
+	}
+	return 0, false
+}
+
+// isCJK reports whether r belongs to a script written without spaces
+// between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// Tokenize normalizes text and splits it into tokens. Words of scripts
+// written with spaces are split at every character that is neither a letter
+// nor a digit. Runs of Chinese, Japanese and Korean characters are split into
+// overlapping bigrams ("東京都" gives "東京" and "京都"), and a lone character
+// is its own token.
+func Tokenize(text string) []string {
+	var tokens []string
+	runes := []rune(Normalize(text))
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case isCJK(r):
+			end := i
+			for end < len(runes) && isCJK(runes[end]) {
+				end++
+			}
+			if end-i == 1 {
+				tokens = append(tokens, string(runes[i]))
+			}
+			for j := i; j+1 < end; j++ {
+				tokens = append(tokens, string(runes[j:j+2]))
+			}
+			i = end
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			end := i
+			for end < len(runes) && !isCJK(runes[end]) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || unicode.Is(unicode.Mn, runes[end])) {
+				end++
+			}
+			if end-i <= maxTokenLength {
+				tokens = append(tokens, string(runes[i:end]))
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// TokenizeURL splits a URL into tokens of its host, path and query. The
+// scheme and a leading www. are dropped and escapes are decoded, so that
+// percent-encoded Japanese paths are searchable.
+func TokenizeURL(rawURL string) []string {
+	rest := rawURL
+	if _, afterScheme, found := strings.Cut(rest, "://"); found {
+		rest = afterScheme
+	}
+	rest = strings.TrimPrefix(rest, "www.")
+	if unescaped, err := url.QueryUnescape(rest); err == nil {
+		rest = unescaped
+	}
+	return Tokenize(rest)
+}
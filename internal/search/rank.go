@@ -0,0 +1,282 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/query"
+)
+
+// Weights of the fields in the score of a tab
+var fieldWeights = map[string]float64{
+	FieldTitle: 2.0,
+	FieldURL:   1.0,
+	FieldText:  0.5,
+}
+
+// Weights of inexact matches relative to an exact match of a token
+const (
+	prefixWeight = 0.8
+	typoWeight   = 0.6
+)
+
+// expansion is an indexed term a query token stands for
+type expansion struct {
+	term   string
+	weight float64
+}
+
+// rankedTerm is a query term prepared for ranking
+type rankedTerm struct {
+	fields []string
+	// Expansions of every token of the term
+	tokens [][]expansion
+	// The normalized text of a phrase
+	phrase string
+}
+
+// Ranker scores and matches tabs against the words and phrases of a query
+// using the index. Words match indexed tokens exactly, as the start of a
+// longer token, or with a typo or two, depending on their length.
+type Ranker struct {
+	ix *Index
+	// Terms in query order, so that scores add up the same way every time
+	order []*rankedTerm
+	terms map[*query.Term]*rankedTerm
+}
+
+// Ranker prepares the positive text terms of a query for ranking. Words
+// that are not in the index are expanded to the indexed tokens they may
+// stand for once, here.
+func (ix *Index) Ranker(q *query.Query) *Ranker {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	r := &Ranker{ix: ix, terms: make(map[*query.Term]*rankedTerm)}
+	for _, term := range q.Terms() {
+		if term.Kind != query.KindText {
+			continue
+		}
+		var termFields []string
+		switch term.Field {
+		case query.FieldAny:
+			termFields = fields
+		case query.FieldTitle:
+			termFields = []string{FieldTitle}
+		case query.FieldURL, query.FieldHost:
+			termFields = []string{FieldURL}
+		default:
+			continue
+		}
+
+		tokenize := Tokenize
+		if termFields[0] == FieldURL {
+			tokenize = TokenizeURL
+		}
+		// Phrases match their words exactly
+		fuzzy := !strings.ContainsFunc(term.Value, unicode.IsSpace)
+
+		ranked := &rankedTerm{fields: termFields}
+		if !fuzzy {
+			ranked.phrase = Normalize(term.Value)
+		}
+		for _, token := range tokenize(term.Value) {
+			ranked.tokens = append(ranked.tokens, ix.expand(token, fuzzy))
+		}
+		if len(ranked.tokens) > 0 {
+			r.order = append(r.order, ranked)
+			r.terms[term] = ranked
+		}
+	}
+	return r
+}
+
+// Match reports whether the tab matches a word or phrase of the query after
+// normalization. A word matches when the tab contains all of its tokens,
+// allowing for prefixes and typos. A phrase matches when the normalized
+// title or URL contains it, or when the page text contains all of its
+// words. Host terms and terms that are not words or phrases never match here.
+func (r *Ranker) Match(term *query.Term, tab loader.Tab) bool {
+	ranked, ok := r.terms[term]
+	if !ok || term.Field == query.FieldHost {
+		return false
+	}
+
+	r.ix.mu.RLock()
+	defer r.ix.mu.RUnlock()
+
+	key := docKey(tab.Device, tab.ID)
+	if _, ok := r.ix.docs[key]; !ok {
+		return false
+	}
+
+	searched := ranked.fields
+	if ranked.phrase != "" {
+		searched = nil
+		for _, field := range ranked.fields {
+			switch field {
+			case FieldTitle:
+				if strings.Contains(Normalize(tab.Title), ranked.phrase) {
+					return true
+				}
+			case FieldURL:
+				if strings.Contains(Normalize(tab.URL), ranked.phrase) {
+					return true
+				}
+			default:
+				searched = append(searched, field)
+			}
+		}
+	}
+	if len(searched) == 0 {
+		return false
+	}
+
+	for _, expansions := range ranked.tokens {
+		found := false
+		for _, field := range searched {
+			for _, e := range expansions {
+				if _, ok := r.ix.postings[field][e.term][key]; ok {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Score returns the BM25 score of a tab for the words and phrases of the
+// query. Every token counts with its best expansion in every field, by the
+// weight of the field; inexact expansions count less.
+func (r *Ranker) Score(tab loader.Tab) float64 {
+	r.ix.mu.RLock()
+	defer r.ix.mu.RUnlock()
+
+	key := docKey(tab.Device, tab.ID)
+	if _, ok := r.ix.docs[key]; !ok {
+		return 0
+	}
+
+	score := 0.0
+	for _, ranked := range r.order {
+		for _, expansions := range ranked.tokens {
+			for _, field := range ranked.fields {
+				best := 0.0
+				for _, e := range expansions {
+					if s := e.weight * r.ix.bm25(e.term, field, key); s > best {
+						best = s
+					}
+				}
+				score += fieldWeights[field] * best
+			}
+		}
+	}
+	return score
+}
+
+// expand returns the indexed terms a query token stands for: itself, and
+// when fuzzy, longer terms it starts and terms within a few typos of it. The
+// caller holds the read lock.
+func (ix *Index) expand(token string, fuzzy bool) []expansion {
+	var expansions []expansion
+	if ix.vocabulary[token] > 0 {
+		expansions = append(expansions, expansion{term: token, weight: 1})
+	}
+
+	runes := []rune(token)
+	if !fuzzy || len(runes) < 3 || isCJK(runes[0]) {
+		return expansions
+	}
+
+	for term := range ix.vocabulary {
+		if term != token && strings.HasPrefix(term, token) {
+			expansions = append(expansions, expansion{term: term, weight: prefixWeight})
+		}
+	}
+
+	maxEdits := allowedEdits(len(runes))
+	if maxEdits == 0 {
+		return expansions
+	}
+
+	// Candidates share enough trigrams with the token to be within maxEdits
+	grams := trigramsOf(token)
+	shared := make(map[string]int)
+	for _, gram := range grams {
+		for term := range ix.trigrams[gram] {
+			shared[term]++
+		}
+	}
+	for term, count := range shared {
+		if term == token || strings.HasPrefix(term, token) || count < len(grams)-3*maxEdits {
+			continue
+		}
+		if distance := editDistance(runes, []rune(term), maxEdits); distance <= maxEdits {
+			expansions = append(expansions, expansion{term: term, weight: typoWeight / float64(distance)})
+		}
+	}
+	return expansions
+}
+
+// allowedEdits returns the number of typos tolerated in a word: none in
+// short words, one from four characters and two from eight
+func allowedEdits(length int) int {
+	switch {
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// trigramsOf returns the trigrams of a term padded with $ at both ends
+func trigramsOf(term string) []string {
+	runes := []rune("$" + term + "$")
+	if len(runes) < 3 {
+		return nil
+	}
+	grams := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+3]))
+	}
+	return grams
+}
+
+// editDistance returns the optimal string alignment distance of a and b,
+// counting insertions, deletions, substitutions and transpositions of
+// adjacent characters. Distances above limit are returned as limit+1.
+func editDistance(a, b []rune, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	// Three rows of the dynamic programming table
+	previous2 := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return min(previous[len(b)], limit+1)
+}
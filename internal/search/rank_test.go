@@ -0,0 +1,160 @@
+package search
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+	"github.com/kazuph/mcp-android-chrome/internal/query"
+)
+
+// newTestIndex indexes tabs of one device
+func newTestIndex(tabs []loader.Tab) *Index {
+	ix := NewIndex()
+	ix.Update("android", tabs)
+	return ix
+}
+
+// ranker parses a query and prepares it for ranking
+func ranker(t *testing.T, ix *Index, input string) (*Ranker, *query.Query) {
+	t.Helper()
+	q, err := query.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", input, err)
+	}
+	return ix.Ranker(q), q
+}
+
+func TestRankerScore(t *testing.T) {
+	tabs := []loader.Tab{
+		{ID: "title", Title: "Go tutorial", URL: "https://example.com/a"},
+		{ID: "url", Title: "Cooking", URL: "https://go.dev/tutorial"},
+		{ID: "twice", Title: "Tutorial: tutorial basics", URL: "https://example.com/b"},
+		{ID: "none", Title: "Weather", URL: "https://example.com/c"},
+		{ID: "typo", Title: "Tutorail of the week", URL: "https://example.com/d"},
+	}
+	ix := newTestIndex(tabs)
+
+	tests := []struct {
+		query string
+		// IDs of the tabs with a positive score, best first
+		want []string
+	}{
+		{query: "tutorial", want: []string{"twice", "title", "url", "typo"}},
+		{query: "title:tutorial", want: []string{"twice", "title", "typo"}},
+		{query: "url:tutorial", want: []string{"url"}},
+		{query: "weather", want: []string{"none"}},
+		{query: "-weather", want: nil},
+		{query: "host:go.dev", want: []string{"url"}},
+		{query: "title:/tutorial/", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r, _ := ranker(t, ix, tt.query)
+
+			scores := make(map[string]float64)
+			var got []string
+			for _, tab := range tabs {
+				if score := r.Score(tab); score > 0 {
+					scores[tab.ID] = score
+					got = append(got, tab.ID)
+				}
+			}
+			sort.SliceStable(got, func(i, j int) bool { return scores[got[i]] > scores[got[j]] })
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranking of %q = %v (scores %v), want %v", tt.query, got, scores, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankerMatch(t *testing.T) {
+	tab := loader.Tab{
+		ID:    "1",
+		Title: "Kubernetes データベース入門",
+		URL:   "https://example.com/docs/%E6%9D%B1%E4%BA%AC",
+	}
+	ix := newTestIndex([]loader.Tab{tab})
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "kubernetes", want: true},
+		{query: "KUBERNETES", want: true},
+		{query: "kube", want: true},
+		{query: "kubernets", want: true},
+		{query: "kuberentes", want: true},
+		{query: "kuebrnetse", want: false},
+		{query: "docs", want: true},
+		{query: "dosc", want: true},
+		{query: "doc", want: true},
+		{query: "dco", want: false},
+		{query: "データ", want: true},
+		{query: "でーた", want: true},
+		{query: "ﾃﾞｰﾀ", want: true},
+		{query: "東京", want: true},
+		{query: "京都", want: false},
+		{query: `"kubernetes データ"`, want: true},
+		{query: `"データ kubernetes"`, want: false},
+		{query: `"kubernets データ"`, want: false},
+		{query: "title:docs", want: false},
+		{query: "url:docs", want: true},
+		{query: "host:example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r, q := ranker(t, ix, tt.query)
+			terms := q.Terms()
+			if len(terms) != 1 {
+				t.Fatalf("Parse(%q) has %d terms, want 1", tt.query, len(terms))
+			}
+			if got := r.Match(terms[0], tab); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankerInexactScoresLess(t *testing.T) {
+	tab := loader.Tab{ID: "1", Title: "Kubernetes operators", URL: "https://example.com/"}
+	ix := newTestIndex([]loader.Tab{tab, {ID: "2", Title: "Other", URL: "https://example.org/"}})
+
+	exact, _ := ranker(t, ix, "kubernetes")
+	prefix, _ := ranker(t, ix, "kubern")
+	typo, _ := ranker(t, ix, "kubernets")
+
+	exactScore, prefixScore, typoScore := exact.Score(tab), prefix.Score(tab), typo.Score(tab)
+	if !(exactScore > prefixScore && prefixScore > typoScore && typoScore > 0) {
+		t.Errorf("scores exact %v, prefix %v, typo %v: want exact > prefix > typo > 0", exactScore, prefixScore, typoScore)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{a: "kitten", b: "kitten", limit: 2, want: 0},
+		{a: "kitten", b: "sitten", limit: 2, want: 1},
+		{a: "kitten", b: "kiten", limit: 2, want: 1},
+		{a: "kitten", b: "kitetn", limit: 2, want: 1},
+		{a: "kitten", b: "sitting", limit: 3, want: 3},
+		{a: "kitten", b: "sitting", limit: 2, want: 3},
+		{a: "go", b: "golang", limit: 2, want: 3},
+		{a: "データ", b: "デエタ", limit: 1, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := editDistance([]rune(tt.a), []rune(tt.b), tt.limit); got != tt.want {
+				t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// ReadTabsFile reads tabs from a JSON file holding either a tab array, as
+// printed by the android and ios commands, or a stored snapshot
+func ReadTabsFile(path string) ([]loader.Tab, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tabs file: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot file: %w", err)
+		}
+		return snapshot.Tabs, nil
+	}
+
+	var tabs []loader.Tab
+	if err := json.Unmarshal(data, &tabs); err != nil {
+		return nil, fmt.Errorf("failed to parse tabs JSON: %w", err)
+	}
+	return tabs, nil
+}
+
+// LoadTabs loads tabs from ref, which is the path of a tabs file, a snapshot
+// ID or "latest"
+func (s *Store) LoadTabs(ref string) ([]loader.Tab, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		return ReadTabsFile(ref)
+	}
+
+	snapshot, err := s.Load(ref)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Tabs, nil
+}
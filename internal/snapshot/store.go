@@ -0,0 +1,192 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kazuph/mcp-android-chrome/internal/jsonstore"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// Default retention limits for stored snapshots
+const (
+	DefaultMaxCount = 500
+	DefaultMaxAge   = 90 * 24 * time.Hour
+)
+
+// Meta describes a stored snapshot without its tabs
+type Meta struct {
+	ID         string    `json:"id" yaml:"id"`
+	Platform   string    `json:"platform" yaml:"platform"`
+	Device     string    `json:"device,omitempty" yaml:"device,omitempty"`
+	Browser    string    `json:"browser,omitempty" yaml:"browser,omitempty"`
+	Source     string    `json:"source,omitempty" yaml:"source,omitempty"`
+	CapturedAt time.Time `json:"capturedAt" yaml:"capturedAt"`
+	TabCount   int       `json:"tabCount" yaml:"tabCount"`
+}
+
+// Snapshot is the set of tabs captured from one device at one point in time
+type Snapshot struct {
+	Meta `yaml:",inline"`
+	Tabs []loader.Tab `json:"tabs" yaml:"tabs"`
+}
+
+// Options configures a Store
+type Options struct {
+	jsonstore.Options
+	Disabled bool // skip writing snapshots
+}
+
+// Filter selects snapshots in List
+type Filter = jsonstore.Filter
+
+// Header returns what the store orders and filters the snapshot by
+func (m Meta) Header() jsonstore.Header {
+	return jsonstore.Header{
+		ID:       m.ID,
+		Platform: m.Platform,
+		Device:   m.Device,
+		Time:     m.CapturedAt,
+	}
+}
+
+// SetID sets the ID of the snapshot read from a file
+func (s *Snapshot) SetID(id string) {
+	s.ID = id
+}
+
+// Store keeps tab snapshots as JSON files in a directory
+type Store struct {
+	files    *jsonstore.Store[Snapshot, *Snapshot]
+	disabled bool
+}
+
+// NewStore creates a snapshot store with the given options
+func NewStore(options Options) *Store {
+	return &Store{
+		files:    jsonstore.New[Snapshot, *Snapshot](options.Options, "snapshot"),
+		disabled: options.Disabled,
+	}
+}
+
+// DefaultDir returns the directory of the snapshots when none is configured:
+// snapshots in the user config directory
+func DefaultDir() string {
+	return jsonstore.DefaultDir("snapshots")
+}
+
+// Dir returns the directory the snapshots are stored in
+func (s *Store) Dir() string {
+	return s.files.Dir()
+}
+
+// Save writes a snapshot of tabs and applies the retention limits. It returns
+// nil metadata without error when snapshots are disabled.
+func (s *Store) Save(platform, source string, tabs []loader.Tab) (*Meta, error) {
+	if s.disabled {
+		return nil, nil
+	}
+
+	snapshot := Snapshot{
+		Meta: Meta{
+			Platform:   platform,
+			Source:     source,
+			CapturedAt: time.Now().UTC(),
+			TabCount:   len(tabs),
+		},
+		Tabs: tabs,
+	}
+	if len(tabs) > 0 {
+		snapshot.Device = tabs[0].Device
+		snapshot.Browser = tabs[0].Browser
+		if !tabs[0].CapturedAt.IsZero() {
+			snapshot.CapturedAt = tabs[0].CapturedAt.UTC()
+		}
+	}
+	snapshot.ID = jsonstore.NewID(snapshot.CapturedAt, platform, snapshot.Device)
+
+	if err := s.files.Write(&snapshot); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Prune(); err != nil {
+		return &snapshot.Meta, fmt.Errorf("snapshot saved but pruning failed: %w", err)
+	}
+
+	return &snapshot.Meta, nil
+}
+
+// List returns the metadata of stored snapshots matching filter, newest first
+func (s *Store) List(filter Filter) ([]Meta, error) {
+	snapshots, err := s.files.List(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make([]Meta, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		metas = append(metas, snapshot.Meta)
+	}
+	return metas, nil
+}
+
+// Load reads the snapshot with the given ID. The ID "latest" selects the
+// newest snapshot.
+func (s *Store) Load(id string) (*Snapshot, error) {
+	if id == "latest" {
+		metas, err := s.List(Filter{Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(metas) == 0 {
+			return nil, fmt.Errorf("no snapshots stored in %s", s.Dir())
+		}
+		id = metas[0].ID
+	}
+
+	return s.files.Read(id)
+}
+
+// Delete removes the snapshot with the given ID
+func (s *Store) Delete(id string) error {
+	return s.files.Remove(id)
+}
+
+// Prune deletes snapshots beyond the retention limits and returns how many
+// were deleted
+func (s *Store) Prune() (int, error) {
+	return s.files.Prune()
+}
+
+// ParseTime parses a filter time given as RFC 3339 or as a YYYY-MM-DD date
+// in the local time zone. An empty string yields the zero time.
+func ParseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC 3339 or YYYY-MM-DD)", value)
+}
+
+// ParseRange parses the since and until bounds of a Filter with ParseTime.
+// An until given as a bare date covers that whole day.
+func ParseRange(since, until string) (time.Time, time.Time, error) {
+	sinceTime, err := ParseTime(since)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	untilTime, err := ParseTime(until)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if until != "" && !strings.Contains(until, "T") {
+		untilTime = untilTime.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return sinceTime, untilTime, nil
+}
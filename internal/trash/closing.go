@@ -0,0 +1,69 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// Record records the tabs about to be closed on a device as one operation.
+// Titles and URLs come from the loaded tabs. When the tabs cannot be
+// recorded the partial record is removed and an error returned: nothing must
+// be closed then. A failure to prune old entries is only a warning.
+func (s *Store) Record(platform, source string, loaded []loader.Tab, tabIDs []string) ([]Entry, error) {
+	known := make(map[string]loader.Tab, len(loaded))
+	for _, tab := range loaded {
+		known[tab.ID] = tab
+	}
+
+	tabs := make([]loader.Tab, 0, len(tabIDs))
+	for _, tabID := range tabIDs {
+		tab, ok := known[tabID]
+		if !ok {
+			tab = loader.Tab{ID: tabID}
+		}
+		tabs = append(tabs, tab)
+	}
+
+	entries, err := s.Add(platform, source, tabs)
+	if err != nil {
+		if len(entries) == len(tabs) {
+			// Recorded; only pruning old entries failed
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return entries, nil
+		}
+		s.ForgetUnclosed(entries, nil)
+		return nil, fmt.Errorf("failed to record the tabs in the trash, so none were closed: %w", err)
+	}
+	return entries, nil
+}
+
+// ForgetUnclosed removes the entries of tabs that were not closed after all
+// and returns the entries of the closed tabs. A nil result means no tab was
+// closed.
+func (s *Store) ForgetUnclosed(entries []Entry, result *loader.BatchResult) []Entry {
+	closed := make(map[string]bool)
+	if result != nil {
+		for _, tab := range result.Tabs {
+			if tab.Status == loader.StatusClosed {
+				closed[tab.ID] = true
+			}
+		}
+	}
+
+	var kept []Entry
+	var unclosed []string
+	for _, entry := range entries {
+		if closed[entry.TabID] {
+			kept = append(kept, entry)
+		} else {
+			unclosed = append(unclosed, entry.ID)
+		}
+	}
+
+	if err := s.Remove(unclosed); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove tabs that were not closed from the trash: %v\n", err)
+	}
+	return kept
+}
@@ -0,0 +1,221 @@
+package trash
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kazuph/mcp-android-chrome/internal/jsonstore"
+	"github.com/kazuph/mcp-android-chrome/internal/loader"
+)
+
+// Default retention limits for closed tabs
+const (
+	DefaultMaxCount = 1000
+	DefaultMaxAge   = 30 * 24 * time.Hour
+)
+
+// Entry is a tab recorded in the trash before it was closed
+type Entry struct {
+	ID string `json:"id" yaml:"id"`
+	// ID shared by the tabs closed in one call
+	Operation string `json:"operation" yaml:"operation"`
+	// Tool or command that closed the tab, e.g. close_tabs_bulk
+	Source   string    `json:"source" yaml:"source"`
+	Platform string    `json:"platform" yaml:"platform"`
+	Device   string    `json:"device,omitempty" yaml:"device,omitempty"`
+	Browser  string    `json:"browser,omitempty" yaml:"browser,omitempty"`
+	TabID    string    `json:"tabId" yaml:"tabId"`
+	URL      string    `json:"url" yaml:"url"`
+	Title    string    `json:"title,omitempty" yaml:"title,omitempty"`
+	ClosedAt time.Time `json:"closedAt" yaml:"closedAt"`
+}
+
+// Tab returns the closed tab, for restoring it
+func (e Entry) Tab() loader.Tab {
+	return loader.Tab{
+		ID:      e.TabID,
+		Title:   e.Title,
+		URL:     e.URL,
+		Device:  e.Device,
+		Browser: e.Browser,
+	}
+}
+
+// Options configures a Store
+type Options = jsonstore.Options
+
+// Filter selects entries in List
+type Filter struct {
+	jsonstore.Filter
+	Operation string
+}
+
+// Header returns what the store orders and filters the entry by. IDs end
+// with the position of the tab in its operation.
+func (e Entry) Header() jsonstore.Header {
+	position, _ := strconv.Atoi(e.ID[strings.LastIndex(e.ID, "-")+1:])
+	return jsonstore.Header{
+		ID:       e.ID,
+		Platform: e.Platform,
+		Device:   e.Device,
+		Time:     e.ClosedAt,
+		Position: position,
+	}
+}
+
+// SetID sets the ID of the entry read from a file
+func (e *Entry) SetID(id string) {
+	e.ID = id
+}
+
+// Store keeps closed tabs as JSON files in a directory, one file per tab, so
+// that they can be restored later
+type Store struct {
+	files *jsonstore.Store[Entry, *Entry]
+}
+
+// NewStore creates a trash store with the given options
+func NewStore(options Options) *Store {
+	return &Store{
+		files: jsonstore.New[Entry, *Entry](options, "closed tab"),
+	}
+}
+
+// DefaultDir returns the directory of the trash when none is configured:
+// trash in the user config directory
+func DefaultDir() string {
+	return jsonstore.DefaultDir("trash")
+}
+
+// Dir returns the directory the closed tabs are stored in
+func (s *Store) Dir() string {
+	return s.files.Dir()
+}
+
+// Add records tabs about to be closed from a device as one operation and
+// applies the retention limits. It returns the entries in the order of tabs.
+func (s *Store) Add(platform, source string, tabs []loader.Tab) ([]Entry, error) {
+	if len(tabs) == 0 {
+		return nil, nil
+	}
+
+	closedAt := time.Now().UTC()
+	operation, err := newOperationID(closedAt, platform)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(tabs))
+	for i, tab := range tabs {
+		entry := Entry{
+			ID:        fmt.Sprintf("%s-%d", operation, i+1),
+			Operation: operation,
+			Source:    source,
+			Platform:  platform,
+			Device:    tab.Device,
+			Browser:   tab.Browser,
+			TabID:     tab.ID,
+			URL:       tab.URL,
+			Title:     tab.Title,
+			ClosedAt:  closedAt,
+		}
+		if err := s.files.Write(&entry); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if _, err := s.Prune(); err != nil {
+		return entries, fmt.Errorf("closed tabs recorded but pruning failed: %w", err)
+	}
+
+	return entries, nil
+}
+
+// List returns the entries matching filter, most recently closed first
+func (s *Store) List(filter Filter) ([]Entry, error) {
+	var match func(*Entry) bool
+	if filter.Operation != "" {
+		match = func(entry *Entry) bool { return entry.Operation == filter.Operation }
+	}
+
+	found, err := s.files.List(filter.Filter, match)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(found))
+	for _, entry := range found {
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+// Get reads the entries with the given IDs, in that order
+func (s *Store) Get(ids []string) ([]Entry, error) {
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		entry, err := s.files.Read(id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+// Operation returns the entries of one operation. The operation "latest"
+// selects the most recent one.
+func (s *Store) Operation(operation string) ([]Entry, error) {
+	if operation == "latest" {
+		latest, err := s.List(Filter{Filter: jsonstore.Filter{Limit: 1}})
+		if err != nil {
+			return nil, err
+		}
+		if len(latest) == 0 {
+			return nil, fmt.Errorf("no closed tabs in %s", s.Dir())
+		}
+		operation = latest[0].Operation
+	}
+
+	entries, err := s.List(Filter{Operation: operation})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no closed tabs for operation '%s'", operation)
+	}
+	return entries, nil
+}
+
+// Remove deletes the entries with the given IDs, e.g. after their tabs were
+// restored or failed to close. Missing entries are ignored.
+func (s *Store) Remove(ids []string) error {
+	for _, id := range ids {
+		if err := s.files.Remove(id); err != nil && !errors.Is(err, jsonstore.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune deletes entries beyond the retention limits and returns how many
+// were deleted
+func (s *Store) Prune() (int, error) {
+	return s.files.Prune()
+}
+
+// newOperationID builds a sortable, filesystem-safe operation ID such as
+// 20261016T053700.123Z-android-3f9a2c. The random suffix keeps operations
+// started in the same millisecond, e.g. by the server and a command, apart.
+func newOperationID(closedAt time.Time, platform string) (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to create operation ID: %w", err)
+	}
+	return jsonstore.NewID(closedAt, platform, hex.EncodeToString(suffix)), nil
+}